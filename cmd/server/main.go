@@ -1,23 +1,8 @@
 package main
 
 import (
-	"os"
-	"path/filepath"
-	"strconv"
-
-	"gosir/config"
 	_ "gosir/docs" // 导入 swagger 文档
-	"gosir/internal/common"
-	"gosir/internal/cron"
-	"gosir/internal/database"
-	"gosir/internal/handler"
-	"gosir/internal/logger"
-	"gosir/internal/middleware"
-	"gosir/internal/service/system"
-
-	"github.com/labstack/echo/v4"
-	echoMiddleware "github.com/labstack/echo/v4/middleware"
-	"go.uber.org/zap"
+	"gosir/internal/cli"
 )
 
 // @title           Gosir API
@@ -39,100 +24,15 @@ import (
 // @in header
 // @name Authorization
 // @description 请输入 JWT token，格式：Bearer <token>
-func main() {
-	// 加载配置
-	cfg, err := config.Load("config/config.yaml")
-	if err != nil {
-		panic("Failed to load config: " + err.Error())
-	}
-	cfg.PrintConfig()
-
-	// 创建日志目录
-	logDir := filepath.Dir(cfg.Log.Path)
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		panic("Failed to create log directory: " + err.Error())
-	}
-
-	// 初始化日志系统
-	if err := logger.InitWithConfig(&logger.LogConfig{
-		Path:   cfg.Log.Path,
-		Level:  cfg.Log.Level,
-		Format: cfg.Log.Format,
-	}); err != nil {
-		panic("Failed to init logger: " + err.Error())
-	}
-	defer logger.Sync()
-
-	logger.Info("Starting application...")
-
-	// 初始化数据库
-	if err := database.InitDB(cfg.Database.Path, cfg.Database.LogLevel, logger.Log); err != nil {
-		logger.Fatal("Failed to connect database",
-			zap.Error(err),
-		)
-	}
-	defer func() {
-		if err := database.CloseDB(); err != nil {
-			logger.Error("Failed to close database", zap.Error(err))
-		}
-	}()
-
-	// 自动迁移核心数据表结构（使用 GORM AutoMigrate）
-	if err := system.AutoMigrate(); err != nil {
-		logger.Fatal("Failed to migrate database schema",
-			zap.Error(err),
-		)
-	}
-
-	// 执行 SQL 迁移脚本（数据初始化、索引、视图等）
-	if err := system.ExecuteSQLScripts("migrations"); err != nil {
-		logger.Fatal("Failed to execute SQL scripts",
-			zap.Error(err),
-		)
-	}
-
-	// 初始化管理员账号
-	if err := system.InitAdminUser(); err != nil {
-		logger.Fatal("Failed to init admin user",
-			zap.Error(err),
-		)
-	}
 
-	// 初始化定时任务
-	cron.Init()
-	defer cron.Stop()
-
-	// 创建 Echo 实例
-	e := echo.New()
-	e.HideBanner = true
-
-	// 初始化 JWT
-	common.InitJWT(cfg.JWT.Secret, cfg.JWT.ExpireHours)
-
-	// 设置统一错误处理
-	e.HTTPErrorHandler = middleware.ErrorHandler()
-
-	// 全局中间件
-	e.Use(middleware.ZapLoggerMiddleware())
-	e.Use(echoMiddleware.Recover())
-	e.Use(echoMiddleware.CORS())
-
-	// 公开路由（无需鉴权）
-	handler.SetupPublicRoutes(e)
-
-	// 受保护路由组（需要鉴权）
-	protected := e.Group("/api", middleware.AuthMiddleware())
-	handler.SetupRoutes(protected)
+// @securityDefinitions.apikey ApiKey
+// @in header
+// @name Authorization
+// @description 请输入 API Key，格式：ApiKey <key>
 
-	// 启动服务
-	addr := ":" + strconv.Itoa(cfg.Server.Port)
-	logger.Info("Server starting",
-		zap.String("addr", addr),
-		zap.String("mode", cfg.Server.Mode),
-	)
-	if err := e.Start(addr); err != nil {
-		logger.Fatal("Failed to start server",
-			zap.Error(err),
-		)
-	}
+// @securityDefinitions.basic BasicAuth
+// @description /metrics 的认证（metrics.username / metrics.password）
+func main() {
+	// 未指定子命令时默认执行 serve，启动 HTTP 服务
+	cli.Execute()
 }
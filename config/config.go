@@ -8,50 +8,279 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Log      LogConfig
+	Server       ServerConfig
+	Database     DatabaseConfig
+	JWT          JWTConfig
+	Log          LogConfig
+	Admin        AdminConfig
+	Tracing      TracingConfig
+	Cron         CronConfig
+	RateLimit    RateLimitConfig
+	OIDC         OIDCConfig
+	OAuth        OAuthConfig
+	Notify       NotifyConfig
+	Passwordless PasswordlessConfig
+	Register     RegisterConfig
+	Audit        AuditConfig
+	Metrics      MetricsConfig
 }
 
 type ServerConfig struct {
-	Port int
-	Mode string
+	Port            int
+	Mode            string
+	ShutdownTimeout int   // 优雅关闭超时时间（秒），等待进行中的请求和定时任务完成
+	Swagger         *bool // 是否开启 Swagger 文档，未设置时 release 模式关闭、其他模式开启
+	ErrorDetail     bool  // 错误响应中包含错误详情（仅用于调试，release 模式不允许开启）
+	HTTP            HTTPConfig
+	TLS             TLSConfig
+}
+
+// TLSConfig HTTPS 配置，开启后 server.port 监听 HTTPS
+type TLSConfig struct {
+	Enabled      bool
+	CertFile     string   // 证书文件（PEM，可包含中间证书），收到 SIGHUP 时重新加载
+	KeyFile      string   // 私钥文件（PEM）
+	SelfSigned   bool     // 使用启动时生成的 localhost 自签名证书（仅用于开发环境）
+	MinVersion   string   // 最低 TLS 版本: 1.2, 1.3
+	CipherSuites []string // TLS 1.2 允许的加密套件，为空时使用 Go 默认值
+	ClientCAFile string   // 校验客户端证书的 CA 文件（mTLS），收到 SIGHUP 时重新加载
+	ClientAuth   string   // 客户端证书校验: none, optional, require
+	RedirectHTTP bool     // 额外监听 redirectPort，将 HTTP 请求重定向到 HTTPS
+	RedirectPort int      // HTTP 重定向端口
+}
+
+// HTTPConfig HTTP 服务配置（跨域、安全响应头、请求体大小和超时时间）
+type HTTPConfig struct {
+	CORS              CORSConfig
+	SecureHeaders     SecureHeadersConfig
+	MaxBodySize       int  // 请求体最大大小（MB），0 表示不限制
+	ReadTimeout       int  // 读取整个请求的超时时间（秒），0 表示不限制
+	ReadHeaderTimeout int  // 读取请求头的超时时间（秒），0 表示使用 readTimeout
+	WriteTimeout      int  // 写响应的超时时间（秒），0 表示不限制
+	IdleTimeout       int  // keep-alive 连接的空闲超时时间（秒），0 表示使用 readTimeout
+	TrustProxy        bool // 信任 X-Forwarded-For/X-Real-IP 请求头获取客户端 IP（部署在反向代理后时开启）
+}
+
+// CORSConfig 跨域配置
+type CORSConfig struct {
+	AllowOrigins     []string // 允许的来源，* 表示全部
+	AllowMethods     []string // 允许的请求方法
+	AllowHeaders     []string // 允许的请求头，为空时允许预检请求中声明的所有请求头
+	ExposeHeaders    []string // 允许浏览器读取的响应头
+	AllowCredentials bool     // 是否允许携带 cookie 等凭证，开启时不能使用 * 来源
+	MaxAge           int      // 预检请求结果的缓存时间（秒）
+}
+
+// SecureHeadersConfig 安全响应头配置
+type SecureHeadersConfig struct {
+	HSTSMaxAge            int    // Strict-Transport-Security 的 max-age（秒），0 表示不发送，只对 HTTPS 请求生效
+	HSTSIncludeSubdomains bool   // HSTS 包含子域名
+	HSTSPreload           bool   // HSTS preload
+	ContentTypeNosniff    bool   // 发送 X-Content-Type-Options: nosniff
+	FrameOptions          string // X-Frame-Options: DENY, SAMEORIGIN，为空表示不发送
+	ContentSecurityPolicy string // 接口响应的 Content-Security-Policy，为空表示不发送
+	SwaggerCSP            string // Swagger 页面的 Content-Security-Policy（页面需要内联脚本和样式）
+}
+
+// SwaggerEnabled 是否开启 Swagger 文档
+func (s ServerConfig) SwaggerEnabled() bool {
+	if s.Swagger != nil {
+		return *s.Swagger
+	}
+	return s.Mode != "release"
 }
 
 type DatabaseConfig struct {
-	Path     string
-	LogLevel string // silent, error, warn, info
+	Path                 string
+	LogLevel             string // silent, error, warn, info
+	MigrationLockTimeout int    // 等待迁移锁的超时时间（秒）
+	MigrationLockTTL     int    // 迁移锁有效期（秒），持有者异常退出后超过该时间可被回收
 }
 
 type JWTConfig struct {
 	Secret      string
+	SecretFile  string // 从文件读取密钥（优先于 Secret）
 	ExpireHours int
 }
 
+// AdminConfig 初始管理员账号配置（仅在管理员不存在时用于创建账号）
+type AdminConfig struct {
+	Name         string
+	Email        string
+	Phone        string
+	Password     string // 留空则自动生成随机密码并在首次启动时打印
+	PasswordFile string // 从文件读取密码（优先于 Password）
+}
+
+// TracingConfig 链路追踪配置
+type TracingConfig struct {
+	Exporter    string  // none, otlp, stdout, file
+	Endpoint    string  // OTLP/HTTP collector 地址
+	Insecure    bool    // OTLP 是否使用明文 HTTP
+	FilePath    string  // file 导出器的输出文件
+	SampleRatio float64 // 采样率 0-1
+	ServiceName string  // 服务名
+}
+
+// RateLimitConfig 限流配置
+type RateLimitConfig struct {
+	Store  string                   // 计数存储: memory（单实例）, database（多实例共享）
+	Groups map[string]RateLimitRule // 路由组（auth, api, admin）-> 限流规则，未配置的路由组不限流
+}
+
+// RateLimitRule 路由组限流规则
+type RateLimitRule struct {
+	Algorithm string // token_bucket, sliding_window
+	KeyBy     string // ip, user, apikey
+	Limit     int    // 每个周期允许的请求数，0 表示不限流
+	Period    int    // 周期（秒）
+	Burst     int    // 令牌桶容量，0 表示与 limit 相同
+}
+
+// OIDCConfig 外部身份提供方登录配置
+type OIDCConfig struct {
+	Providers map[string]OIDCProviderConfig // 提供方名称 -> 配置，名称用于登录地址 /auth/oidc/{name}/login
+}
+
+// OIDCProviderConfig OIDC 提供方配置
+type OIDCProviderConfig struct {
+	Issuer         string   // 签发者地址，通过 {issuer}/.well-known/openid-configuration 获取端点
+	ClientID       string   // 客户端 ID
+	ClientSecret   string   // 客户端密钥，公开客户端（只使用 PKCE）留空
+	RedirectURL    string   // 回调地址 {服务地址}/auth/oidc/{name}/callback，需要在提供方登记
+	Scopes         []string // 请求的 scope，为空时使用 openid email profile
+	AutoCreate     bool     // 首次登录时自动创建用户
+	LinkByEmail    bool     // 首次登录时关联邮箱相同的已有用户（要求邮箱已验证）
+	AllowedDomains []string // 允许登录的邮箱域名，为空表示不限制
+}
+
+// OAuthConfig OAuth2/OIDC 授权服务配置（其他应用通过 gosir 登录）
+type OAuthConfig struct {
+	Enabled         bool
+	Issuer          string // 对外地址（签发者），如 https://auth.example.com
+	SigningKeyFile  string // ID Token 签名私钥（PEM，RSA 或 EC），为空时启动时生成临时密钥（release 模式禁止）
+	CodeTTL         int    // 授权码有效期（秒）
+	AccessTokenTTL  int    // 访问令牌有效期（秒）
+	RefreshTokenTTL int    // 刷新令牌有效期（秒）
+	IDTokenTTL      int    // ID Token 有效期（秒）
+	SessionTTL      int    // 授权页面登录会话有效期（秒）
+}
+
+// NotifyConfig 通知发送配置（验证码、登录链接、注册验证邮件等）
+type NotifyConfig struct {
+	Transport string // 发送方式: log（写入日志，仅用于开发）, file（追加到文件）, smtp
+	FilePath  string // file 方式的输出文件
+	SMTP      SMTPConfig
+}
+
+// SMTPConfig 邮件发送配置（smtp 方式只能发送邮件，不能发送短信）
+type SMTPConfig struct {
+	Host         string
+	Port         int
+	Username     string
+	Password     string
+	PasswordFile string // 从文件读取密码（优先于 Password）
+	From         string // 发件人地址
+	TLS          string // starttls, tls（隐式 TLS，通常为 465 端口）, none
+}
+
+// PasswordlessConfig 免密登录配置（验证码和登录链接）
+type PasswordlessConfig struct {
+	Enabled     bool
+	CodeTTL     int    // 验证码和登录链接有效期（秒）
+	CodeLength  int    // 验证码位数
+	MaxAttempts int    // 每个验证码允许输错的次数，超过后失效
+	SendLimit   int    // 每个账号每个周期最多发送次数，0 表示不限制
+	SendPeriod  int    // 发送次数限制周期（秒）
+	LinkURL     string // 登录链接指向的前端页面，链接带 token 参数；为空时只支持验证码
+}
+
+// RegisterConfig 自助注册配置
+type RegisterConfig struct {
+	Enabled    bool
+	VerifyURL  string // 邮箱验证链接地址（GET /auth/verify 的对外地址，或调用该接口的前端页面），链接带 token 参数
+	VerifyTTL  int    // 注册后完成邮箱验证的期限（秒），过期未验证的账号会被清理
+	SendLimit  int    // 每个邮箱每个周期最多重新发送验证邮件的次数，0 表示不限制
+	SendPeriod int    // 重新发送次数限制周期（秒）
+}
+
+// AuditConfig 审计日志配置
+type AuditConfig struct {
+	RetentionDays int // 审计日志保留天数，0 表示永久保留（支持热更新）
+}
+
+// MetricsConfig 指标接口（/metrics）配置
+type MetricsConfig struct {
+	Username string // HTTP Basic 认证用户名
+	Password string // HTTP Basic 认证密码，为空时不提供 /metrics
+}
+
+// CronConfig 定时任务配置
+type CronConfig struct {
+	Jobs map[string]string // 任务名 -> cron 表达式（支持秒），未设置的任务使用默认时间
+}
+
 type LogConfig struct {
-	Level  string
-	Path   string
-	Format string
+	Level      string
+	Path       string
+	Format     string
+	MaxSize    int               // 单个日志文件最大大小（MB），超过后轮转
+	MaxBackups int               // 保留的历史日志文件数量，0 表示不限制
+	MaxAge     int               // 历史日志文件保留天数，0 表示不限制
+	Compress   bool              // 是否 gzip 压缩历史日志文件
+	Daily      bool              // 是否每天零点轮转
+	LocalTime  bool              // 历史日志文件名使用本地时间
+	Outputs    []LogOutputConfig // 日志输出，为空时同时输出到 path 文件和标准输出
+	Modules    map[string]string // 模块日志级别（http, sql, cron, auth, notify），未设置的模块使用 level
+	Sampling   LogSamplingConfig
+}
+
+// LogOutputConfig 日志输出配置
+type LogOutputConfig struct {
+	Type    string // stdout, stderr, file, syslog
+	Format  string // json, text，为空时使用 log.format
+	Level   string // 该输出的最低级别，为空时不额外过滤
+	Path    string // file: 文件路径，为空时使用 log.path
+	Network string // syslog: unix, unixgram, udp, tcp，为空时连接本机 syslog socket
+	Address string // syslog: 地址
+	Tag     string // syslog: 标签，默认 gosir
+}
+
+// LogSamplingConfig debug 日志采样配置
+type LogSamplingConfig struct {
+	Enabled    bool
+	Tick       int // 采样周期（秒）
+	Initial    int // 每个周期内同一条日志前 N 条全部输出
+	Thereafter int // 之后每 N 条输出 1 条，0 表示全部丢弃
 }
 
-// Load 加载配置（支持配置文件和环境变量，环境变量优先级更高）
+// Load 加载配置（支持配置文件和环境变量，环境变量优先级更高），未设置的配置项使用默认值
+// 加载后校验配置，不合法时返回 *ValidationError
 func Load(path string) (Config, error) {
 	v := viper.New()
+	v.SetEnvPrefix(envPrefix)
+	v.AutomaticEnv()
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	setDefaults(v)
 
 	v.SetConfigFile(path)
 	if err := v.ReadInConfig(); err != nil {
 		return Config{}, fmt.Errorf("failed to load config file: %w", err)
 	}
-	v.SetEnvPrefix("GOSIR")
-	v.AutomaticEnv()
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+
+	if err := loadSecrets(v); err != nil {
+		return Config{}, err
+	}
 
 	var cfg Config
 	if err := v.Unmarshal(&cfg); err != nil {
 		return Config{}, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
 	return cfg, nil
 }
 
@@ -70,18 +299,103 @@ func (c Config) PrintConfig() {
 	fmt.Printf("Server:\n")
 	fmt.Printf("  Port: %d\n", c.Server.Port)
 	fmt.Printf("  Mode: %s\n", c.Server.Mode)
+	fmt.Printf("  ShutdownTimeout: %ds\n", c.Server.ShutdownTimeout)
+	fmt.Printf("  Swagger: %t\n", c.Server.SwaggerEnabled())
+	fmt.Printf("  ErrorDetail: %t\n", c.Server.ErrorDetail)
+	fmt.Printf("  CORS: origins=%v credentials=%t\n", c.Server.HTTP.CORS.AllowOrigins, c.Server.HTTP.CORS.AllowCredentials)
+	fmt.Printf("  TLS: enabled=%t cert=%s selfSigned=%t minVersion=%s clientAuth=%s redirectHTTP=%t redirectPort=%d\n", c.Server.TLS.Enabled, c.Server.TLS.CertFile, c.Server.TLS.SelfSigned, c.Server.TLS.MinVersion, c.Server.TLS.ClientAuth, c.Server.TLS.RedirectHTTP, c.Server.TLS.RedirectPort)
+	fmt.Printf("  MaxBodySize: %dMB\n", c.Server.HTTP.MaxBodySize)
+	fmt.Printf("  Timeouts: read=%ds readHeader=%ds write=%ds idle=%ds\n", c.Server.HTTP.ReadTimeout, c.Server.HTTP.ReadHeaderTimeout, c.Server.HTTP.WriteTimeout, c.Server.HTTP.IdleTimeout)
 	fmt.Println()
 	fmt.Printf("Database:\n")
 	fmt.Printf("  Path: %s\n", c.Database.Path)
 	fmt.Printf("  LogLevel: %s\n", c.Database.LogLevel)
+	fmt.Printf("  MigrationLockTimeout: %ds\n", c.Database.MigrationLockTimeout)
+	fmt.Printf("  MigrationLockTTL: %ds\n", c.Database.MigrationLockTTL)
 	fmt.Println()
 	fmt.Printf("JWT:\n")
 	fmt.Printf("  Secret: %s\n", maskSecret(c.JWT.Secret))
+	fmt.Printf("  SecretFile: %s\n", c.JWT.SecretFile)
 	fmt.Printf("  ExpireHours: %d\n", c.JWT.ExpireHours)
 	fmt.Println()
 	fmt.Printf("Log:\n")
 	fmt.Printf("  Level: %s\n", c.Log.Level)
 	fmt.Printf("  Path: %s\n", c.Log.Path)
 	fmt.Printf("  Format: %s\n", c.Log.Format)
+	fmt.Printf("  MaxSize: %dMB\n", c.Log.MaxSize)
+	fmt.Printf("  MaxBackups: %d\n", c.Log.MaxBackups)
+	fmt.Printf("  MaxAge: %dd\n", c.Log.MaxAge)
+	fmt.Printf("  Compress: %t\n", c.Log.Compress)
+	fmt.Printf("  Daily: %t\n", c.Log.Daily)
+	fmt.Printf("  LocalTime: %t\n", c.Log.LocalTime)
+	for i, out := range c.Log.Outputs {
+		fmt.Printf("  Output[%d]: type=%s format=%s level=%s path=%s address=%s\n", i, out.Type, out.Format, out.Level, out.Path, out.Address)
+	}
+	for name, level := range c.Log.Modules {
+		fmt.Printf("  Module[%s]: %s\n", name, level)
+	}
+	fmt.Printf("  Sampling: enabled=%t tick=%ds initial=%d thereafter=%d\n", c.Log.Sampling.Enabled, c.Log.Sampling.Tick, c.Log.Sampling.Initial, c.Log.Sampling.Thereafter)
+	fmt.Println()
+	fmt.Printf("Tracing:\n")
+	fmt.Printf("  Exporter: %s\n", c.Tracing.Exporter)
+	fmt.Printf("  Endpoint: %s\n", c.Tracing.Endpoint)
+	fmt.Printf("  Insecure: %t\n", c.Tracing.Insecure)
+	fmt.Printf("  FilePath: %s\n", c.Tracing.FilePath)
+	fmt.Printf("  SampleRatio: %g\n", c.Tracing.SampleRatio)
+	fmt.Printf("  ServiceName: %s\n", c.Tracing.ServiceName)
+	fmt.Println()
+	fmt.Printf("Admin:\n")
+	fmt.Printf("  Name: %s\n", c.Admin.Name)
+	fmt.Printf("  Email: %s\n", c.Admin.Email)
+	fmt.Printf("  Phone: %s\n", c.Admin.Phone)
+	fmt.Printf("  Password: %s\n", maskSecret(c.Admin.Password))
+	fmt.Printf("  PasswordFile: %s\n", c.Admin.PasswordFile)
+	fmt.Println()
+	fmt.Printf("RateLimit:\n")
+	fmt.Printf("  Store: %s\n", c.RateLimit.Store)
+	for group, rule := range c.RateLimit.Groups {
+		fmt.Printf("  Group[%s]: algorithm=%s keyBy=%s limit=%d period=%ds burst=%d\n", group, rule.Algorithm, rule.KeyBy, rule.Limit, rule.Period, rule.Burst)
+	}
+	fmt.Println()
+	fmt.Printf("OIDC:\n")
+	for name, provider := range c.OIDC.Providers {
+		fmt.Printf("  Provider[%s]: issuer=%s clientId=%s clientSecret=%s redirectUrl=%s autoCreate=%t linkByEmail=%t allowedDomains=%v\n", name, provider.Issuer, provider.ClientID, maskSecret(provider.ClientSecret), provider.RedirectURL, provider.AutoCreate, provider.LinkByEmail, provider.AllowedDomains)
+	}
+	fmt.Println()
+	fmt.Printf("OAuth:\n")
+	fmt.Printf("  Enabled: %t\n", c.OAuth.Enabled)
+	fmt.Printf("  Issuer: %s\n", c.OAuth.Issuer)
+	fmt.Printf("  SigningKeyFile: %s\n", c.OAuth.SigningKeyFile)
+	fmt.Printf("  TTL: code=%ds accessToken=%ds refreshToken=%ds idToken=%ds session=%ds\n", c.OAuth.CodeTTL, c.OAuth.AccessTokenTTL, c.OAuth.RefreshTokenTTL, c.OAuth.IDTokenTTL, c.OAuth.SessionTTL)
+	fmt.Println()
+	fmt.Printf("Notify:\n")
+	fmt.Printf("  Transport: %s\n", c.Notify.Transport)
+	fmt.Printf("  FilePath: %s\n", c.Notify.FilePath)
+	fmt.Printf("  SMTP: host=%s port=%d username=%s password=%s from=%s tls=%s\n", c.Notify.SMTP.Host, c.Notify.SMTP.Port, c.Notify.SMTP.Username, maskSecret(c.Notify.SMTP.Password), c.Notify.SMTP.From, c.Notify.SMTP.TLS)
+	fmt.Println()
+	fmt.Printf("Passwordless:\n")
+	fmt.Printf("  Enabled: %t\n", c.Passwordless.Enabled)
+	fmt.Printf("  Code: ttl=%ds length=%d maxAttempts=%d\n", c.Passwordless.CodeTTL, c.Passwordless.CodeLength, c.Passwordless.MaxAttempts)
+	fmt.Printf("  SendLimit: %d per %ds\n", c.Passwordless.SendLimit, c.Passwordless.SendPeriod)
+	fmt.Printf("  LinkURL: %s\n", c.Passwordless.LinkURL)
+	fmt.Println()
+	fmt.Printf("Register:\n")
+	fmt.Printf("  Enabled: %t\n", c.Register.Enabled)
+	fmt.Printf("  VerifyURL: %s\n", c.Register.VerifyURL)
+	fmt.Printf("  VerifyTTL: %ds\n", c.Register.VerifyTTL)
+	fmt.Printf("  SendLimit: %d per %ds\n", c.Register.SendLimit, c.Register.SendPeriod)
+	fmt.Println()
+	fmt.Printf("Audit:\n")
+	fmt.Printf("  RetentionDays: %d\n", c.Audit.RetentionDays)
+	fmt.Println()
+	fmt.Printf("Metrics:\n")
+	fmt.Printf("  Enabled: %t\n", c.Metrics.Password != "")
+	fmt.Printf("  Username: %s\n", c.Metrics.Username)
+	fmt.Printf("  Password: %s\n", maskSecret(c.Metrics.Password))
+	fmt.Println()
+	fmt.Printf("Cron:\n")
+	for name, spec := range c.Cron.Jobs {
+		fmt.Printf("  %s: %s\n", name, spec)
+	}
 	fmt.Println("========================================")
 }
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// envPrefix 环境变量前缀，配置项 jwt.expireHours 对应 GOSIR_JWT_EXPIREHOURS
+const envPrefix = "GOSIR"
+
+// Field 配置项说明
+type Field struct {
+	Key         string // 配置项路径
+	Type        string // 类型
+	Default     any    // 默认值，nil 表示没有默认值
+	Description string // 说明
+}
+
+// EnvName 配置项对应的环境变量名
+func (f Field) EnvName() string {
+	return envName(f.Key)
+}
+
+// Fields 所有配置项及默认值
+var Fields = []Field{
+	{"server.port", "int", 1323, "服务端口"},
+	{"server.mode", "string", "debug", "运行模式: debug, release, test"},
+	{"server.shutdownTimeout", "int", 30, "优雅关闭超时时间（秒）"},
+	{"server.swagger", "bool", nil, "开启 Swagger 文档，未设置时 release 模式关闭、其他模式开启"},
+	{"server.errorDetail", "bool", false, "错误响应中包含错误详情（仅用于调试，release 模式不允许开启）"},
+	{"server.http.cors.allowOrigins", "list", []string{"*"}, "跨域允许的来源，* 表示全部"},
+	{"server.http.cors.allowMethods", "list", []string{"GET", "HEAD", "PUT", "PATCH", "POST", "DELETE"}, "跨域允许的请求方法"},
+	{"server.http.cors.allowHeaders", "list", []string{}, "跨域允许的请求头，为空时允许预检请求声明的所有请求头"},
+	{"server.http.cors.exposeHeaders", "list", []string{"X-Request-ID", "ETag"}, "允许浏览器读取的响应头"},
+	{"server.http.cors.allowCredentials", "bool", false, "跨域请求允许携带凭证，开启时不能使用 * 来源"},
+	{"server.http.cors.maxAge", "int", 0, "预检请求结果缓存时间（秒）"},
+	{"server.http.secureHeaders.hstsMaxAge", "int", 31536000, "HSTS max-age（秒），0 表示不发送，只对 HTTPS 请求生效"},
+	{"server.http.secureHeaders.hstsIncludeSubdomains", "bool", false, "HSTS 包含子域名"},
+	{"server.http.secureHeaders.hstsPreload", "bool", false, "HSTS preload"},
+	{"server.http.secureHeaders.contentTypeNosniff", "bool", true, "发送 X-Content-Type-Options: nosniff"},
+	{"server.http.secureHeaders.frameOptions", "string", "DENY", "X-Frame-Options: DENY, SAMEORIGIN，为空表示不发送"},
+	{"server.http.secureHeaders.contentSecurityPolicy", "string", "default-src 'none'; frame-ancestors 'none'", "接口响应的 Content-Security-Policy"},
+	{"server.http.secureHeaders.swaggerCSP", "string", "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data:; frame-ancestors 'none'", "Swagger 页面的 Content-Security-Policy"},
+	{"server.http.maxBodySize", "int", 4, "请求体最大大小（MB），0 表示不限制"},
+	{"server.http.readTimeout", "int", 30, "读取请求的超时时间（秒），0 表示不限制"},
+	{"server.http.readHeaderTimeout", "int", 10, "读取请求头的超时时间（秒），0 表示使用 readTimeout"},
+	{"server.http.writeTimeout", "int", 30, "写响应的超时时间（秒），0 表示不限制"},
+	{"server.http.idleTimeout", "int", 120, "keep-alive 连接的空闲超时时间（秒），0 表示使用 readTimeout"},
+	{"server.tls.enabled", "bool", false, "开启 HTTPS，server.port 监听 HTTPS"},
+	{"server.tls.certFile", "string", "", "证书文件（PEM），收到 SIGHUP 时重新加载"},
+	{"server.tls.keyFile", "string", "", "私钥文件（PEM）"},
+	{"server.tls.selfSigned", "bool", false, "使用启动时生成的 localhost 自签名证书（仅开发环境，release 模式禁止）"},
+	{"server.tls.minVersion", "string", "1.2", "最低 TLS 版本: 1.2, 1.3"},
+	{"server.tls.cipherSuites", "list", []string{}, "TLS 1.2 允许的加密套件，为空时使用 Go 默认值"},
+	{"server.tls.clientCAFile", "string", "", "校验客户端证书的 CA 文件（mTLS）"},
+	{"server.tls.clientAuth", "string", "none", "客户端证书校验: none, optional, require"},
+	{"server.tls.redirectHTTP", "bool", false, "额外监听 redirectPort，将 HTTP 请求重定向到 HTTPS"},
+	{"server.tls.redirectPort", "int", 80, "HTTP 重定向端口"},
+	{"server.http.trustProxy", "bool", false, "信任 X-Forwarded-For/X-Real-IP 获取客户端 IP（部署在反向代理后时开启）"},
+
+	{"database.path", "string", "data.db", "SQLite 数据库文件路径"},
+	{"database.logLevel", "string", "info", "SQL 日志级别: silent, error, warn, info"},
+	{"database.migrationLockTimeout", "int", 60, "等待迁移锁的超时时间（秒）"},
+	{"database.migrationLockTTL", "int", 300, "迁移锁有效期（秒）"},
+
+	{"jwt.secret", "secret", nil, "JWT 签名密钥（必填，release 模式下至少 32 字节）"},
+	{"jwt.secretFile", "string", "", "从文件读取 JWT 签名密钥（优先于 jwt.secret）"},
+	{"jwt.expireHours", "int", 24, "token 有效期（小时）"},
+
+	{"log.level", "string", "info", "日志级别: debug, info, warn, error"},
+	{"log.path", "string", "logs/app.log", "日志文件路径"},
+	{"log.format", "string", "json", "日志格式: json, text"},
+	{"log.maxSize", "int", 100, "单个日志文件最大大小（MB）"},
+	{"log.maxBackups", "int", 7, "保留的历史日志文件数量，0 表示不限制"},
+	{"log.maxAge", "int", 30, "历史日志文件保留天数，0 表示不限制"},
+	{"log.compress", "bool", true, "gzip 压缩历史日志文件"},
+	{"log.daily", "bool", true, "每天零点轮转"},
+	{"log.localTime", "bool", true, "历史日志文件名使用本地时间"},
+	{"log.outputs", "list", nil, "日志输出（type, format, level, path, network, address, tag），为空时输出到文件和标准输出"},
+	{"log.modules", "map", nil, "模块日志级别（http, sql, cron, auth, notify）"},
+	{"log.sampling.enabled", "bool", false, "开启 debug 日志采样"},
+	{"log.sampling.tick", "int", 1, "采样周期（秒）"},
+	{"log.sampling.initial", "int", 100, "每个周期内同一条日志前 N 条全部输出"},
+	{"log.sampling.thereafter", "int", 100, "之后每 N 条输出 1 条，0 表示全部丢弃"},
+
+	{"admin.name", "string", "管理员", "初始管理员名称"},
+	{"admin.email", "string", "admin@gosir.com", "初始管理员邮箱（管理员身份以账号的管理员标记为准，修改邮箱不影响）"},
+	{"admin.phone", "string", "", "初始管理员手机号"},
+	{"admin.password", "secret", "", "初始管理员密码，为空时自动生成"},
+	{"admin.passwordFile", "string", "", "从文件读取初始管理员密码（优先于 admin.password）"},
+
+	{"tracing.exporter", "string", "none", "链路追踪导出器: none, otlp, stdout, file"},
+	{"tracing.endpoint", "string", "localhost:4318", "OTLP/HTTP collector 地址"},
+	{"tracing.insecure", "bool", false, "OTLP 是否使用明文 HTTP"},
+	{"tracing.filePath", "string", "logs/traces.json", "file 导出器的输出文件"},
+	{"tracing.sampleRatio", "float", 1.0, "采样率 0-1"},
+	{"tracing.serviceName", "string", "gosir", "服务名"},
+
+	{"cron.jobs", "map", nil, "覆盖定时任务执行时间（任务名 -> cron 表达式）"},
+
+	{"rateLimit.store", "string", "memory", "限流计数存储: memory（单实例）, database（多实例共享）"},
+	{"rateLimit.groups.auth.algorithm", "string", "sliding_window", "登录等认证接口的限流算法: token_bucket, sliding_window"},
+	{"rateLimit.groups.auth.keyBy", "string", "ip", "认证接口的限流维度: ip, user, apikey"},
+	{"rateLimit.groups.auth.limit", "int", 10, "认证接口每个周期允许的请求数，0 表示不限流"},
+	{"rateLimit.groups.auth.period", "int", 60, "认证接口限流周期（秒）"},
+	{"rateLimit.groups.api.algorithm", "string", "token_bucket", "/api 接口的限流算法"},
+	{"rateLimit.groups.api.keyBy", "string", "user", "/api 接口的限流维度"},
+	{"rateLimit.groups.api.limit", "int", 300, "/api 接口每个周期允许的请求数，0 表示不限流"},
+	{"rateLimit.groups.api.period", "int", 60, "/api 接口限流周期（秒）"},
+	{"rateLimit.groups.api.burst", "int", 60, "/api 接口令牌桶容量，0 表示与 limit 相同"},
+
+	{"oauth.enabled", "bool", false, "作为 OAuth2/OIDC 授权服务，允许其他应用通过 gosir 登录"},
+	{"oauth.issuer", "string", "", "授权服务对外地址（签发者），开启时必填，如 https://auth.example.com"},
+	{"oauth.signingKeyFile", "string", "", "ID Token 签名私钥（PEM，RSA 或 EC），为空时启动时生成临时密钥（release 模式必填）"},
+	{"oauth.codeTTL", "int", 300, "授权码有效期（秒）"},
+	{"oauth.accessTokenTTL", "int", 3600, "访问令牌有效期（秒）"},
+	{"oauth.refreshTokenTTL", "int", 2592000, "刷新令牌有效期（秒）"},
+	{"oauth.idTokenTTL", "int", 600, "ID Token 有效期（秒）"},
+	{"oauth.sessionTTL", "int", 900, "授权页面登录会话有效期（秒），会话只能用于授权页面"},
+
+	{"notify.transport", "string", "log", "通知发送方式: log（写入日志，仅用于开发）, file（追加到文件）, smtp"},
+	{"notify.filePath", "string", "logs/notify.log", "file 方式的输出文件"},
+	{"notify.smtp.host", "string", "", "SMTP 服务器地址"},
+	{"notify.smtp.port", "int", 587, "SMTP 端口"},
+	{"notify.smtp.username", "string", "", "SMTP 用户名，为空表示不认证"},
+	{"notify.smtp.password", "string", "", "SMTP 密码"},
+	{"notify.smtp.passwordFile", "string", "", "从文件读取 SMTP 密码（优先于 notify.smtp.password）"},
+	{"notify.smtp.from", "string", "", "发件人地址"},
+	{"notify.smtp.tls", "string", "starttls", "SMTP 加密方式: starttls, tls, none"},
+
+	{"passwordless.enabled", "bool", false, "开启免密登录（邮箱或手机号接收验证码或登录链接）"},
+	{"passwordless.codeTTL", "int", 600, "验证码和登录链接有效期（秒）"},
+	{"passwordless.codeLength", "int", 6, "验证码位数（6-10）"},
+	{"passwordless.maxAttempts", "int", 5, "每个验证码允许输错的次数，超过后失效"},
+	{"passwordless.sendLimit", "int", 5, "每个账号每个周期最多发送次数，0 表示不限制"},
+	{"passwordless.sendPeriod", "int", 3600, "发送次数限制周期（秒）"},
+	{"passwordless.linkUrl", "string", "", "登录链接指向的前端页面（链接带 token 参数），为空时只支持验证码"},
+
+	{"register.enabled", "bool", false, "开启自助注册（POST /auth/register），注册后需要验证邮箱才能登录"},
+	{"register.verifyUrl", "string", "", "邮箱验证链接地址（GET /auth/verify 的对外地址或前端页面，链接带 token 参数）"},
+	{"register.verifyTTL", "int", 86400, "注册后完成邮箱验证的期限（秒），过期未验证的账号会被清理"},
+	{"register.sendLimit", "int", 3, "每个邮箱每个周期最多重新发送验证邮件的次数，0 表示不限制"},
+	{"register.sendPeriod", "int", 3600, "重新发送次数限制周期（秒）"},
+
+	{"audit.retentionDays", "int", 180, "审计日志保留天数，0 表示永久保留（支持热更新）"},
+
+	{"metrics.username", "string", "prometheus", "/metrics 的 HTTP Basic 认证用户名"},
+	{"metrics.password", "string", "", "/metrics 的 HTTP Basic 认证密码，为空时不提供 /metrics"},
+	{"metrics.passwordFile", "string", "", "从文件读取 /metrics 的认证密码（优先于 metrics.password）"},
+
+	{"oidc.providers", "map", nil, "OIDC 登录提供方（名称 -> issuer, clientId, clientSecret, clientSecretFile, redirectUrl, scopes, autoCreate, linkByEmail, allowedDomains）"},
+}
+
+// setDefaults 设置所有配置项的默认值，没有默认值的配置项绑定环境变量（配置文件中未出现时环境变量也能生效）
+// 需要在设置环境变量前缀之后调用
+func setDefaults(v *viper.Viper) {
+	for _, f := range Fields {
+		if f.Default != nil {
+			v.SetDefault(f.Key, f.Default)
+		} else {
+			_ = v.BindEnv(f.Key)
+		}
+	}
+}
+
+// envName 配置项对应的环境变量名
+func envName(key string) string {
+	return envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// secretKeys 敏感配置项：支持从文件读取（Docker/Kubernetes secrets），打印和变更日志中脱敏
+// OIDC 提供方的 clientSecret（oidc.providers.{name}.clientSecret）同样是敏感配置项，见 providerSecretKeys
+var secretKeys = []string{"jwt.secret", "admin.password", "notify.smtp.password", "metrics.password"}
+
+// providerSecretField OIDC 提供方配置中的敏感字段
+const providerSecretField = "clientSecret"
+
+// providerSecretKeys 已配置的每个 OIDC 提供方的 clientSecret 配置项
+func providerSecretKeys(v *viper.Viper) []string {
+	var keys []string
+	for name := range v.GetStringMap("oidc.providers") {
+		keys = append(keys, "oidc.providers."+name+"."+providerSecretField)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// isSecretKey 配置项是否为敏感配置项
+func isSecretKey(key string) bool {
+	if slices.Contains(secretKeys, key) {
+		return true
+	}
+	name, ok := strings.CutPrefix(key, "oidc.providers.")
+	if !ok {
+		return false
+	}
+	name, ok = strings.CutSuffix(name, "."+providerSecretField)
+	return ok && name != "" && !strings.Contains(name, ".")
+}
+
+// loadSecrets 从文件读取敏感配置项，文件末尾的换行会被去掉
+// 优先级：环境变量 GOSIR_JWT_SECRET_FILE > 配置项 jwt.secretFile > jwt.secret（含环境变量 GOSIR_JWT_SECRET）
+// OIDC 提供方：GOSIR_OIDC_PROVIDERS_{NAME}_CLIENTSECRET_FILE > oidc.providers.{name}.clientSecretFile > clientSecret
+func loadSecrets(v *viper.Viper) error {
+	for _, key := range secretKeys {
+		value, ok, err := readSecretFile(v, key)
+		if err != nil {
+			return err
+		}
+		if ok {
+			v.Set(key, value)
+		}
+	}
+
+	// 单独设置 map 中的某个字段会覆盖配置文件中该提供方的其他字段，因此修改后整体写回
+	providers := v.GetStringMap("oidc.providers")
+	changed := false
+	for _, key := range providerSecretKeys(v) {
+		value, ok, err := readSecretFile(v, key)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "oidc.providers."), "."+providerSecretField)
+		provider, isMap := providers[name].(map[string]any)
+		if !ok || !isMap {
+			continue
+		}
+		provider[strings.ToLower(providerSecretField)] = value
+		changed = true
+	}
+	if changed {
+		v.Set("oidc.providers", providers)
+	}
+	return nil
+}
+
+// readSecretFile 读取敏感配置项对应的文件，未配置文件时返回 ok = false
+func readSecretFile(v *viper.Viper, key string) (value string, ok bool, err error) {
+	path := os.Getenv(envName(key) + "_FILE")
+	if path == "" {
+		path = v.GetString(key + "File")
+	}
+	if path == "" {
+		return "", false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s from file: %w", key, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
@@ -0,0 +1,284 @@
+package config
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+
+	"gosir/internal/tlsconfig"
+)
+
+// SampleJWTSecret 仓库中示例配置使用的 JWT 密钥，release 模式下禁止使用
+const SampleJWTSecret = "XC0VfuGRumdG47PqxvqC7OIDWuyGY0bUVr6+o1CHHoY="
+
+// minReleaseSecretLength release 模式下 JWT 密钥的最小长度（字节）
+const minReleaseSecretLength = 32
+
+var (
+	serverModes     = []string{"debug", "release", "test"}
+	frameOptions    = []string{"", "DENY", "SAMEORIGIN"}
+	tlsVersions     = []string{"1.2", "1.3"}
+	tlsClientAuth   = []string{"none", "optional", "require"}
+	logLevels       = []string{"debug", "info", "warn", "error"}
+	logFormats      = []string{"json", "text"}
+	logOutputTypes  = []string{"stdout", "stderr", "file", "syslog"}
+	syslogNetworks  = []string{"", "unix", "unixgram", "udp", "tcp"}
+	databaseLevels  = []string{"silent", "error", "warn", "info"}
+	tracingExporter = []string{"none", "otlp", "stdout", "file"}
+	rateLimitStores = []string{"memory", "database"}
+	rateLimitGroups = []string{"auth", "api", "admin"}
+	rateLimitAlgos  = []string{"token_bucket", "sliding_window"}
+	rateLimitKeys   = []string{"ip", "user", "apikey"}
+	notifyTransport = []string{"log", "file", "smtp"}
+	smtpTLSModes    = []string{"starttls", "tls", "none"}
+)
+
+// oidcProviderName OIDC 提供方名称（用于登录地址）
+var oidcProviderName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// cronParser 与定时任务调度器一致的解析器（支持秒）
+var cronParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ValidationError 配置校验错误，包含所有不合法的配置项
+type ValidationError struct {
+	Errors []error
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Errors)+1)
+	lines = append(lines, "invalid config:")
+	for _, err := range e.Errors {
+		lines = append(lines, "  - "+err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return e.Errors
+}
+
+// Validate 校验配置，返回包含所有错误的 *ValidationError
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+		}
+	}
+	oneOf := func(value, key string, allowed []string) {
+		check(slices.Contains(allowed, value), key, "must be one of %v, got %q", allowed, value)
+	}
+
+	// server
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port", "must be between 1 and 65535, got %d", c.Server.Port)
+	oneOf(c.Server.Mode, "server.mode", serverModes)
+	check(!c.Server.ErrorDetail || c.Server.Mode != "release", "server.errorDetail", "must not be enabled in release mode")
+	check(c.Server.ShutdownTimeout >= 0, "server.shutdownTimeout", "must not be negative, got %d", c.Server.ShutdownTimeout)
+
+	// server.tls
+	tlsCfg := c.Server.TLS
+	if tlsCfg.Enabled {
+		if tlsCfg.SelfSigned {
+			check(c.Server.Mode != "release", "server.tls.selfSigned", "must not be used in release mode")
+		} else {
+			check(tlsCfg.CertFile != "", "server.tls.certFile", "is required unless selfSigned is enabled")
+			check(tlsCfg.KeyFile != "", "server.tls.keyFile", "is required unless selfSigned is enabled")
+		}
+		oneOf(tlsCfg.MinVersion, "server.tls.minVersion", tlsVersions)
+		if _, err := tlsconfig.ParseCipherSuites(tlsCfg.CipherSuites); err != nil {
+			errs = append(errs, fmt.Errorf("server.tls.cipherSuites: %v", err))
+		}
+		oneOf(tlsCfg.ClientAuth, "server.tls.clientAuth", tlsClientAuth)
+		if tlsCfg.ClientAuth != "none" {
+			check(tlsCfg.ClientCAFile != "", "server.tls.clientCAFile", "is required when clientAuth is %s", tlsCfg.ClientAuth)
+		}
+		if tlsCfg.RedirectHTTP {
+			check(tlsCfg.RedirectPort > 0 && tlsCfg.RedirectPort <= 65535, "server.tls.redirectPort", "must be between 1 and 65535, got %d", tlsCfg.RedirectPort)
+			check(tlsCfg.RedirectPort != c.Server.Port, "server.tls.redirectPort", "must differ from server.port")
+		}
+	}
+
+	// server.http
+	httpCfg := c.Server.HTTP
+	check(len(httpCfg.CORS.AllowOrigins) > 0, "server.http.cors.allowOrigins", "must not be empty")
+	if httpCfg.CORS.AllowCredentials {
+		check(!slices.Contains(httpCfg.CORS.AllowOrigins, "*"), "server.http.cors.allowOrigins", "must list explicit origins when allowCredentials is enabled")
+	}
+	check(httpCfg.CORS.MaxAge >= 0, "server.http.cors.maxAge", "must not be negative, got %d", httpCfg.CORS.MaxAge)
+	check(httpCfg.SecureHeaders.HSTSMaxAge >= 0, "server.http.secureHeaders.hstsMaxAge", "must not be negative, got %d", httpCfg.SecureHeaders.HSTSMaxAge)
+	oneOf(httpCfg.SecureHeaders.FrameOptions, "server.http.secureHeaders.frameOptions", frameOptions)
+	check(httpCfg.MaxBodySize >= 0, "server.http.maxBodySize", "must not be negative, got %d", httpCfg.MaxBodySize)
+	check(httpCfg.ReadTimeout >= 0, "server.http.readTimeout", "must not be negative, got %d", httpCfg.ReadTimeout)
+	check(httpCfg.ReadHeaderTimeout >= 0, "server.http.readHeaderTimeout", "must not be negative, got %d", httpCfg.ReadHeaderTimeout)
+	check(httpCfg.WriteTimeout >= 0, "server.http.writeTimeout", "must not be negative, got %d", httpCfg.WriteTimeout)
+	check(httpCfg.IdleTimeout >= 0, "server.http.idleTimeout", "must not be negative, got %d", httpCfg.IdleTimeout)
+
+	// database
+	check(c.Database.Path != "", "database.path", "is required")
+	oneOf(c.Database.LogLevel, "database.logLevel", databaseLevels)
+	check(c.Database.MigrationLockTimeout > 0, "database.migrationLockTimeout", "must be positive, got %d", c.Database.MigrationLockTimeout)
+	check(c.Database.MigrationLockTTL > 0, "database.migrationLockTTL", "must be positive, got %d", c.Database.MigrationLockTTL)
+
+	// jwt
+	check(c.JWT.Secret != "", "jwt.secret", "is required (set jwt.secret, jwt.secretFile or %s_FILE)", envName("jwt.secret"))
+	check(c.JWT.ExpireHours > 0, "jwt.expireHours", "must be positive, got %d", c.JWT.ExpireHours)
+	if c.Server.Mode == "release" && c.JWT.Secret != "" {
+		check(len(c.JWT.Secret) >= minReleaseSecretLength, "jwt.secret", "must be at least %d bytes in release mode, got %d", minReleaseSecretLength, len(c.JWT.Secret))
+		check(c.JWT.Secret != SampleJWTSecret, "jwt.secret", "must not be the sample secret from the repository in release mode")
+	}
+
+	// log
+	oneOf(c.Log.Level, "log.level", logLevels)
+	oneOf(c.Log.Format, "log.format", logFormats)
+	check(c.Log.MaxSize >= 0, "log.maxSize", "must not be negative, got %d", c.Log.MaxSize)
+	check(c.Log.MaxBackups >= 0, "log.maxBackups", "must not be negative, got %d", c.Log.MaxBackups)
+	check(c.Log.MaxAge >= 0, "log.maxAge", "must not be negative, got %d", c.Log.MaxAge)
+	for i, out := range c.Log.Outputs {
+		key := fmt.Sprintf("log.outputs[%d]", i)
+		oneOf(out.Type, key+".type", logOutputTypes)
+		if out.Format != "" {
+			oneOf(out.Format, key+".format", logFormats)
+		}
+		if out.Level != "" {
+			oneOf(out.Level, key+".level", logLevels)
+		}
+		if out.Type == "file" {
+			check(out.Path != "" || c.Log.Path != "", key+".path", "is required when log.path is empty")
+		}
+		if out.Type == "syslog" {
+			oneOf(out.Network, key+".network", syslogNetworks)
+			check(out.Network == "" || out.Address != "", key+".address", "is required when network is set")
+		}
+	}
+	if len(c.Log.Outputs) == 0 {
+		check(c.Log.Path != "", "log.path", "is required")
+	}
+	for name, level := range c.Log.Modules {
+		oneOf(level, "log.modules."+name, logLevels)
+	}
+	check(c.Log.Sampling.Tick >= 0, "log.sampling.tick", "must not be negative, got %d", c.Log.Sampling.Tick)
+	check(c.Log.Sampling.Initial >= 0, "log.sampling.initial", "must not be negative, got %d", c.Log.Sampling.Initial)
+	check(c.Log.Sampling.Thereafter >= 0, "log.sampling.thereafter", "must not be negative, got %d", c.Log.Sampling.Thereafter)
+
+	// admin
+	_, err := mail.ParseAddress(c.Admin.Email)
+	check(err == nil, "admin.email", "must be a valid email address, got %q", c.Admin.Email)
+
+	// tracing
+	oneOf(c.Tracing.Exporter, "tracing.exporter", tracingExporter)
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sampleRatio", "must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+	check(c.Tracing.ServiceName != "", "tracing.serviceName", "is required")
+	if c.Tracing.Exporter == "otlp" {
+		check(c.Tracing.Endpoint != "", "tracing.endpoint", "is required for the otlp exporter")
+	}
+	if c.Tracing.Exporter == "file" {
+		check(c.Tracing.FilePath != "", "tracing.filePath", "is required for the file exporter")
+	}
+
+	// cron
+	for name, spec := range c.Cron.Jobs {
+		if _, err := cronParser.Parse(spec); err != nil {
+			errs = append(errs, fmt.Errorf("cron.jobs.%s: invalid spec %q: %v", name, spec, err))
+		}
+	}
+
+	// rateLimit
+	oneOf(c.RateLimit.Store, "rateLimit.store", rateLimitStores)
+	for group, rule := range c.RateLimit.Groups {
+		key := "rateLimit.groups." + group
+		check(slices.Contains(rateLimitGroups, group), key, "unknown route group, must be one of %v", rateLimitGroups)
+		oneOf(rule.Algorithm, key+".algorithm", rateLimitAlgos)
+		oneOf(rule.KeyBy, key+".keyBy", rateLimitKeys)
+		check(rule.Limit >= 0, key+".limit", "must not be negative, got %d", rule.Limit)
+		check(rule.Period > 0, key+".period", "must be positive, got %d", rule.Period)
+		check(rule.Burst >= 0, key+".burst", "must not be negative, got %d", rule.Burst)
+	}
+
+	// oidc
+	for name, provider := range c.OIDC.Providers {
+		key := "oidc.providers." + name
+		check(oidcProviderName.MatchString(name), key, "name may only contain lowercase letters, digits, '-' and '_'")
+		issuer, err := url.Parse(provider.Issuer)
+		check(err == nil && (issuer.Scheme == "https" || issuer.Scheme == "http") && issuer.Host != "", key+".issuer", "must be an absolute http(s) URL, got %q", provider.Issuer)
+		if c.Server.Mode == "release" && err == nil {
+			check(issuer.Scheme == "https", key+".issuer", "must use https in release mode")
+		}
+		check(provider.ClientID != "", key+".clientId", "is required")
+		redirect, err := url.Parse(provider.RedirectURL)
+		callbackPath := "/auth/oidc/" + name + "/callback"
+		check(err == nil && redirect.IsAbs() && strings.HasSuffix(redirect.Path, callbackPath), key+".redirectUrl", "must be an absolute URL ending with %s, got %q", callbackPath, provider.RedirectURL)
+	}
+
+	// oauth
+	if c.OAuth.Enabled {
+		issuer, err := url.Parse(c.OAuth.Issuer)
+		check(err == nil && (issuer.Scheme == "https" || issuer.Scheme == "http") && issuer.Host != "" && issuer.RawQuery == "" && issuer.Fragment == "", "oauth.issuer", "must be an absolute http(s) URL without query or fragment, got %q", c.OAuth.Issuer)
+		if c.Server.Mode == "release" {
+			check(err == nil && issuer.Scheme == "https", "oauth.issuer", "must use https in release mode")
+			check(c.OAuth.SigningKeyFile != "", "oauth.signingKeyFile", "is required in release mode")
+		}
+		check(c.OAuth.CodeTTL > 0, "oauth.codeTTL", "must be positive, got %d", c.OAuth.CodeTTL)
+		check(c.OAuth.AccessTokenTTL > 0, "oauth.accessTokenTTL", "must be positive, got %d", c.OAuth.AccessTokenTTL)
+		check(c.OAuth.RefreshTokenTTL > 0, "oauth.refreshTokenTTL", "must be positive, got %d", c.OAuth.RefreshTokenTTL)
+		check(c.OAuth.IDTokenTTL > 0, "oauth.idTokenTTL", "must be positive, got %d", c.OAuth.IDTokenTTL)
+		check(c.OAuth.SessionTTL > 0, "oauth.sessionTTL", "must be positive, got %d", c.OAuth.SessionTTL)
+	}
+
+	// notify
+	oneOf(c.Notify.Transport, "notify.transport", notifyTransport)
+	switch c.Notify.Transport {
+	case "file":
+		check(c.Notify.FilePath != "", "notify.filePath", "is required when notify.transport is file")
+	case "smtp":
+		smtp := c.Notify.SMTP
+		check(smtp.Host != "", "notify.smtp.host", "is required when notify.transport is smtp")
+		check(smtp.Port > 0 && smtp.Port <= 65535, "notify.smtp.port", "must be between 1 and 65535, got %d", smtp.Port)
+		_, err := mail.ParseAddress(smtp.From)
+		check(err == nil, "notify.smtp.from", "must be a valid email address, got %q", smtp.From)
+		oneOf(smtp.TLS, "notify.smtp.tls", smtpTLSModes)
+	}
+
+	// passwordless
+	if c.Passwordless.Enabled {
+		p := c.Passwordless
+		check(p.CodeTTL > 0, "passwordless.codeTTL", "must be positive, got %d", p.CodeTTL)
+		check(p.CodeLength >= 6 && p.CodeLength <= 10, "passwordless.codeLength", "must be between 6 and 10, got %d", p.CodeLength)
+		check(p.MaxAttempts > 0, "passwordless.maxAttempts", "must be positive, got %d", p.MaxAttempts)
+		check(p.SendLimit >= 0, "passwordless.sendLimit", "must not be negative, got %d", p.SendLimit)
+		check(p.SendPeriod > 0, "passwordless.sendPeriod", "must be positive, got %d", p.SendPeriod)
+		if p.LinkURL != "" {
+			link, err := url.Parse(p.LinkURL)
+			check(err == nil && (link.Scheme == "https" || link.Scheme == "http") && link.Host != "" && link.Fragment == "", "passwordless.linkUrl", "must be an absolute http(s) URL without fragment, got %q", p.LinkURL)
+		}
+	}
+
+	// register
+	if c.Register.Enabled {
+		r := c.Register
+		verify, err := url.Parse(r.VerifyURL)
+		check(err == nil && (verify.Scheme == "https" || verify.Scheme == "http") && verify.Host != "" && verify.Fragment == "", "register.verifyUrl", "must be an absolute http(s) URL without fragment, got %q", r.VerifyURL)
+		check(r.VerifyTTL > 0, "register.verifyTTL", "must be positive, got %d", r.VerifyTTL)
+		check(r.SendLimit >= 0, "register.sendLimit", "must not be negative, got %d", r.SendLimit)
+		check(r.SendPeriod > 0, "register.sendPeriod", "must be positive, got %d", r.SendPeriod)
+	}
+	// audit
+	check(c.Audit.RetentionDays >= 0, "audit.retentionDays", "must not be negative, got %d", c.Audit.RetentionDays)
+	check(c.Metrics.Password == "" || c.Metrics.Username != "", "metrics.username", "is required when metrics.password is set")
+
+	if (c.Passwordless.Enabled || c.Register.Enabled) && c.Server.Mode == "release" {
+		check(c.Notify.Transport != "log", "notify.transport", "must not be log in release mode when passwordless login or registration is enabled (codes and links would be written to the log)")
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	slices.SortFunc(errs, func(a, b error) int {
+		return strings.Compare(a.Error(), b.Error())
+	})
+	return &ValidationError{Errors: errs}
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"gosir/internal/logger"
+)
+
+// reloadDebounce 配置文件变更后的等待时间，编辑器保存文件时通常会触发多次事件
+const reloadDebounce = 200 * time.Millisecond
+
+// reloadableKeys 支持运行时生效的配置项（按前缀匹配），其他配置项修改后需要重启
+var reloadableKeys = []string{
+	"log.level",
+	"log.modules",
+	"database.logLevel",
+	"jwt.expireHours",
+	"cron.jobs",
+	"server.http.cors",
+	"rateLimit.groups",
+	"audit.retentionDays",
+}
+
+// ErrRestartRequired 修改了不支持运行时生效的配置项
+var ErrRestartRequired = errors.New("changes require a restart")
+
+// Change 单个配置项的变更
+type Change struct {
+	Key string // 配置项路径，如 log.level
+	Old any
+	New any
+}
+
+// Subscriber 配置变更订阅者，old 为变更前的配置，new 为变更后的配置
+type Subscriber func(old, new Config) error
+
+// subscriber 已注册的订阅者
+type subscriber struct {
+	name string
+	fn   Subscriber
+}
+
+// Watcher 配置文件监听器，文件变化时重新加载并校验配置，通知订阅者生效
+type Watcher struct {
+	path string
+
+	mu          sync.Mutex
+	current     Config
+	subscribers []subscriber
+	debounce    *time.Timer
+}
+
+// NewWatcher 创建配置监听器，current 为当前生效的配置
+func NewWatcher(path string, current Config) *Watcher {
+	return &Watcher{path: path, current: current}
+}
+
+// Subscribe 订阅配置变更，订阅者按注册顺序调用
+func (w *Watcher) Subscribe(name string, fn Subscriber) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, subscriber{name: name, fn: fn})
+}
+
+// Current 获取当前生效的配置
+func (w *Watcher) Current() Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Start 开始监听配置文件
+func (w *Watcher) Start() error {
+	v := viper.New()
+	v.SetConfigFile(w.path)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to load config file: %w", err)
+	}
+
+	v.OnConfigChange(func(event fsnotify.Event) {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		if w.debounce != nil {
+			w.debounce.Stop()
+		}
+		w.debounce = time.AfterFunc(reloadDebounce, func() {
+			if err := w.Reload(); err != nil {
+				logger.Error("Failed to reload config", zap.String("path", w.path), zap.Error(err))
+			}
+		})
+	})
+	v.WatchConfig()
+
+	logger.Info("Watching config file for changes", zap.String("path", w.path))
+	return nil
+}
+
+// Reload 重新加载配置文件
+// 配置无效或修改了不支持运行时生效的配置项时拒绝整个变更，当前配置保持不变
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	next, err := Load(w.path)
+	if err != nil {
+		return err
+	}
+
+	changes := Diff(w.current, next)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	var rejected []string
+	for _, change := range changes {
+		if !isReloadable(change.Key) {
+			rejected = append(rejected, change.Key)
+		}
+	}
+	if len(rejected) > 0 {
+		return fmt.Errorf("%w: %s", ErrRestartRequired, strings.Join(rejected, ", "))
+	}
+
+	for _, change := range changes {
+		logger.Info("Config changed",
+			zap.String("key", change.Key),
+			zap.Any("old", change.Old),
+			zap.Any("new", change.New),
+		)
+	}
+
+	old := w.current
+	w.current = next
+
+	var errs []error
+	for _, s := range w.subscribers {
+		if err := s.fn(old, next); err != nil {
+			logger.Error("Failed to apply config change", zap.String("subscriber", s.name), zap.Error(err))
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Diff 比较两份配置，返回变更的配置项（敏感配置项的值已脱敏）
+func Diff(old, new Config) []Change {
+	var changes []Change
+	diffValue("", reflect.ValueOf(old), reflect.ValueOf(new), &changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+// diffValue 递归比较结构体字段和 map 元素，其他类型整体比较
+func diffValue(key string, a, b reflect.Value, changes *[]Change) {
+	switch a.Kind() {
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			diffValue(joinKey(key, configKey(a.Type().Field(i).Name)), a.Field(i), b.Field(i), changes)
+		}
+	case reflect.Map:
+		keys := make(map[string]reflect.Value)
+		for _, k := range a.MapKeys() {
+			keys[k.String()] = k
+		}
+		for _, k := range b.MapKeys() {
+			keys[k.String()] = k
+		}
+		for name, k := range keys {
+			av, bv := a.MapIndex(k), b.MapIndex(k)
+			switch {
+			case !av.IsValid():
+				*changes = append(*changes, newChange(joinKey(key, name), nil, bv.Interface()))
+			case !bv.IsValid():
+				*changes = append(*changes, newChange(joinKey(key, name), av.Interface(), nil))
+			default:
+				diffValue(joinKey(key, name), av, bv, changes)
+			}
+		}
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*changes = append(*changes, newChange(key, a.Interface(), b.Interface()))
+		}
+	}
+}
+
+// newChange 创建变更记录，敏感配置项的值替换为掩码
+func newChange(key string, old, new any) Change {
+	if isSecretKey(key) {
+		return Change{Key: key, Old: "******", New: "******"}
+	}
+	return Change{Key: key, Old: old, New: new}
+}
+
+// isReloadable 配置项是否支持运行时生效
+func isReloadable(key string) bool {
+	for _, prefix := range reloadableKeys {
+		if key == prefix || strings.HasPrefix(key, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// configKey 将字段名转换为配置文件中的 key（JWT -> jwt，ExpireHours -> expireHours，HSTSMaxAge -> hstsMaxAge）
+func configKey(field string) string {
+	if strings.ToUpper(field) == field {
+		return strings.ToLower(field)
+	}
+	runes := []rune(field)
+	upper := 0
+	for upper < len(runes) && unicode.IsUpper(runes[upper]) {
+		upper++
+	}
+	// 开头的缩写保留最后一个大写字母作为下一个单词的首字母
+	if upper > 1 {
+		upper--
+	}
+	for i := 0; i < upper; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// joinKey 拼接配置项路径
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
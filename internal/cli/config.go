@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"gosir/config"
+
+	"github.com/spf13/cobra"
+)
+
+// newConfigCommand 配置管理命令
+func newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "配置管理",
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "print",
+			Short: "打印当前配置（敏感信息已脱敏）",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cfg, err := loadConfig()
+				if err != nil {
+					return err
+				}
+				cfg.PrintConfig()
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "validate",
+			Short: "校验配置文件（包含环境变量和密钥文件），列出所有错误",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cfg, err := loadConfig()
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "配置文件有效: %s (mode=%s)\n", configPath, cfg.Server.Mode)
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "schema",
+			Short: "列出所有配置项、默认值和对应的环境变量",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "KEY\tTYPE\tDEFAULT\tENV\tDESCRIPTION")
+				for _, f := range config.Fields {
+					def := "-"
+					if f.Default != nil {
+						def = fmt.Sprintf("%v", f.Default)
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", f.Key, f.Type, def, f.EnvName(), f.Description)
+				}
+				return w.Flush()
+			},
+		},
+	)
+	return cmd
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"gosir/internal/cron"
+
+	"github.com/spf13/cobra"
+)
+
+// newCronCommand 定时任务管理命令
+func newCronCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cron",
+		Short: "定时任务管理",
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "列出已注册的定时任务",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cfg, cleanup, err := bootstrapAdmin()
+				if err != nil {
+					return err
+				}
+				defer cleanup()
+
+				w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "NAME\tSPEC\tDESCRIPTION")
+				for _, job := range cron.NewManager(cfg.Cron.Jobs).Jobs() {
+					fmt.Fprintf(w, "%s\t%s\t%s\n", job.Name, job.Spec, job.Description)
+				}
+				return w.Flush()
+			},
+		},
+		&cobra.Command{
+			Use:   "run <name>",
+			Short: "立即执行指定的定时任务",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cfg, cleanup, err := bootstrapAdmin()
+				if err != nil {
+					return err
+				}
+				defer cleanup()
+
+				if err := cron.NewManager(cfg.Cron.Jobs).Run(args[0]); err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "任务已执行: %s\n", args[0])
+				return nil
+			},
+		},
+	)
+	return cmd
+}
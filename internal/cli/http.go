@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gosir/config"
+	"gosir/internal/middleware"
+	"gosir/internal/ratelimit"
+	"gosir/internal/tlsconfig"
+
+	"github.com/labstack/echo/v4"
+)
+
+// httpMiddlewares 根据配置构建 HTTP 中间件：安全响应头 → 跨域 → 请求体大小限制
+func httpMiddlewares(cfg config.Config) []echo.MiddlewareFunc {
+	httpCfg := cfg.Server.HTTP
+	return []echo.MiddlewareFunc{
+		middleware.SecureHeadersMiddleware(middleware.SecureHeadersOptions{
+			HSTSMaxAge:            httpCfg.SecureHeaders.HSTSMaxAge,
+			HSTSIncludeSubdomains: httpCfg.SecureHeaders.HSTSIncludeSubdomains,
+			HSTSPreload:           httpCfg.SecureHeaders.HSTSPreload,
+			ContentTypeNosniff:    httpCfg.SecureHeaders.ContentTypeNosniff,
+			FrameOptions:          httpCfg.SecureHeaders.FrameOptions,
+			ContentSecurityPolicy: httpCfg.SecureHeaders.ContentSecurityPolicy,
+			SwaggerCSP:            httpCfg.SecureHeaders.SwaggerCSP,
+		}),
+		middleware.CORSMiddleware(corsOptions(cfg)),
+		middleware.BodyLimitMiddleware(httpCfg.MaxBodySize),
+	}
+}
+
+// corsOptions 从配置构建跨域选项
+func corsOptions(cfg config.Config) middleware.CORSOptions {
+	cors := cfg.Server.HTTP.CORS
+	return middleware.CORSOptions{
+		AllowOrigins:     cors.AllowOrigins,
+		AllowMethods:     cors.AllowMethods,
+		AllowHeaders:     cors.AllowHeaders,
+		ExposeHeaders:    cors.ExposeHeaders,
+		AllowCredentials: cors.AllowCredentials,
+		MaxAge:           cors.MaxAge,
+	}
+}
+
+// configureHTTPServer 设置 HTTP/HTTPS 服务的超时时间和客户端 IP 的获取方式
+func configureHTTPServer(e *echo.Echo, cfg config.Config) {
+	httpCfg := cfg.Server.HTTP
+	for _, s := range []*http.Server{e.Server, e.TLSServer} {
+		s.ReadTimeout = time.Duration(httpCfg.ReadTimeout) * time.Second
+		s.ReadHeaderTimeout = time.Duration(httpCfg.ReadHeaderTimeout) * time.Second
+		s.WriteTimeout = time.Duration(httpCfg.WriteTimeout) * time.Second
+		s.IdleTimeout = time.Duration(httpCfg.IdleTimeout) * time.Second
+	}
+
+	// 不在反向代理后时忽略客户端伪造的 X-Forwarded-For，避免绕过按 IP 限流
+	if httpCfg.TrustProxy {
+		e.IPExtractor = echo.ExtractIPFromXFFHeader()
+	} else {
+		e.IPExtractor = echo.ExtractIPDirect()
+	}
+}
+
+// rateLimitRules 从配置构建各路由组的限流规则
+func rateLimitRules(cfg config.Config) map[string]ratelimit.Rule {
+	rules := make(map[string]ratelimit.Rule, len(cfg.RateLimit.Groups))
+	for group, rule := range cfg.RateLimit.Groups {
+		rules[group] = ratelimit.Rule{
+			Algorithm: rule.Algorithm,
+			KeyBy:     rule.KeyBy,
+			Limit:     rule.Limit,
+			Period:    time.Duration(rule.Period) * time.Second,
+			Burst:     rule.Burst,
+		}
+	}
+	return rules
+}
+
+// newTLSManager 加载 HTTPS 证书，未开启 HTTPS 时返回 nil
+func newTLSManager(cfg config.Config) (*tlsconfig.Manager, error) {
+	tlsCfg := cfg.Server.TLS
+	if !tlsCfg.Enabled {
+		return nil, nil
+	}
+	return tlsconfig.New(tlsconfig.Options{
+		CertFile:     tlsCfg.CertFile,
+		KeyFile:      tlsCfg.KeyFile,
+		SelfSigned:   tlsCfg.SelfSigned,
+		MinVersion:   tlsCfg.MinVersion,
+		CipherSuites: tlsCfg.CipherSuites,
+		ClientCAFile: tlsCfg.ClientCAFile,
+		ClientAuth:   tlsCfg.ClientAuth,
+	})
+}
+
+// startServer 启动 HTTP 服务，tlsManager 不为空时监听 HTTPS
+func startServer(e *echo.Echo, addr string, tlsManager *tlsconfig.Manager) error {
+	if tlsManager == nil {
+		return e.Start(addr)
+	}
+	e.TLSServer.Addr = addr
+	e.TLSServer.TLSConfig = tlsManager.TLSConfig()
+	return e.StartServer(e.TLSServer)
+}
+
+// newRedirectServer 创建将 HTTP 请求重定向到 HTTPS 的服务，未开启时返回 nil
+func newRedirectServer(cfg config.Config) *http.Server {
+	tlsCfg := cfg.Server.TLS
+	if !tlsCfg.Enabled || !tlsCfg.RedirectHTTP {
+		return nil
+	}
+	httpCfg := cfg.Server.HTTP
+	return &http.Server{
+		Addr:              ":" + strconv.Itoa(tlsCfg.RedirectPort),
+		Handler:           redirectToHTTPS(cfg.Server.Port),
+		ReadTimeout:       time.Duration(httpCfg.ReadTimeout) * time.Second,
+		ReadHeaderTimeout: time.Duration(httpCfg.ReadHeaderTimeout) * time.Second,
+		WriteTimeout:      time.Duration(httpCfg.WriteTimeout) * time.Second,
+		IdleTimeout:       time.Duration(httpCfg.IdleTimeout) * time.Second,
+	}
+}
+
+// redirectToHTTPS 将请求重定向到相同主机的 HTTPS 端口（GET/HEAD 使用 301，其他方法使用 308 保留请求方法和请求体）
+func redirectToHTTPS(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		} else {
+			host = strings.Trim(host, "[]")
+		}
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+
+		status := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			status = http.StatusMovedPermanently
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), status)
+	})
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"fmt"
+
+	"gosir/internal/service/system"
+
+	"github.com/spf13/cobra"
+)
+
+// newMigrateCommand 执行数据库迁移
+func newMigrateCommand() *cobra.Command {
+	var folder string
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "执行数据库迁移（AutoMigrate + SQL 脚本）",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, cleanup, err := bootstrapAdmin()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			if err := system.WithMigrationLock(migrationLockOptions(cfg), func() error {
+				return system.RunMigrations(folder)
+			}); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "迁移执行完成")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&folder, "dir", "migrations", "SQL 迁移脚本目录")
+	return cmd
+}
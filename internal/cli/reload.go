@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gosir/config"
+	"gosir/internal/common"
+	"gosir/internal/cron"
+	"gosir/internal/middleware"
+	"gosir/internal/ratelimit"
+	"gosir/internal/service/system"
+)
+
+// watchConfig 监听配置文件，变更时将支持运行时生效的配置应用到各子系统
+func watchConfig(cfg config.Config) (*config.Watcher, error) {
+	watcher := config.NewWatcher(configPath, cfg)
+	watcher.Subscribe("log", applyLogConfig)
+	watcher.Subscribe("jwt", applyJWTConfig)
+	watcher.Subscribe("cron", applyCronConfig)
+	watcher.Subscribe("http", applyHTTPConfig)
+	watcher.Subscribe("rateLimit", applyRateLimitConfig)
+	watcher.Subscribe("audit", applyAuditConfig)
+
+	if err := watcher.Start(); err != nil {
+		return nil, err
+	}
+	return watcher, nil
+}
+
+// applyLogConfig 应用日志级别和 SQL 日志级别（同时取消通过管理接口设置的临时级别）
+func applyLogConfig(old, new config.Config) error {
+	change := system.LogLevelChange{Modules: make(map[string]string)}
+	if new.Log.Level != old.Log.Level {
+		change.Root = new.Log.Level
+	}
+	if new.Database.LogLevel != old.Database.LogLevel {
+		change.GORM = new.Database.LogLevel
+	}
+	for name, level := range new.Log.Modules {
+		if old.Log.Modules[name] != level {
+			change.Modules[name] = level
+		}
+	}
+	for name := range old.Log.Modules {
+		if _, ok := new.Log.Modules[name]; !ok {
+			change.Modules[name] = system.LogLevelInherit
+		}
+	}
+
+	if change.Root == "" && change.GORM == "" && len(change.Modules) == 0 {
+		return nil
+	}
+	_, err := system.SetLogLevels(change)
+	return err
+}
+
+// applyJWTConfig 应用 token 有效期（只影响新签发的 token）
+func applyJWTConfig(old, new config.Config) error {
+	if new.JWT.ExpireHours == old.JWT.ExpireHours {
+		return nil
+	}
+	jwtManager := common.GetJWTManager()
+	if jwtManager == nil {
+		return fmt.Errorf("JWT manager not initialized")
+	}
+	jwtManager.SetExpiration(time.Duration(new.JWT.ExpireHours) * time.Hour)
+	return nil
+}
+
+// applyCronConfig 应用定时任务执行时间，从配置中删除的任务恢复默认时间
+func applyCronConfig(old, new config.Config) error {
+	var errs []error
+	for name, spec := range new.Cron.Jobs {
+		if old.Cron.Jobs[name] != spec {
+			errs = append(errs, cron.Reschedule(name, spec))
+		}
+	}
+	for name := range old.Cron.Jobs {
+		if _, ok := new.Cron.Jobs[name]; !ok {
+			errs = append(errs, cron.Reschedule(name, ""))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// applyHTTPConfig 应用跨域配置
+func applyHTTPConfig(old, new config.Config) error {
+	if reflect.DeepEqual(new.Server.HTTP.CORS, old.Server.HTTP.CORS) {
+		return nil
+	}
+	middleware.SetCORSOptions(corsOptions(new))
+	return nil
+}
+
+// applyRateLimitConfig 应用限流规则，已有的计数保留
+func applyRateLimitConfig(old, new config.Config) error {
+	if reflect.DeepEqual(new.RateLimit.Groups, old.RateLimit.Groups) {
+		return nil
+	}
+	ratelimit.SetRules(rateLimitRules(new))
+	return nil
+}
+
+// applyAuditConfig 应用审计日志保留天数（下次清理任务生效）
+func applyAuditConfig(old, new config.Config) error {
+	if new.Audit.RetentionDays != old.Audit.RetentionDays {
+		initAudit(new)
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gosir/config"
+	"gosir/internal/clock"
+	"gosir/internal/common"
+	"gosir/internal/database"
+	"gosir/internal/logger"
+	"gosir/internal/notify"
+	"gosir/internal/oidc"
+	"gosir/internal/ratelimit"
+	"gosir/internal/repository"
+	"gosir/internal/service/audit"
+	"gosir/internal/service/auth"
+	"gosir/internal/service/oauth"
+
+	"github.com/spf13/cobra"
+)
+
+// configPath 配置文件路径（全局参数 --config）
+var configPath string
+
+// Execute 执行命令行入口
+func Execute() {
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// newRootCommand 创建根命令，未指定子命令时默认启动 HTTP 服务
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:          "gosir",
+		Short:        "Gosir REST API 服务及运维工具",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe()
+		},
+	}
+
+	root.PersistentFlags().StringVarP(&configPath, "config", "c", "config/config.yaml", "配置文件路径")
+
+	root.AddCommand(
+		newServeCommand(),
+		newMigrateCommand(),
+		newUserCommand(),
+		newTokenCommand(),
+		newConfigCommand(),
+		newCronCommand(),
+	)
+
+	return root
+}
+
+// loadConfig 加载配置文件
+// 同时按 server.mode 设置运行模式，test 模式下使用可控的测试时钟（随真实时间走动，可通过 /test/clock 调整）
+func loadConfig() (config.Config, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return config.Config{}, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	common.SetMode(cfg.Server.Mode)
+	common.SetErrorDetail(cfg.Server.ErrorDetail)
+	if cfg.Server.Mode == common.ModeTest {
+		clock.Set(clock.NewRunningFake(time.Now()))
+	}
+	return cfg, nil
+}
+
+// initLogger 初始化日志系统
+// 运维命令关闭控制台输出，日志只写入文件
+func initLogger(cfg config.Config, console bool) error {
+	// 初始化日志系统（日志目录由 logger 按输出自动创建）
+	if err := logger.InitWithConfig(&logger.LogConfig{
+		Path:           cfg.Log.Path,
+		Level:          cfg.Log.Level,
+		Format:         cfg.Log.Format,
+		DisableConsole: !console,
+		Rotate: logger.RotateConfig{
+			MaxSize:    cfg.Log.MaxSize,
+			MaxBackups: cfg.Log.MaxBackups,
+			MaxAge:     cfg.Log.MaxAge,
+			Compress:   cfg.Log.Compress,
+			Daily:      cfg.Log.Daily,
+			LocalTime:  cfg.Log.LocalTime,
+		},
+		Outputs: logOutputs(cfg.Log.Outputs),
+		Modules: cfg.Log.Modules,
+		Sampling: logger.SamplingConfig{
+			Enabled:    cfg.Log.Sampling.Enabled,
+			Tick:       time.Duration(cfg.Log.Sampling.Tick) * time.Second,
+			Initial:    cfg.Log.Sampling.Initial,
+			Thereafter: cfg.Log.Sampling.Thereafter,
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to init logger: %w", err)
+	}
+	return nil
+}
+
+// initDB 初始化数据库
+// test 模式使用内存数据库；release 模式下 SQL 日志最多到 warn 级别（错误和慢查询），且不输出参数值
+func initDB(cfg config.Config) error {
+	opts := database.Options{
+		DSN:      cfg.Database.Path,
+		LogLevel: cfg.Database.LogLevel,
+		Logger:   logger.Named(logger.ModuleSQL),
+	}
+
+	switch cfg.Server.Mode {
+	case common.ModeTest:
+		opts.DSN = database.MemoryDSN
+	case common.ModeRelease:
+		opts.HideParams = true
+		if opts.LogLevel == "info" {
+			logger.Warn("SQL query logging is limited to warn level in release mode")
+			opts.LogLevel = "warn"
+		}
+	}
+
+	return database.InitDB(opts)
+}
+
+// logOutputs 转换日志输出配置
+func logOutputs(outputs []config.LogOutputConfig) []logger.OutputConfig {
+	result := make([]logger.OutputConfig, 0, len(outputs))
+	for _, out := range outputs {
+		result = append(result, logger.OutputConfig{
+			Type:    out.Type,
+			Format:  out.Format,
+			Level:   out.Level,
+			Path:    out.Path,
+			Network: out.Network,
+			Address: out.Address,
+			Tag:     out.Tag,
+		})
+	}
+	return result
+}
+
+// initJWT 初始化 JWT 管理器，黑名单持久化到数据库以便多实例和命令行共享
+func initJWT(cfg config.Config) {
+	common.InitJWT(cfg.JWT.Secret, cfg.JWT.ExpireHours)
+	common.GetJWTManager().SetBlacklistStore(repository.NewTokenBlacklistRepository())
+}
+
+// initRateLimit 初始化限流器
+func initRateLimit(cfg config.Config) {
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimit.Store == "database" {
+		store = repository.NewRateLimitRepository()
+	}
+	ratelimit.Init(store, rateLimitRules(cfg))
+}
+
+// initOIDC 注册 OIDC 登录提供方，提供方元数据在首次登录时获取
+func initOIDC(cfg config.Config) {
+	configs := make(map[string]oidc.Config, len(cfg.OIDC.Providers))
+	for name, provider := range cfg.OIDC.Providers {
+		configs[name] = oidc.Config{
+			Issuer:         provider.Issuer,
+			ClientID:       provider.ClientID,
+			ClientSecret:   provider.ClientSecret,
+			RedirectURL:    provider.RedirectURL,
+			Scopes:         provider.Scopes,
+			AutoCreate:     provider.AutoCreate,
+			LinkByEmail:    provider.LinkByEmail,
+			AllowedDomains: provider.AllowedDomains,
+		}
+	}
+	oidc.Init(configs)
+}
+
+// initOAuth 开启 OAuth 授权服务，未配置签名密钥时生成临时密钥（重启后已签发的 ID Token 无法校验）
+func initOAuth(cfg config.Config) error {
+	if !cfg.OAuth.Enabled {
+		return nil
+	}
+
+	var key *oauth.SigningKey
+	var err error
+	if cfg.OAuth.SigningKeyFile != "" {
+		key, err = oauth.LoadSigningKey(cfg.OAuth.SigningKeyFile)
+	} else {
+		logger.Warn("oauth.signingKeyFile not set, using a generated signing key that changes on restart")
+		key, err = oauth.GenerateSigningKey()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load oauth signing key: %w", err)
+	}
+
+	oauth.Init(oauth.Options{
+		Issuer:          cfg.OAuth.Issuer,
+		SigningKey:      key,
+		CodeTTL:         time.Duration(cfg.OAuth.CodeTTL) * time.Second,
+		AccessTokenTTL:  time.Duration(cfg.OAuth.AccessTokenTTL) * time.Second,
+		RefreshTokenTTL: time.Duration(cfg.OAuth.RefreshTokenTTL) * time.Second,
+		IDTokenTTL:      time.Duration(cfg.OAuth.IDTokenTTL) * time.Second,
+		SessionTTL:      time.Duration(cfg.OAuth.SessionTTL) * time.Second,
+	})
+	return nil
+}
+
+// initNotify 初始化通知发送方式（验证码、登录链接等）
+func initNotify(cfg config.Config) error {
+	switch cfg.Notify.Transport {
+	case "file":
+		notify.Init(notify.NewFileNotifier(cfg.Notify.FilePath))
+	case "smtp":
+		smtp := cfg.Notify.SMTP
+		n, err := notify.NewSMTPNotifier(notify.SMTPOptions{
+			Host:     smtp.Host,
+			Port:     smtp.Port,
+			Username: smtp.Username,
+			Password: smtp.Password,
+			From:     smtp.From,
+			TLS:      smtp.TLS,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to init smtp notifier: %w", err)
+		}
+		notify.Init(n)
+	default:
+		notify.Init(notify.NewLogNotifier())
+	}
+	return nil
+}
+
+// initPasswordless 开启免密登录
+func initPasswordless(cfg config.Config) {
+	if !cfg.Passwordless.Enabled {
+		return
+	}
+	auth.InitPasswordless(auth.PasswordlessOptions{
+		CodeTTL:     time.Duration(cfg.Passwordless.CodeTTL) * time.Second,
+		CodeLength:  cfg.Passwordless.CodeLength,
+		MaxAttempts: cfg.Passwordless.MaxAttempts,
+		SendLimit:   cfg.Passwordless.SendLimit,
+		SendPeriod:  time.Duration(cfg.Passwordless.SendPeriod) * time.Second,
+		LinkURL:     cfg.Passwordless.LinkURL,
+	})
+}
+
+// initRegister 开启自助注册
+func initRegister(cfg config.Config) {
+	if !cfg.Register.Enabled {
+		return
+	}
+	auth.InitRegister(auth.RegisterOptions{
+		VerifyURL:  cfg.Register.VerifyURL,
+		VerifyTTL:  time.Duration(cfg.Register.VerifyTTL) * time.Second,
+		SendLimit:  cfg.Register.SendLimit,
+		SendPeriod: time.Duration(cfg.Register.SendPeriod) * time.Second,
+	})
+}
+
+// initAudit 设置审计日志保留时长
+func initAudit(cfg config.Config) {
+	audit.Init(time.Duration(cfg.Audit.RetentionDays) * 24 * time.Hour)
+}
+
+// bootstrapAdmin 运维命令的通用初始化：加载配置、日志、数据库、JWT、限流器，以及清理任务需要的自助注册和审计日志选项
+// 返回的清理函数负责关闭数据库并刷新日志
+func bootstrapAdmin() (config.Config, func(), error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return config.Config{}, nil, err
+	}
+
+	if err := initLogger(cfg, false); err != nil {
+		return config.Config{}, nil, err
+	}
+
+	if err := initDB(cfg); err != nil {
+		logger.Sync()
+		return config.Config{}, nil, fmt.Errorf("failed to connect database: %w", err)
+	}
+
+	initJWT(cfg)
+	initRateLimit(cfg)
+	initRegister(cfg)
+	initAudit(cfg)
+
+	cleanup := func() {
+		if err := database.CloseDB(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to close database: %v\n", err)
+		}
+		logger.Sync()
+	}
+	return cfg, cleanup, nil
+}
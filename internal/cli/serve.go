@@ -0,0 +1,375 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"gosir/config"
+	"gosir/internal/common"
+	"gosir/internal/cron"
+	"gosir/internal/database"
+	"gosir/internal/handler"
+	"gosir/internal/health"
+	"gosir/internal/logger"
+	"gosir/internal/metrics"
+	"gosir/internal/middleware"
+	"gosir/internal/ratelimit"
+	"gosir/internal/service/apikey"
+	"gosir/internal/service/system"
+	"gosir/internal/tracing"
+
+	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// defaultShutdownTimeout 默认优雅关闭超时时间
+const defaultShutdownTimeout = 30 * time.Second
+
+// newServeCommand 启动 HTTP 服务
+func newServeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "启动 HTTP 服务（默认命令）",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe()
+		},
+	}
+}
+
+// runServe 初始化依赖并启动 HTTP 服务
+// 收到 SIGINT/SIGTERM 后按顺序关闭：HTTP 服务（等待进行中的请求）→ 定时任务 → 数据库 → 日志
+func runServe() (err error) {
+	// 加载配置
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	// release 模式下不在标准输出打印配置
+	if cfg.Server.Mode != common.ModeRelease {
+		cfg.PrintConfig()
+	}
+
+	// 初始化日志系统
+	if err := initLogger(cfg, true); err != nil {
+		return err
+	}
+	defer logger.Sync()
+	defer func() {
+		if err != nil {
+			logger.Error("Server exited with error", zap.Error(err))
+			return
+		}
+		logger.Info("Shutdown complete")
+	}()
+
+	logger.Info("Starting application...")
+
+	// 初始化链路追踪
+	shutdownTracing, err := tracing.Init(tracingConfig(cfg))
+	if err != nil {
+		return fmt.Errorf("failed to init tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("Failed to flush traces", zap.Error(err))
+		}
+	}()
+
+	// 初始化数据库
+	if err := initDB(cfg); err != nil {
+		return fmt.Errorf("failed to connect database: %w", err)
+	}
+	defer func() {
+		logger.Info("Closing database")
+		if err := database.CloseDB(); err != nil {
+			logger.Error("Failed to close database", zap.Error(err))
+			return
+		}
+		logger.Info("Database closed")
+	}()
+
+	// 执行数据库迁移（AutoMigrate + SQL 脚本）并初始化管理员账号
+	// 多实例同时启动时通过迁移锁串行执行
+	var adminResult *system.AdminInitResult
+	if err := system.WithMigrationLock(migrationLockOptions(cfg), func() error {
+		if err := system.RunMigrations("migrations"); err != nil {
+			return err
+		}
+		if adminResult, err = system.InitAdminUser(adminOptions(cfg)); err != nil {
+			return fmt.Errorf("failed to init admin user: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+	printGeneratedAdminPassword(adminResult)
+
+	// release 模式下禁止使用已知的默认管理员密码
+	if cfg.Server.Mode == common.ModeRelease {
+		if err := system.CheckDefaultAdminPassword(cfg.Admin.Email); err != nil {
+			return fmt.Errorf("refusing to start in release mode (admin %s): %w", cfg.Admin.Email, err)
+		}
+	}
+
+	// 加载 HTTPS 证书
+	tlsManager, err := newTLSManager(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to init TLS: %w", err)
+	}
+
+	// 创建 Echo 实例
+	e := echo.New()
+	e.HideBanner = true
+	e.Debug = cfg.Server.Mode == common.ModeDebug
+	configureHTTPServer(e, cfg)
+
+	// 初始化 JWT
+	initJWT(cfg)
+
+	// 初始化限流器
+	initRateLimit(cfg)
+
+	// 注册 OIDC 登录提供方
+	initOIDC(cfg)
+
+	// 开启 OAuth 授权服务
+	if err := initOAuth(cfg); err != nil {
+		return err
+	}
+
+	// 初始化通知发送方式，开启免密登录和自助注册
+	if err := initNotify(cfg); err != nil {
+		return err
+	}
+	initPasswordless(cfg)
+	initRegister(cfg)
+
+	// 设置审计日志保留时长
+	initAudit(cfg)
+
+	// 初始化定时任务
+	cron.Init(cfg.Cron.Jobs)
+
+	// 注册就绪检查项
+	system.RegisterHealthChecks("migrations")
+
+	// 注册 JWT 黑名单大小指标（采集时计算）
+	metrics.NewGaugeFunc("gosir_jwt_blacklist_size", "Number of entries in the local JWT blacklist cache.", func() float64 {
+		return float64(common.GetJWTManager().GetBlacklistSize())
+	})
+
+	// 设置统一错误处理
+	e.HTTPErrorHandler = middleware.ErrorHandler()
+
+	// 全局中间件
+	e.Use(middleware.RequestIDMiddleware())
+	e.Use(middleware.AuditContextMiddleware())
+	e.Use(middleware.TracingMiddleware())
+	e.Use(middleware.MetricsMiddleware())
+	e.Use(middleware.ZapLoggerMiddleware())
+	e.Use(echoMiddleware.Recover())
+	e.Use(httpMiddlewares(cfg)...)
+
+	// 指标接口需要 Basic 认证，未设置密码时不提供
+	var metricsAuth echo.MiddlewareFunc
+	if cfg.Metrics.Password != "" {
+		metricsAuth = middleware.MetricsAuthMiddleware(cfg.Metrics.Username, cfg.Metrics.Password)
+	} else {
+		logger.Info("Metrics endpoint disabled, set metrics.password to enable it")
+	}
+
+	// 公开路由（无需鉴权）
+	handler.SetupPublicRoutes(e, handler.RouteOptions{
+		Swagger:     cfg.Server.SwaggerEnabled(),
+		TestClock:   cfg.Server.Mode == common.ModeTest,
+		MetricsAuth: metricsAuth,
+		AuthMiddlewares: []echo.MiddlewareFunc{
+			middleware.RateLimitMiddleware(ratelimit.GroupAuth),
+		},
+	})
+
+	// 受保护路由组（需要鉴权）
+	protected := e.Group("/api", middleware.AuthMiddleware(), middleware.RateLimitMiddleware(ratelimit.GroupAPI))
+	handler.SetupRoutes(protected)
+
+	// 管理员路由组
+	admin := protected.Group("/admin",
+		middleware.AdminMiddleware(),
+		middleware.RequireScope(apikey.ScopeAdmin),
+		middleware.RateLimitMiddleware(ratelimit.GroupAdmin),
+	)
+	handler.SetupAdminRoutes(admin)
+
+	// 监听配置文件变更，支持运行时生效的配置无需重启
+	if _, err := watchConfig(cfg); err != nil {
+		logger.Warn("Config hot reload disabled", zap.Error(err))
+	}
+
+	// 启动服务
+	addr := ":" + strconv.Itoa(cfg.Server.Port)
+	logger.Info("Server starting",
+		zap.String("addr", addr),
+		zap.String("mode", cfg.Server.Mode),
+		zap.Bool("tls", tlsManager != nil),
+	)
+
+	serverErr := make(chan error, 2)
+	go func() {
+		if err := startServer(e, addr, tlsManager); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	// HTTP 重定向到 HTTPS
+	redirect := newRedirectServer(cfg)
+	if redirect != nil {
+		logger.Info("HTTP to HTTPS redirect starting", zap.String("addr", redirect.Addr))
+		go func() {
+			if err := redirect.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serverErr <- fmt.Errorf("redirect server: %w", err)
+			}
+		}()
+	}
+
+	// SIGHUP 重新打开日志文件（配合 logrotate 等外部工具）并重新加载 HTTPS 证书（配合证书自动续期）
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	go func() {
+		for range hup {
+			if err := logger.Reopen(); err != nil {
+				logger.Error("Failed to reopen log file", zap.Error(err))
+			} else {
+				logger.Info("Log file reopened")
+			}
+
+			if tlsManager != nil {
+				if err := tlsManager.Reload(); err != nil {
+					logger.Error("Failed to reload TLS certificate, keeping the current one", zap.Error(err))
+				} else {
+					logger.Info("TLS certificate reloaded")
+				}
+			}
+		}
+	}()
+
+	// 等待退出信号或服务异常退出
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	var runErr error
+	select {
+	case sig := <-quit:
+		logger.Info("Shutdown signal received", zap.String("signal", sig.String()))
+	case runErr = <-serverErr:
+		logger.Error("Server stopped unexpectedly", zap.Error(runErr))
+	}
+	// 恢复默认信号处理，再次发送信号可强制退出
+	signal.Stop(quit)
+
+	shutdown(e, redirect, shutdownTimeout(cfg))
+
+	if runErr != nil {
+		return fmt.Errorf("failed to start server: %w", runErr)
+	}
+	return nil
+}
+
+// shutdown 优雅关闭 HTTP 服务（包括 HTTPS 重定向服务）和定时任务，数据库和日志由 runServe 的 defer 依次关闭
+func shutdown(e *echo.Echo, redirect *http.Server, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// 0. 就绪探针立即返回 503，负载均衡不再转发新请求
+	health.SetShuttingDown()
+
+	// 1. 停止接收新连接，等待进行中的请求完成
+	logger.Info("Shutting down HTTP server", zap.Duration("timeout", timeout))
+	if err := e.Shutdown(ctx); err != nil {
+		logger.Error("HTTP server shutdown did not complete", zap.Error(err))
+	} else {
+		logger.Info("HTTP server stopped")
+	}
+	if redirect != nil {
+		if err := redirect.Shutdown(ctx); err != nil {
+			logger.Error("Redirect server shutdown did not complete", zap.Error(err))
+		}
+	}
+
+	// 2. 停止调度定时任务，等待正在运行的任务完成
+	logger.Info("Waiting for running cron jobs")
+	select {
+	case <-cron.Stop().Done():
+		logger.Info("Cron jobs finished")
+	case <-ctx.Done():
+		logger.Warn("Timed out waiting for cron jobs", zap.Error(ctx.Err()))
+	}
+}
+
+// shutdownTimeout 获取优雅关闭超时时间
+func shutdownTimeout(cfg config.Config) time.Duration {
+	if cfg.Server.ShutdownTimeout <= 0 {
+		return defaultShutdownTimeout
+	}
+	return time.Duration(cfg.Server.ShutdownTimeout) * time.Second
+}
+
+// migrationLockOptions 从配置构建迁移锁选项
+func migrationLockOptions(cfg config.Config) system.MigrationLockOptions {
+	return system.MigrationLockOptions{
+		Timeout: time.Duration(cfg.Database.MigrationLockTimeout) * time.Second,
+		TTL:     time.Duration(cfg.Database.MigrationLockTTL) * time.Second,
+	}
+}
+
+// tracingConfig 从配置构建链路追踪选项
+func tracingConfig(cfg config.Config) tracing.Config {
+	return tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		FilePath:    cfg.Tracing.FilePath,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		ServiceName: cfg.Tracing.ServiceName,
+	}
+}
+
+// adminOptions 从配置构建初始管理员选项
+func adminOptions(cfg config.Config) system.AdminOptions {
+	return system.AdminOptions{
+		Name:     cfg.Admin.Name,
+		Email:    cfg.Admin.Email,
+		Phone:    cfg.Admin.Phone,
+		Password: cfg.Admin.Password,
+	}
+}
+
+// printGeneratedAdminPassword 打印自动生成的管理员密码
+// 只输出到控制台，不写入日志文件；该密码仅在首次创建时展示一次
+func printGeneratedAdminPassword(result *system.AdminInitResult) {
+	if result == nil || result.GeneratedPassword == "" {
+		return
+	}
+
+	fmt.Println("========================================")
+	fmt.Println("       Admin Account Created")
+	fmt.Println("========================================")
+	fmt.Printf("  Email:    %s\n", result.Email)
+	fmt.Printf("  Password: %s\n", result.GeneratedPassword)
+	fmt.Println()
+	fmt.Println("  This password is shown only once.")
+	fmt.Println("  You must change it on first login.")
+	fmt.Println("========================================")
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"gosir/internal/common"
+	"gosir/internal/service/user"
+
+	"github.com/spf13/cobra"
+)
+
+// newTokenCommand token 管理命令
+func newTokenCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "JWT token 管理",
+	}
+
+	cmd.AddCommand(
+		newTokenIssueCommand(),
+		newTokenRevokeCommand(),
+	)
+	return cmd
+}
+
+// newTokenIssueCommand 为用户签发 token
+func newTokenIssueCommand() *cobra.Command {
+	var expireHours int
+
+	cmd := &cobra.Command{
+		Use:   "issue <id|email|phone>",
+		Short: "为用户签发 token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, cleanup, err := bootstrapAdmin()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			target, err := findUser(user.NewUserService(), args[0])
+			if err != nil {
+				return err
+			}
+
+			jwtManager := common.GetJWTManager()
+			if expireHours > 0 && expireHours != cfg.JWT.ExpireHours {
+				jwtManager = common.NewJWTManager(cfg.JWT.Secret, time.Duration(expireHours)*time.Hour)
+			}
+
+			token, err := jwtManager.GenerateToken(target.ID)
+			if err != nil {
+				return fmt.Errorf("failed to generate token: %w", err)
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), token)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&expireHours, "expire-hours", 0, "过期时间（小时），默认使用配置中的 jwt.expireHours")
+	return cmd
+}
+
+// newTokenRevokeCommand 吊销 token
+func newTokenRevokeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke <token>",
+		Short: "吊销 token（加入黑名单，对所有共享数据库的实例生效）",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, cleanup, err := bootstrapAdmin()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			jwtManager := common.GetJWTManager()
+			claims, err := jwtManager.ValidateToken(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid token: %w", err)
+			}
+
+			if err := jwtManager.AddToBlacklist(claims.JTI, claims.ExpiresAt.Time); err != nil {
+				return fmt.Errorf("failed to revoke token: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "token 已吊销: jti=%s user=%s\n", claims.JTI, claims.UserID)
+			return nil
+		},
+	}
+}
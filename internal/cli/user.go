@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	usermodel "gosir/internal/model/user"
+	"gosir/internal/service/user"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/cobra"
+)
+
+// generatedPasswordLength 自动生成密码的长度
+const generatedPasswordLength = 16
+
+// newUserCommand 用户管理命令
+func newUserCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "user",
+		Short: "用户管理",
+	}
+
+	cmd.AddCommand(
+		newUserCreateCommand(),
+		newUserListCommand(),
+		newUserDisableCommand(),
+		newUserResetPasswordCommand(),
+	)
+	return cmd
+}
+
+// newUserCreateCommand 创建用户
+func newUserCreateCommand() *cobra.Command {
+	var req user.CreateUserRequest
+	var status int
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "创建用户（未指定密码时自动生成）",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, cleanup, err := bootstrapAdmin()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			generated := false
+			if req.Password == "" {
+				if req.Password, err = user.GenerateRandomPassword(generatedPasswordLength); err != nil {
+					return fmt.Errorf("failed to generate password: %w", err)
+				}
+				generated = true
+			}
+			if cmd.Flags().Changed("status") {
+				req.Status = &status
+			}
+
+			if err := validator.New().Struct(&req); err != nil {
+				return fmt.Errorf("invalid user: %w", err)
+			}
+
+			newUser, err := user.NewUserService().CreateUser(&req)
+			if err != nil {
+				return fmt.Errorf("failed to create user: %w", err)
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "用户已创建: %s (%s)\n", newUser.ID, newUser.Email)
+			if generated {
+				fmt.Fprintf(out, "初始密码: %s\n", req.Password)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&req.Name, "name", "", "姓名")
+	cmd.Flags().StringVar(&req.Email, "email", "", "邮箱")
+	cmd.Flags().StringVar(&req.Password, "password", "", "密码（留空则自动生成）")
+	cmd.Flags().StringVar(&req.Phone, "phone", "", "手机号")
+	cmd.Flags().IntVar(&status, "status", int(usermodel.UserStatusNormal), "状态：1-正常 2-禁用")
+	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.MarkFlagRequired("email")
+	return cmd
+}
+
+// newUserListCommand 列出用户
+func newUserListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "列出所有用户",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, cleanup, err := bootstrapAdmin()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			users, err := user.NewUserService().GetAllUsers()
+			if err != nil {
+				return fmt.Errorf("failed to list users: %w", err)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tNAME\tEMAIL\tPHONE\tSTATUS\tLAST_LOGIN")
+			for _, u := range users {
+				lastLogin := "-"
+				if u.LastLogin != nil {
+					lastLogin = u.LastLogin.Format("2006-01-02 15:04:05")
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+					u.ID, u.Name, u.Email, u.Phone, usermodel.UserStatus(u.Status), lastLogin)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// newUserDisableCommand 禁用用户
+func newUserDisableCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "disable <id|email|phone>",
+		Short: "禁用用户",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, cleanup, err := bootstrapAdmin()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			userService := user.NewUserService()
+			target, err := findUser(userService, args[0])
+			if err != nil {
+				return err
+			}
+
+			if _, err := userService.DisableUser(target.ID); err != nil {
+				return fmt.Errorf("failed to disable user: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "用户已禁用，已签发的 token 已失效: %s (%s)\n", target.ID, target.Email)
+			return nil
+		},
+	}
+}
+
+// newUserResetPasswordCommand 重置用户密码
+func newUserResetPasswordCommand() *cobra.Command {
+	var password string
+
+	cmd := &cobra.Command{
+		Use:   "reset-password <id|email|phone>",
+		Short: "重置用户密码（未指定密码时自动生成）",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, cleanup, err := bootstrapAdmin()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			userService := user.NewUserService()
+			target, err := findUser(userService, args[0])
+			if err != nil {
+				return err
+			}
+
+			generated := false
+			if password == "" {
+				if password, err = user.GenerateRandomPassword(generatedPasswordLength); err != nil {
+					return fmt.Errorf("failed to generate password: %w", err)
+				}
+				generated = true
+			} else if len(password) < 6 {
+				return fmt.Errorf("password must be at least 6 characters")
+			}
+
+			if _, err := userService.ResetPassword(target.ID, password); err != nil {
+				return fmt.Errorf("failed to reset password: %w", err)
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "密码已重置: %s (%s)\n", target.ID, target.Email)
+			if generated {
+				fmt.Fprintf(out, "新密码: %s\n", password)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&password, "password", "", "新密码（留空则自动生成）")
+	return cmd
+}
+
+// findUser 通过 ID、邮箱或手机号查找用户
+func findUser(userService *user.UserService, key string) (*usermodel.User, error) {
+	if u, err := userService.GetUserByID(key); err == nil {
+		return u, nil
+	}
+	u, err := userService.GetUserByEmailOrPhone(key)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %s", key)
+	}
+	return u, nil
+}
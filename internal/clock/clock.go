@@ -0,0 +1,98 @@
+package clock
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock 时钟，业务代码通过 clock.Now() 获取当前时间，test 模式下可替换为可控的 Fake 时钟
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock 系统时钟
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// holder 包装时钟，atomic.Value 要求每次存入的具体类型一致
+type holder struct {
+	clock Clock
+}
+
+// current 当前使用的时钟
+var current atomic.Value
+
+func init() {
+	Reset()
+}
+
+// Now 获取当前时间
+func Now() time.Time {
+	return Current().Now()
+}
+
+// Set 替换全局时钟
+func Set(c Clock) {
+	current.Store(holder{clock: c})
+}
+
+// Reset 恢复使用系统时钟
+func Reset() {
+	Set(realClock{})
+}
+
+// Current 获取当前使用的时钟
+func Current() Clock {
+	return current.Load().(holder).clock
+}
+
+// Fake 可控时钟，NewFake 创建的时钟只在调用 Set/Advance 时变化，NewRunningFake 创建的时钟同时随真实时间走动
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	since   time.Time // 最近一次设置时的真实时间
+	running bool      // 是否随真实时间走动
+}
+
+// NewFake 创建固定在 now 的可控时钟
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now, since: time.Now()}
+}
+
+// NewRunningFake 创建从 now 开始随真实时间走动的可控时钟，Set/Advance 调整的时间偏移会一直保留
+func NewRunningFake(now time.Time) *Fake {
+	return &Fake{now: now, since: time.Now(), running: true}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.current()
+}
+
+// Set 设置当前时间
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now, f.since = now, time.Now()
+}
+
+// Advance 将时间向前推进 d
+func (f *Fake) Advance(d time.Duration) time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now, f.since = f.current().Add(d), time.Now()
+	return f.now
+}
+
+// current 当前时间，调用方需持有锁
+func (f *Fake) current() time.Time {
+	if !f.running {
+		return f.now
+	}
+	return f.now.Add(time.Since(f.since))
+}
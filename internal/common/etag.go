@@ -0,0 +1,49 @@
+package common
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 条件请求相关的 HTTP 头
+const (
+	HeaderETag    = "ETag"
+	HeaderIfMatch = "If-Match"
+)
+
+// AnyVersion If-Match: * 对应的版本号，表示资源存在即可，不检查版本
+const AnyVersion int64 = 0
+
+// VersionETag 由版本号生成强 ETag，如 "3"
+func VersionETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// SetVersionETag 设置响应的 ETag 头
+func SetVersionETag(c echo.Context, version int64) {
+	c.Response().Header().Set(HeaderETag, VersionETag(version))
+}
+
+// ParseVersionETag 解析 If-Match 请求头中的版本号
+// 支持单个强 ETag（VersionETag 的格式）和 *（返回 AnyVersion），弱 ETag 和多个 ETag 返回 false
+func ParseVersionETag(value string) (int64, bool) {
+	value = strings.TrimSpace(value)
+	if value == "*" {
+		return AnyVersion, true
+	}
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(value[1:len(value)-1], 10, 64)
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
+
+// IsWeakETag 是否为弱 ETag（W/ 开头），If-Match 使用强比较，弱 ETag 不能匹配
+func IsWeakETag(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), "W/")
+}
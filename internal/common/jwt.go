@@ -1,19 +1,53 @@
 package common
 
 import (
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"gosir/internal/clock"
+
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
+// ErrTokenBlacklistUnavailable 黑名单持久化存储不可用，无法确认 token 是否已被吊销
+var ErrTokenBlacklistUnavailable = errors.New("token blacklist unavailable")
+
 // JWTClaims JWT 声明
 type JWTClaims struct {
+	UserID             string `json:"user_id"`
+	JTI                string `json:"jti"`                            // JWT ID，用于标识唯一 token
+	MustChangePassword bool   `json:"must_change_password,omitempty"` // 受限 token，只能用于修改密码
+	ClientID           string `json:"client_id,omitempty"`            // OAuth 访问令牌的客户端，为空表示用户本人登录的 token
+	Scope              string `json:"scope,omitempty"`                // OAuth 访问令牌的权限范围（空格分隔）
+	jwt.RegisteredClaims
+}
+
+// IsOAuth 是否为签发给 OAuth 客户端的访问令牌
+func (c *JWTClaims) IsOAuth() bool {
+	return c.ClientID != ""
+}
+
+// Scopes OAuth 访问令牌的权限范围
+func (c *JWTClaims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// VerificationClaims 邮箱验证链接声明
+type VerificationClaims struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"` // 注册时的邮箱，邮箱修改后链接失效
+	jwt.RegisteredClaims
+}
+
+// OAuthSessionClaims OAuth 授权页面登录会话声明
+type OAuthSessionClaims struct {
 	UserID string `json:"user_id"`
-	JTI    string `json:"jti"` // JWT ID，用于标识唯一 token
 	jwt.RegisteredClaims
 }
 
@@ -24,32 +58,92 @@ type TokenBlacklistEntry struct {
 	Blacklisted time.Time // 加入黑名单的时间
 }
 
+// BlacklistStore token 黑名单持久化存储
+// 本地缓存只在当前进程内生效，配置存储后黑名单可以在多个实例和命令行之间共享
+type BlacklistStore interface {
+	// Add 将 token 加入黑名单
+	Add(jti string, expiredAt time.Time) error
+	// Exists 检查 token 是否在黑名单中，返回 token 原始过期时间
+	Exists(jti string) (bool, time.Time, error)
+	// DeleteExpired 删除已过期的记录，返回删除数量
+	DeleteExpired() (int64, error)
+}
+
 // JWTManager JWT 管理器
 type JWTManager struct {
 	secretKey  string
-	expiration time.Duration
-	issuer     string    // 签发者
-	blacklist  *sync.Map // token 黑名单 (本地缓存)
+	expiration atomic.Int64   // token 有效期，支持运行时修改（只影响新签发的 token）
+	issuer     string         // 签发者
+	blacklist  *sync.Map      // token 黑名单 (本地缓存)
+	store      BlacklistStore // token 黑名单持久化存储（可选）
 }
 
 // NewJWTManager 创建 JWT 管理器
 func NewJWTManager(secretKey string, expiration time.Duration) *JWTManager {
-	return &JWTManager{
-		secretKey:  secretKey,
-		expiration: expiration,
-		issuer:     "gosir",
-		blacklist:  &sync.Map{},
+	m := &JWTManager{
+		secretKey: secretKey,
+		issuer:    "gosir",
+		blacklist: &sync.Map{},
 	}
+	m.expiration.Store(int64(expiration))
+	return m
+}
+
+// SetExpiration 修改 token 有效期，已签发的 token 不受影响
+func (m *JWTManager) SetExpiration(expiration time.Duration) {
+	m.expiration.Store(int64(expiration))
+}
+
+// Expiration 获取 token 有效期
+func (m *JWTManager) Expiration() time.Duration {
+	return time.Duration(m.expiration.Load())
+}
+
+// SetBlacklistStore 设置黑名单持久化存储
+func (m *JWTManager) SetBlacklistStore(store BlacklistStore) {
+	m.store = store
 }
 
 // GenerateToken 生成 JWT token
 func (m *JWTManager) GenerateToken(userID string) (string, error) {
-	now := time.Now()
+	return m.generateToken(userID, false)
+}
+
+// GeneratePasswordChangeToken 生成受限 token，用户修改密码前只能访问修改密码接口
+func (m *JWTManager) GeneratePasswordChangeToken(userID string) (string, error) {
+	return m.generateToken(userID, true)
+}
+
+// generateToken 生成 JWT token
+func (m *JWTManager) generateToken(userID string, mustChangePassword bool) (string, error) {
+	now := clock.Now()
 	claims := JWTClaims{
-		UserID: userID,
-		JTI:    uuid.New().String(), // 生成唯一的 JTI
+		UserID:             userID,
+		JTI:                uuid.New().String(), // 生成唯一的 JTI
+		MustChangePassword: mustChangePassword,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.Expiration())),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    m.issuer,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(m.secretKey))
+}
+
+// GenerateAccessToken 为 OAuth 客户端签发访问令牌，userID 为空表示客户端凭证模式（不代表任何用户）
+func (m *JWTManager) GenerateAccessToken(userID, clientID string, scopes []string, ttl time.Duration) (string, error) {
+	now := clock.Now()
+	claims := JWTClaims{
+		UserID:   userID,
+		JTI:      uuid.New().String(),
+		ClientID: clientID,
+		Scope:    strings.Join(scopes, " "),
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(m.expiration)),
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    m.issuer,
@@ -60,6 +154,89 @@ func (m *JWTManager) GenerateToken(userID string) (string, error) {
 	return token.SignedString([]byte(m.secretKey))
 }
 
+// GenerateVerificationToken 签发邮箱验证链接 token
+// 使用由 JWT 密钥派生的独立密钥签名，不能当作登录 token 使用，登录 token 也不能用于验证邮箱
+func (m *JWTManager) GenerateVerificationToken(userID, email string, expiresAt time.Time) (string, error) {
+	now := clock.Now()
+	claims := VerificationClaims{
+		UserID: userID,
+		Email:  email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    m.issuer,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(m.verificationKey())
+}
+
+// ValidateVerificationToken 验证邮箱验证链接 token
+func (m *JWTManager) ValidateVerificationToken(tokenString string) (*VerificationClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &VerificationClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return m.verificationKey(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(m.issuer), jwt.WithTimeFunc(clock.Now))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*VerificationClaims)
+	if !ok || !token.Valid || claims.UserID == "" {
+		return nil, errors.New("无效的 token")
+	}
+	return claims, nil
+}
+
+// verificationKey 邮箱验证链接的签名密钥
+func (m *JWTManager) verificationKey() []byte {
+	return m.derivedKey("email verification")
+}
+
+// GenerateOAuthSessionToken 签发 OAuth 授权页面的登录会话 token
+// 使用由 JWT 密钥派生的独立密钥签名，只能用于授权页面，不能访问 /api 接口
+func (m *JWTManager) GenerateOAuthSessionToken(userID string, ttl time.Duration) (string, error) {
+	now := clock.Now()
+	claims := OAuthSessionClaims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    m.issuer,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(m.oauthSessionKey())
+}
+
+// ValidateOAuthSessionToken 验证 OAuth 授权页面的登录会话 token
+func (m *JWTManager) ValidateOAuthSessionToken(tokenString string) (*OAuthSessionClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &OAuthSessionClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return m.oauthSessionKey(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(m.issuer), jwt.WithTimeFunc(clock.Now))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*OAuthSessionClaims)
+	if !ok || !token.Valid || claims.UserID == "" || claims.IssuedAt == nil {
+		return nil, errors.New("无效的 token")
+	}
+	return claims, nil
+}
+
+// oauthSessionKey OAuth 授权页面登录会话的签名密钥
+func (m *JWTManager) oauthSessionKey() []byte {
+	return m.derivedKey("oauth session")
+}
+
+// derivedKey 由 JWT 密钥派生的专用签名密钥，不同用途的 token 不能互相冒用
+func (m *JWTManager) derivedKey(purpose string) []byte {
+	sum := sha256.Sum256([]byte("gosir " + purpose + ":" + m.secretKey))
+	return sum[:]
+}
+
 // ValidateToken 验证 JWT token
 func (m *JWTManager) ValidateToken(tokenString string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
@@ -67,7 +244,7 @@ func (m *JWTManager) ValidateToken(tokenString string) (*JWTClaims, error) {
 			return nil, fmt.Errorf("无效的签名方法: %v", token.Header["alg"])
 		}
 		return []byte(m.secretKey), nil
-	})
+	}, jwt.WithTimeFunc(clock.Now))
 
 	if err != nil {
 		return nil, err
@@ -79,7 +256,11 @@ func (m *JWTManager) ValidateToken(tokenString string) (*JWTClaims, error) {
 	}
 
 	// 检查 token 是否在黑名单中
-	if m.IsTokenBlacklisted(claims.JTI) {
+	revoked, err := m.IsTokenBlacklisted(claims.JTI)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
 		return nil, errors.New("token 已失效")
 	}
 
@@ -94,49 +275,86 @@ func (m *JWTManager) RefreshToken(tokenString string) (string, error) {
 	}
 
 	// 检查 token 是否在黑名单中
-	if m.IsTokenBlacklisted(claims.JTI) {
+	revoked, err := m.IsTokenBlacklisted(claims.JTI)
+	if err != nil {
+		return "", err
+	}
+	if revoked {
 		return "", errors.New("token 已失效")
 	}
 
-	// 直接刷新，不限制时间
-	return m.GenerateToken(claims.UserID)
+	// OAuth 访问令牌只能通过刷新令牌续期
+	if claims.IsOAuth() {
+		return "", errors.New("OAuth 访问令牌不能刷新")
+	}
+
+	// 直接刷新，不限制时间（受限 token 刷新后仍然受限）
+	return m.generateToken(claims.UserID, claims.MustChangePassword)
 }
 
 // AddToBlacklist 将 token 加入黑名单
-func (m *JWTManager) AddToBlacklist(jti string, expiredAt time.Time) {
+func (m *JWTManager) AddToBlacklist(jti string, expiredAt time.Time) error {
 	entry := TokenBlacklistEntry{
 		JTI:         jti,
 		ExpiredAt:   expiredAt,
-		Blacklisted: time.Now(),
+		Blacklisted: clock.Now(),
 	}
 	m.blacklist.Store(jti, entry)
+
+	if m.store != nil {
+		return m.store.Add(jti, expiredAt)
+	}
+	return nil
 }
 
 // IsTokenBlacklisted 检查 token 是否在黑名单中
-func (m *JWTManager) IsTokenBlacklisted(jti string) bool {
+// 持久化存储不可用时无法确认 token 是否已在其他实例或命令行吊销，按已吊销处理并返回 ErrTokenBlacklistUnavailable
+func (m *JWTManager) IsTokenBlacklisted(jti string) (bool, error) {
 	if value, ok := m.blacklist.Load(jti); ok {
 		entry := value.(TokenBlacklistEntry)
 
 		// 如果 token 已经过期，从黑名单中删除
-		if time.Now().After(entry.ExpiredAt) {
+		if clock.Now().After(entry.ExpiredAt) {
 			m.blacklist.Delete(jti)
-			return false
+			return false, nil
 		}
 
-		return true
+		return true, nil
+	}
+
+	// 本地缓存未命中时查询持久化存储（其他实例或命令行吊销的 token）
+	if m.store != nil {
+		exists, expiredAt, err := m.store.Exists(jti)
+		if err != nil {
+			return true, fmt.Errorf("%w: %v", ErrTokenBlacklistUnavailable, err)
+		}
+		if exists {
+			m.blacklist.Store(jti, TokenBlacklistEntry{
+				JTI:         jti,
+				ExpiredAt:   expiredAt,
+				Blacklisted: clock.Now(),
+			})
+			return true, nil
+		}
 	}
-	return false
+	return false, nil
 }
 
 // CleanupExpiredBlacklist 清理黑名单中已过期的 token
-func (m *JWTManager) CleanupExpiredBlacklist() {
+func (m *JWTManager) CleanupExpiredBlacklist() error {
 	m.blacklist.Range(func(key, value interface{}) bool {
 		entry := value.(TokenBlacklistEntry)
-		if time.Now().After(entry.ExpiredAt) {
+		if clock.Now().After(entry.ExpiredAt) {
 			m.blacklist.Delete(key)
 		}
 		return true
 	})
+
+	if m.store != nil {
+		_, err := m.store.DeleteExpired()
+		return err
+	}
+	return nil
 }
 
 // GetBlacklistSize 获取黑名单大小
@@ -170,3 +388,35 @@ func GenerateToken(userID string) (string, error) {
 	}
 	return jwtManager.GenerateToken(userID)
 }
+
+// GenerateAccessToken 使用全局 JWT 管理器签发 OAuth 访问令牌
+func GenerateAccessToken(userID, clientID string, scopes []string, ttl time.Duration) (string, error) {
+	if jwtManager == nil {
+		return "", errors.New("JWT 管理器未初始化")
+	}
+	return jwtManager.GenerateAccessToken(userID, clientID, scopes, ttl)
+}
+
+// GeneratePasswordChangeToken 使用全局 JWT 管理器生成受限 token
+func GeneratePasswordChangeToken(userID string) (string, error) {
+	if jwtManager == nil {
+		return "", errors.New("JWT 管理器未初始化")
+	}
+	return jwtManager.GeneratePasswordChangeToken(userID)
+}
+
+// GenerateVerificationToken 使用全局 JWT 管理器签发邮箱验证链接 token
+func GenerateVerificationToken(userID, email string, expiresAt time.Time) (string, error) {
+	if jwtManager == nil {
+		return "", errors.New("JWT 管理器未初始化")
+	}
+	return jwtManager.GenerateVerificationToken(userID, email, expiresAt)
+}
+
+// GenerateOAuthSessionToken 使用全局 JWT 管理器签发 OAuth 授权页面的登录会话 token
+func GenerateOAuthSessionToken(userID string, ttl time.Duration) (string, error) {
+	if jwtManager == nil {
+		return "", errors.New("JWT 管理器未初始化")
+	}
+	return jwtManager.GenerateOAuthSessionToken(userID, ttl)
+}
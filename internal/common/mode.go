@@ -0,0 +1,50 @@
+package common
+
+import "sync/atomic"
+
+// 运行模式（配置项 server.mode）
+const (
+	ModeDebug   = "debug"
+	ModeRelease = "release"
+	ModeTest    = "test"
+)
+
+// mode 当前运行模式
+var mode atomic.Value
+
+func init() {
+	mode.Store(ModeDebug)
+}
+
+// SetMode 设置运行模式（启动时调用）
+func SetMode(m string) {
+	mode.Store(m)
+}
+
+// Mode 获取运行模式
+func Mode() string {
+	return mode.Load().(string)
+}
+
+// IsRelease 是否为 release 模式
+func IsRelease() bool {
+	return Mode() == ModeRelease
+}
+
+// IsTest 是否为 test 模式
+func IsTest() bool {
+	return Mode() == ModeTest
+}
+
+// errorDetail 错误响应中是否包含错误详情（配置项 server.errorDetail）
+var errorDetail atomic.Bool
+
+// SetErrorDetail 设置错误响应中是否包含错误详情（启动时调用）
+func SetErrorDetail(enabled bool) {
+	errorDetail.Store(enabled)
+}
+
+// ErrorDetail 错误响应中是否包含错误详情，未开启时详情只写入日志
+func ErrorDetail() bool {
+	return errorDetail.Load()
+}
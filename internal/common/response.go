@@ -11,6 +11,8 @@ type Response struct {
 	Code    int         `json:"code" example:"0"`          // 业务状态码
 	Message string      `json:"message" example:"success"` // 响应消息
 	Data    interface{} `json:"data"`                      // 响应数据
+
+	RequestID string `json:"request_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"` // 请求 ID（仅错误响应返回，用于排查日志）
 }
 
 // Pagination 分页数据
@@ -23,24 +25,36 @@ type Pagination struct {
 
 // 常用业务状态码
 const (
-	CodeSuccess         = 0   // 成功
-	CodeBadRequest      = 400 // 请求参数错误
-	CodeUnauthorized    = 401 // 未授权
-	CodeForbidden       = 403 // 禁止访问
-	CodeNotFound        = 404 // 资源不存在
-	CodeInternalError   = 500 // 服务器内部错误
-	CodeValidationError = 422 // 参数验证错误
+	CodeSuccess              = 0   // 成功
+	CodeBadRequest           = 400 // 请求参数错误
+	CodeUnauthorized         = 401 // 未授权
+	CodeForbidden            = 403 // 禁止访问
+	CodeNotFound             = 404 // 资源不存在
+	CodeConflict             = 409 // 资源状态冲突
+	CodePreconditionFailed   = 412 // 资源已被修改（If-Match 不匹配）
+	CodePreconditionRequired = 428 // 缺少 If-Match 请求头
+	CodeUnsupportedMedia     = 415 // 不支持的请求体类型
+	CodeInternalError        = 500 // 服务器内部错误
+	CodeValidationError      = 422 // 参数验证错误
+	CodeRequestTooLarge      = 413 // 请求体过大
+	CodeTooManyRequests      = 429 // 请求过于频繁
 )
 
 // 响应消息映射
 var codeMessages = map[int]string{
-	CodeSuccess:         "success",
-	CodeBadRequest:      "请求参数错误",
-	CodeUnauthorized:    "未授权，请先登录",
-	CodeForbidden:       "禁止访问",
-	CodeNotFound:        "资源不存在",
-	CodeInternalError:   "服务器内部错误",
-	CodeValidationError: "参数验证失败",
+	CodeSuccess:              "success",
+	CodeBadRequest:           "请求参数错误",
+	CodeUnauthorized:         "未授权，请先登录",
+	CodeForbidden:            "禁止访问",
+	CodeNotFound:             "资源不存在",
+	CodeConflict:             "资源状态冲突",
+	CodePreconditionFailed:   "资源已被修改，请重新获取",
+	CodePreconditionRequired: "缺少 If-Match 请求头",
+	CodeUnsupportedMedia:     "不支持的请求体类型",
+	CodeInternalError:        "服务器内部错误",
+	CodeValidationError:      "参数验证失败",
+	CodeRequestTooLarge:      "请求体过大",
+	CodeTooManyRequests:      "请求过于频繁",
 }
 
 // Success 成功响应
@@ -74,16 +88,32 @@ func Created(c echo.Context, data interface{}) error {
 // Error 错误响应
 // @Failure      200 {object} Response
 func Error(c echo.Context, code int, message string) error {
+	return ErrorWithStatus(c, http.StatusOK, code, message)
+}
+
+// ErrorWithStatus 使用指定 HTTP 状态码的错误响应，用于客户端需要按状态码处理的场景（如限流返回 429）
+func ErrorWithStatus(c echo.Context, status, code int, message string) error {
 	if message == "" {
 		message = codeMessages[code]
 	}
-	return c.JSON(http.StatusOK, Response{
+	return c.JSON(status, Response{
 		Code:    code,
 		Message: message,
 		Data:    nil,
+
+		RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
 	})
 }
 
+// ErrorWithDetail 错误响应，开启 server.errorDetail 时在消息后附加错误详情便于调试
+// release 模式下不向客户端暴露内部错误信息
+func ErrorWithDetail(c echo.Context, code int, message string, err error) error {
+	if err != nil && ErrorDetail() {
+		message += ": " + err.Error()
+	}
+	return Error(c, code, message)
+}
+
 // Paginate 分页响应
 func Paginate(c echo.Context, page, pageSize int, total int64, items interface{}) error {
 	return c.JSON(http.StatusOK, Response{
@@ -1,70 +1,230 @@
 package cron
 
 import (
+	"context"
 	"fmt"
 	"gosir/internal/common"
 	"gosir/internal/logger"
+	"gosir/internal/metrics"
+	"gosir/internal/ratelimit"
+	"gosir/internal/service/audit"
+	"gosir/internal/service/auth"
+	"gosir/internal/service/oauth"
+	"gosir/internal/tracing"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+// Job 定时任务
+type Job struct {
+	Name        string       // 任务名称（唯一标识，用于命令行手动执行）
+	Spec        string       // Cron 表达式
+	Description string       // 任务描述
+	Run         func() error // 任务函数（已包含指标统计和 panic 恢复）
+
+	defaultSpec string       // 代码中定义的默认 Cron 表达式
+	entryID     cron.EntryID // 调度器中的任务 ID
+}
+
 // Manager 定时任务管理器
 type Manager struct {
-	cron *cron.Cron
+	cron      *cron.Cron
+	mu        sync.Mutex
+	jobs      []*Job
+	schedules map[string]string // 配置中覆盖的执行时间（任务名 -> Cron 表达式）
+	running   atomic.Bool
 }
 
 var manager *Manager
 
-// Init 初始化定时任务管理器
-func Init() {
-	manager = &Manager{
-		cron: cron.New(cron.WithSeconds()), // 支持秒级精度
+// NewManager 创建定时任务管理器并注册所有任务（不启动调度）
+// schedules 覆盖任务的默认执行时间（配置项 cron.jobs），为空时使用默认时间
+func NewManager(schedules map[string]string) *Manager {
+	cm := &Manager{
+		cron:      cron.New(cron.WithSeconds()), // 支持秒级精度
+		schedules: schedules,
 	}
 
 	// 注册所有定时任务
-	manager.registerTasks()
+	cm.registerTasks()
+
+	// 配置了不存在的任务
+	for name := range schedules {
+		if cm.findJob(name) == nil {
+			logger.Named(logger.ModuleCron).Warn("Unknown cron job in schedule config", zap.String("name", name))
+		}
+	}
+
+	return cm
+}
+
+// Init 初始化定时任务管理器
+func Init(schedules map[string]string) {
+	manager = NewManager(schedules)
 
 	// 启动定时任务
 	manager.cron.Start()
+	manager.running.Store(true)
 
-	logger.Info("Cron manager started")
+	logger.Named(logger.ModuleCron).Info("Cron manager started")
 }
 
 // Stop 停止定时任务管理器
-func Stop() {
+// 停止调度新的任务，返回的 context 在所有正在运行的任务结束后关闭
+func Stop() context.Context {
 	if manager != nil && manager.cron != nil {
-		manager.cron.Stop()
-		logger.Info("Cron manager stopped")
+		ctx := manager.cron.Stop()
+		manager.running.Store(false)
+		logger.Named(logger.ModuleCron).Info("Cron manager stopped")
+		return ctx
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+// IsRunning 定时任务调度器是否正在运行
+func IsRunning() bool {
+	return manager != nil && manager.running.Load()
+}
+
+// Jobs 获取已注册的任务列表
+func (cm *Manager) Jobs() []*Job {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	jobs := make([]*Job, len(cm.jobs))
+	copy(jobs, cm.jobs)
+	return jobs
+}
+
+// Run 按名称立即执行任务（同步执行，不经过调度器）
+func (cm *Manager) Run(name string) error {
+	cm.mu.Lock()
+	job := cm.findJob(name)
+	cm.mu.Unlock()
+
+	if job == nil {
+		return fmt.Errorf("cron job not found: %s", name)
+	}
+	logger.Named(logger.ModuleCron).Info("Running cron job manually", zap.String("name", name))
+	return job.Run()
+}
+
+// Reschedule 修改任务执行时间，spec 为空时恢复默认时间
+func (cm *Manager) Reschedule(name, spec string) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	job := cm.findJob(name)
+	if job == nil {
+		return fmt.Errorf("cron job not found: %s", name)
 	}
+	if spec == "" {
+		spec = job.defaultSpec
+	}
+	if spec == job.Spec {
+		return nil
+	}
+
+	run := job.Run
+	id, err := cm.cron.AddFunc(spec, func() { _ = run() })
+	if err != nil {
+		return fmt.Errorf("invalid spec %q for cron job %s: %w", spec, name, err)
+	}
+	cm.cron.Remove(job.entryID)
+
+	logger.Named(logger.ModuleCron).Info("Cron job rescheduled",
+		zap.String("name", name),
+		zap.String("old_spec", job.Spec),
+		zap.String("spec", spec),
+	)
+
+	// 替换任务记录，避免修改已返回给调用方的 Job
+	updated := *job
+	updated.Spec = spec
+	updated.entryID = id
+	for i, j := range cm.jobs {
+		if j == job {
+			cm.jobs[i] = &updated
+		}
+	}
+	return nil
+}
+
+// findJob 按名称查找任务（调用方持有锁或任务列表不再变化）
+func (cm *Manager) findJob(name string) *Job {
+	for _, job := range cm.jobs {
+		if job.Name == name {
+			return job
+		}
+	}
+	return nil
+}
+
+// Reschedule 修改正在运行的调度器中的任务执行时间，spec 为空时恢复默认时间
+func Reschedule(name, spec string) error {
+	if manager == nil {
+		return fmt.Errorf("cron manager not initialized")
+	}
+	return manager.Reschedule(name, spec)
 }
 
 // registerTasks 注册所有定时任务
 func (cm *Manager) registerTasks() {
 	// JWT 黑名单清理任务 - 每小时执行一次
-	cm.addJob("0 0 * * * *", "清理过期的 token 黑名单", cm.cleanupExpiredBlacklistTask)
+	cm.addJob("cleanup-blacklist", "0 0 * * * *", "清理过期的 token 黑名单", cm.cleanupExpiredBlacklistTask)
+
+	// 限流计数清理任务 - 每10分钟执行一次
+	cm.addJob("cleanup-rate-limits", "0 */10 * * * *", "清理过期的限流计数", cm.cleanupRateLimitsTask)
+
+	// OAuth 授权码和刷新令牌清理任务 - 每小时执行一次
+	cm.addJob("cleanup-oauth-tokens", "0 5 * * * *", "清理过期的 OAuth 授权码和刷新令牌", cm.cleanupOAuthTokensTask)
+
+	// 免密登录凭证清理任务 - 每小时执行一次
+	cm.addJob("cleanup-login-codes", "0 10 * * * *", "清理过期和已使用的免密登录验证码", cm.cleanupLoginCodesTask)
+
+	// 未验证注册清理任务 - 每小时执行一次
+	cm.addJob("cleanup-unverified-users", "0 15 * * * *", "删除超过验证期限仍未验证邮箱的注册用户", cm.cleanupUnverifiedUsersTask)
+
+	// 审计日志清理任务 - 每天凌晨3点30分执行
+	cm.addJob("cleanup-audit-logs", "0 30 3 * * *", "删除超过保留期限的审计日志", cm.cleanupAuditLogsTask)
 
 	// 示例1: 每5秒执行一次
-	cm.addJob("*/5 * * * * *", "每5秒执行的任务", cm.everyFiveSecondsTask)
+	cm.addJob("every-five-seconds", "*/5 * * * * *", "每5秒执行的任务", cm.everyFiveSecondsTask)
 
 	// 示例2: 每分钟执行一次
-	cm.addJob("0 * * * * *", "每分钟执行的任务", cm.everyMinuteTask)
+	cm.addJob("every-minute", "0 * * * * *", "每分钟执行的任务", cm.everyMinuteTask)
 
 	// 示例3: 每小时执行一次
-	cm.addJob("0 0 * * * *", "每小时执行的任务", cm.everyHourTask)
+	cm.addJob("every-hour", "0 0 * * * *", "每小时执行的任务", cm.everyHourTask)
 
 	// 示例4: 每天凌晨2点执行
-	cm.addJob("0 0 2 * * *", "每天凌晨2点执行的任务", cm.dailyTask)
+	cm.addJob("daily", "0 0 2 * * *", "每天凌晨2点执行的任务", cm.dailyTask)
 
 	// 示例5: 使用 Cron 表达式 (每天上午10点30分)
-	cm.addJob("0 30 10 * * *", "每天上午10点30分", cm.scheduledTask)
+	cm.addJob("scheduled", "0 30 10 * * *", "每天上午10点30分", cm.scheduledTask)
 }
 
-// addJob 添加任务
-func (cm *Manager) addJob(spec string, name string, job func()) {
-	_, err := cm.cron.AddFunc(spec, job)
+// addJob 添加任务，配置了执行时间时使用配置的时间
+func (cm *Manager) addJob(name, defaultSpec, description string, job func(ctx context.Context) error) {
+	spec := defaultSpec
+	if configured, ok := cm.schedules[name]; ok && configured != "" {
+		spec = configured
+	}
+
+	run := instrument(name, job)
+	id, err := cm.cron.AddFunc(spec, func() { _ = run() })
 	if err != nil {
-		logger.Error("Failed to add cron job",
+		logger.Named(logger.ModuleCron).Error("Failed to add cron job",
 			zap.String("name", name),
 			zap.String("spec", spec),
 			zap.Error(err),
@@ -72,62 +232,165 @@ func (cm *Manager) addJob(spec string, name string, job func()) {
 		return
 	}
 
-	logger.Info("Cron job registered",
+	cm.jobs = append(cm.jobs, &Job{
+		Name:        name,
+		Spec:        spec,
+		Description: description,
+		Run:         run,
+		defaultSpec: defaultSpec,
+		entryID:     id,
+	})
+
+	logger.Named(logger.ModuleCron).Info("Cron job registered",
 		zap.String("name", name),
 		zap.String("spec", spec),
 	)
 }
 
+// instrument 包装任务函数：每次执行创建一个根 span，统计执行次数、失败次数和耗时，panic 视为失败
+func instrument(name string, job func(ctx context.Context) error) func() error {
+	return func() (err error) {
+		ctx, span := tracing.Tracer().Start(context.Background(), "cron "+name,
+			trace.WithSpanKind(trace.SpanKindInternal),
+			trace.WithAttributes(attribute.String("cron.job", name)),
+		)
+		start := time.Now()
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("cron job panicked: %v", r)
+			}
+
+			metrics.CronJobRunsTotal.WithLabelValues(name).Inc()
+			metrics.CronJobDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+			if err != nil {
+				metrics.CronJobFailuresTotal.WithLabelValues(name).Inc()
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				logger.NamedCtx(ctx, logger.ModuleCron).Error("Cron job failed",
+					zap.String("name", name),
+					zap.Duration("duration", time.Since(start)),
+					zap.Error(err),
+				)
+			}
+			span.End()
+		}()
+
+		return job(ctx)
+	}
+}
+
 // ==================== 示例任务函数 ====================
 
 // cleanupExpiredBlacklistTask 清理过期的黑名单 token
-func (cm *Manager) cleanupExpiredBlacklistTask() {
+func (cm *Manager) cleanupExpiredBlacklistTask(ctx context.Context) error {
 	jwtManager := common.GetJWTManager()
 	if jwtManager == nil {
-		logger.Error("JWT manager not initialized for cleanup")
-		return
+		return fmt.Errorf("JWT manager not initialized for cleanup")
 	}
 
 	sizeBefore := jwtManager.GetBlacklistSize()
-	jwtManager.CleanupExpiredBlacklist()
+	if err := jwtManager.CleanupExpiredBlacklist(); err != nil {
+		return fmt.Errorf("failed to cleanup persisted blacklist: %w", err)
+	}
 	sizeAfter := jwtManager.GetBlacklistSize()
 
-	logger.Info("Blacklist cleanup completed",
+	logger.NamedCtx(ctx, logger.ModuleCron).Info("Blacklist cleanup completed",
 		zap.Int("before", sizeBefore),
 		zap.Int("after", sizeAfter),
 		zap.Int("cleaned", sizeBefore-sizeAfter),
 	)
+	return nil
+}
+
+// cleanupRateLimitsTask 清理过期的限流计数
+func (cm *Manager) cleanupRateLimitsTask(ctx context.Context) error {
+	deleted, err := ratelimit.DeleteExpired(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to cleanup rate limit counters: %w", err)
+	}
+
+	logger.NamedCtx(ctx, logger.ModuleCron).Info("Rate limit cleanup completed", zap.Int64("cleaned", deleted))
+	return nil
+}
+
+// cleanupOAuthTokensTask 清理过期的 OAuth 授权码和刷新令牌
+func (cm *Manager) cleanupOAuthTokensTask(ctx context.Context) error {
+	deleted, err := oauth.DeleteExpired(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to cleanup oauth tokens: %w", err)
+	}
+
+	logger.NamedCtx(ctx, logger.ModuleCron).Info("OAuth token cleanup completed", zap.Int64("cleaned", deleted))
+	return nil
+}
+
+// cleanupLoginCodesTask 清理过期和已使用的免密登录验证码和登录链接
+func (cm *Manager) cleanupLoginCodesTask(ctx context.Context) error {
+	deleted, err := auth.DeleteExpiredLoginCodes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to cleanup login codes: %w", err)
+	}
+
+	logger.NamedCtx(ctx, logger.ModuleCron).Info("Login code cleanup completed", zap.Int64("cleaned", deleted))
+	return nil
+}
+
+// cleanupUnverifiedUsersTask 删除超过验证期限仍未验证邮箱的注册用户
+func (cm *Manager) cleanupUnverifiedUsersTask(ctx context.Context) error {
+	deleted, err := auth.DeleteUnverifiedUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to cleanup unverified users: %w", err)
+	}
+
+	logger.NamedCtx(ctx, logger.ModuleCron).Info("Unverified user cleanup completed", zap.Int64("cleaned", deleted))
+	return nil
+}
+
+// cleanupAuditLogsTask 删除超过保留期限的审计日志
+func (cm *Manager) cleanupAuditLogsTask(ctx context.Context) error {
+	deleted, err := audit.DeleteExpired(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to cleanup audit logs: %w", err)
+	}
+
+	logger.NamedCtx(ctx, logger.ModuleCron).Info("Audit log cleanup completed", zap.Int64("cleaned", deleted))
+	return nil
 }
 
 // everyFiveSecondsTask 每5秒执行一次的任务
-func (cm *Manager) everyFiveSecondsTask() {
-	logger.Debug("执行每5秒任务", zap.String("task", "everyFiveSeconds"))
+func (cm *Manager) everyFiveSecondsTask(ctx context.Context) error {
+	logger.NamedCtx(ctx, logger.ModuleCron).Debug("执行每5秒任务", zap.String("task", "everyFiveSeconds"))
 	// 在这里添加你的业务逻辑
 	// 例如: 清理缓存、检查状态、发送心跳等
+	return nil
 }
 
 // everyMinuteTask 每分钟执行一次的任务
-func (cm *Manager) everyMinuteTask() {
-	logger.Debug("执行每分钟任务", zap.String("task", "everyMinute"))
+func (cm *Manager) everyMinuteTask(ctx context.Context) error {
+	logger.NamedCtx(ctx, logger.ModuleCron).Debug("执行每分钟任务", zap.String("task", "everyMinute"))
 	// 例如: 定期统计数据、同步信息等
+	return nil
 }
 
 // everyHourTask 每小时执行一次的任务
-func (cm *Manager) everyHourTask() {
-	logger.Debug("执行每小时任务", zap.String("task", "everyHour"))
+func (cm *Manager) everyHourTask(ctx context.Context) error {
+	logger.NamedCtx(ctx, logger.ModuleCron).Debug("执行每小时任务", zap.String("task", "everyHour"))
 	// 例如: 生成报表、备份数据等
+	return nil
 }
 
 // dailyTask 每天凌晨2点执行的任务
-func (cm *Manager) dailyTask() {
-	logger.Debug("执行每日任务", zap.String("task", "daily"))
-	// 例如: 数据归档、日志清理、定期维护等
+func (cm *Manager) dailyTask(ctx context.Context) error {
+	logger.NamedCtx(ctx, logger.ModuleCron).Debug("执行每日任务", zap.String("task", "daily"))
+	// 例如: 数据归档、定期维护等（日志轮转和清理由 logger 根据 log.maxBackups/maxAge 完成）
+	return nil
 }
 
 // scheduledTask 定时执行的任务
-func (cm *Manager) scheduledTask() {
-	logger.Debug("执行定时任务", zap.String("task", "scheduled"))
+func (cm *Manager) scheduledTask(ctx context.Context) error {
+	logger.NamedCtx(ctx, logger.ModuleCron).Debug("执行定时任务", zap.String("task", "scheduled"))
 	// 在这里添加你的具体业务逻辑
+	return nil
 }
 
 // ==================== 高级用法示例 ====================
@@ -158,12 +421,26 @@ func AddCustomJob(spec string, name string, job func()) error {
 		return fmt.Errorf("cron manager not initialized")
 	}
 
-	id, err := manager.cron.AddFunc(spec, job)
+	run := instrument(name, func(ctx context.Context) error {
+		job()
+		return nil
+	})
+	id, err := manager.cron.AddFunc(spec, func() { _ = run() })
 	if err != nil {
 		return err
 	}
 
-	logger.Info("Custom cron job added",
+	manager.mu.Lock()
+	manager.jobs = append(manager.jobs, &Job{
+		Name:        name,
+		Spec:        spec,
+		Run:         run,
+		defaultSpec: spec,
+		entryID:     id,
+	})
+	manager.mu.Unlock()
+
+	logger.Named(logger.ModuleCron).Info("Custom cron job added",
 		zap.String("name", name),
 		zap.String("spec", spec),
 		zap.Int64("id", int64(id)),
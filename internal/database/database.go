@@ -1,37 +1,97 @@
 package database
 
 import (
+	"fmt"
+
 	"go.uber.org/zap"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	gormlogger "gorm.io/gorm/logger"
 
+	"gosir/internal/clock"
 	"gosir/internal/logger"
 )
 
 var DB *gorm.DB
 
-func InitDB(dsn, logLevel string, zapLogger *zap.Logger) error {
+// sqlLogger 全局 GORM 日志适配器，支持运行时修改日志级别
+var sqlLogger *ZapLogger
+
+// MemoryDSN 内存数据库（test 模式使用），同一进程内的连接共享同一个数据库
+const MemoryDSN = "file:gosir?mode=memory&cache=shared"
+
+// Options 数据库初始化选项
+type Options struct {
+	DSN        string      // 数据库文件路径或 DSN
+	LogLevel   string      // SQL 日志级别: silent, error, warn, info
+	Logger     *zap.Logger // 日志输出
+	HideParams bool        // SQL 日志中不输出参数值（release 模式），避免敏感数据写入日志
+}
+
+func InitDB(opts Options) error {
 	var err error
 
 	// 解析日志级别
-	level := parseLogLevel(logLevel)
+	level := parseLogLevel(opts.LogLevel)
 
 	// 创建 Zap 日志适配器
-	zapLoggerAdapter := NewZapLogger(zapLogger, level)
+	sqlLogger = NewZapLogger(opts.Logger, level)
+	sqlLogger.hideParams = opts.HideParams
 
-	DB, err = gorm.Open(sqlite.Open(dsn), &gorm.Config{
-		Logger: zapLoggerAdapter,
+	// 自动填充的时间字段与业务代码一样使用 clock.Now()，test 模式下跟随测试时钟
+	DB, err = gorm.Open(sqlite.Open(opts.DSN), &gorm.Config{
+		Logger:  sqlLogger,
+		NowFunc: clock.Now,
 	})
 
 	if err != nil {
 		return err
 	}
 
+	// 注册链路追踪插件（未开启追踪时为 noop 实现）
+	if err := DB.Use(TracingPlugin{}); err != nil {
+		return err
+	}
+
 	logger.Info("Database connected successfully")
 	return nil
 }
 
+// gormLogLevels GORM 日志级别名称
+var gormLogLevels = map[string]gormlogger.LogLevel{
+	"silent": gormlogger.Silent,
+	"error":  gormlogger.Error,
+	"warn":   gormlogger.Warn,
+	"info":   gormlogger.Info,
+}
+
+// LogLevel 获取当前 SQL 日志级别（silent, error, warn, info）
+func LogLevel() string {
+	if sqlLogger == nil {
+		return ""
+	}
+	level := sqlLogger.Level()
+	for name, l := range gormLogLevels {
+		if l == level {
+			return name
+		}
+	}
+	return ""
+}
+
+// SetLogLevel 运行时修改 SQL 日志级别（silent, error, warn, info）
+func SetLogLevel(level string) error {
+	l, ok := gormLogLevels[level]
+	if !ok {
+		return fmt.Errorf("invalid database log level %q", level)
+	}
+	if sqlLogger == nil {
+		return fmt.Errorf("database is not initialized")
+	}
+	sqlLogger.SetLevel(l)
+	return nil
+}
+
 // parseLogLevel 解析日志级别字符串
 func parseLogLevel(level string) gormlogger.LogLevel {
 	switch level {
@@ -2,82 +2,142 @@ package database
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+
+	applogger "gosir/internal/logger"
+	"gosir/internal/metrics"
 )
 
 // ZapLogger 自定义 GORM Logger，使用 Zap 输出日志
 type ZapLogger struct {
 	logger *zap.Logger
-	level  logger.LogLevel
+	level  *atomic.Int32 // logger.LogLevel，支持运行时修改
+
+	hideParams bool // 日志中的 SQL 不包含参数值
 }
 
 // NewZapLogger 创建 Zap 日志适配器
 func NewZapLogger(zapLogger *zap.Logger, level logger.LogLevel) *ZapLogger {
-	return &ZapLogger{
+	l := &ZapLogger{
 		logger: zapLogger,
-		level:  level,
+		level:  new(atomic.Int32),
 	}
+	l.level.Store(int32(level))
+	return l
 }
 
-// LogMode 设置日志级别
+// LogMode 返回使用指定日志级别的新 logger（如 db.Debug()），不影响原 logger
 func (l *ZapLogger) LogMode(level logger.LogLevel) logger.Interface {
-	newLogger := *l
-	newLogger.level = level
-	return &newLogger
+	newLogger := NewZapLogger(l.logger, level)
+	newLogger.hideParams = l.hideParams
+	return newLogger
+}
+
+// ParamsFilter 实现 gorm.ParamsFilter，开启 hideParams 时日志中的 SQL 只保留占位符
+func (l *ZapLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if l.hideParams {
+		return sql, nil
+	}
+	return sql, params
+}
+
+// Level 获取当前日志级别
+func (l *ZapLogger) Level() logger.LogLevel {
+	return logger.LogLevel(l.level.Load())
+}
+
+// SetLevel 运行时修改日志级别
+func (l *ZapLogger) SetLevel(level logger.LogLevel) {
+	l.level.Store(int32(level))
 }
 
 // Info 记录信息日志
 func (l *ZapLogger) Info(ctx context.Context, msg string, data ...interface{}) {
-	if l.level >= logger.Info {
-		l.logger.Sugar().Infof(msg, data...)
+	if l.Level() >= logger.Info {
+		l.ctxLogger(ctx).Sugar().Infof(msg, data...)
 	}
 }
 
 // Warn 记录警告日志
 func (l *ZapLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
-	if l.level >= logger.Warn {
-		l.logger.Sugar().Warnf(msg, data...)
+	if l.Level() >= logger.Warn {
+		l.ctxLogger(ctx).Sugar().Warnf(msg, data...)
 	}
 }
 
 // Error 记录错误日志
 func (l *ZapLogger) Error(ctx context.Context, msg string, data ...interface{}) {
-	if l.level >= logger.Error {
-		l.logger.Sugar().Errorf(msg, data...)
+	if l.Level() >= logger.Error {
+		l.ctxLogger(ctx).Sugar().Errorf(msg, data...)
 	}
 }
 
-// Trace 记录 SQL 查询日志
+// Trace 记录 SQL 查询日志和指标（指标不受日志级别影响）
 func (l *ZapLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
-	if l.level <= logger.Silent {
-		return
-	}
-
 	elapsed := time.Since(begin)
 	sql, rows := fc()
 
+	operation := sqlOperation(sql)
+	metrics.DBQueryDuration.WithLabelValues(operation).Observe(elapsed.Seconds())
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		metrics.DBQueryErrorsTotal.WithLabelValues(operation).Inc()
+	}
+
+	level := l.Level()
+	if level <= logger.Silent {
+		return
+	}
+
+	log := l.ctxLogger(ctx)
 	switch {
-	case err != nil && l.level >= logger.Error:
-		l.logger.Error("SQL error",
+	case err != nil && level >= logger.Error:
+		log.Error("SQL error",
 			zap.Duration("duration", elapsed),
 			zap.Int64("rows", rows),
 			zap.String("sql", sql),
 			zap.Error(err),
 		)
-	case l.level == logger.Warn && elapsed > 200*time.Millisecond:
-		l.logger.Warn("Slow SQL",
+	case level == logger.Warn && elapsed > 200*time.Millisecond:
+		log.Warn("Slow SQL",
 			zap.Duration("duration", elapsed),
 			zap.Int64("rows", rows),
 			zap.String("sql", sql),
 		)
-	case l.level >= logger.Info:
-		l.logger.Info("SQL query",
+	case level >= logger.Info:
+		log.Info("SQL query",
 			zap.Duration("duration", elapsed),
 			zap.Int64("rows", rows),
 			zap.String("sql", sql),
 		)
 	}
 }
+
+// ctxLogger 附加 context 中的请求 ID 等字段（查询需通过 db.WithContext(ctx) 执行）
+func (l *ZapLogger) ctxLogger(ctx context.Context) *zap.Logger {
+	if fields := applogger.ContextFields(ctx); len(fields) > 0 {
+		return l.logger.With(fields...)
+	}
+	return l.logger
+}
+
+// sqlOperation 从 SQL 语句中提取操作类型，用作指标标签（限制取值范围）
+func sqlOperation(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if idx := strings.IndexAny(sql, " \t\n"); idx > 0 {
+		sql = sql[:idx]
+	}
+
+	switch op := strings.ToLower(sql); op {
+	case "select", "insert", "update", "delete":
+		return op
+	default:
+		return "other"
+	}
+}
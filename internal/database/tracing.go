@@ -0,0 +1,98 @@
+package database
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"gosir/internal/tracing"
+)
+
+// tracingSpanKey 在 gorm 实例中保存 span 的 key
+const tracingSpanKey = "gosir:tracing:span"
+
+// TracingPlugin GORM 链路追踪插件，为每条 SQL 创建子 span
+// 需要通过 db.WithContext(ctx) 执行查询才能关联到请求或定时任务的 span
+type TracingPlugin struct{}
+
+// Name 插件名称
+func (TracingPlugin) Name() string {
+	return "gosir:tracing"
+}
+
+// Initialize 注册 GORM 回调
+func (p TracingPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	hooks := []struct {
+		operation string
+		before    func(string, func(*gorm.DB)) error
+		after     func(string, func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+
+	for _, h := range hooks {
+		if err := h.before("gosir:tracing:before_"+h.operation, p.before(h.operation)); err != nil {
+			return err
+		}
+		if err := h.after("gosir:tracing:after_"+h.operation, p.after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// before 开始 span
+func (p TracingPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		// 只为已有父 span 的查询创建子 span，避免启动迁移等后台查询产生大量孤立的根 span
+		ctx := db.Statement.Context
+		if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+			return
+		}
+
+		_, span := tracing.Tracer().Start(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system.name", db.Dialector.Name()),
+				attribute.String("db.operation.name", operation),
+			),
+		)
+		db.InstanceSet(tracingSpanKey, span)
+	}
+}
+
+// after 结束 span，记录 SQL（不含参数值）、影响行数和错误
+func (p TracingPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.query.text", db.Statement.SQL.String()),
+		attribute.Int64("db.response.returned_rows", db.Statement.RowsAffected),
+	)
+	if db.Statement.Table != "" {
+		span.SetAttributes(attribute.String("db.collection.name", db.Statement.Table))
+	}
+
+	if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,236 @@
+package apikey
+
+import (
+	"errors"
+	"fmt"
+	"gosir/internal/common"
+	apikeymodel "gosir/internal/model/apikey"
+	"gosir/internal/service/apikey"
+	"strings"
+	"time"
+
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	zhtranslations "github.com/go-playground/validator/v10/translations/zh"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	apiKeyService *apikey.APIKeyService
+	validator     *validator.Validate
+	translator    ut.Translator
+}
+
+// New 创建 API Key 处理器
+func New(apiKeyService *apikey.APIKeyService) *Handler {
+	validate := validator.New()
+
+	// 获取中文翻译器
+	zhLocale := zh.New()
+	uni := ut.New(zhLocale, zhLocale)
+	translator, ok := uni.GetTranslator("zh")
+	if !ok {
+		panic("failed to get chinese translator")
+	}
+
+	// 注册默认翻译
+	if err := zhtranslations.RegisterDefaultTranslations(validate, translator); err != nil {
+		panic(fmt.Sprintf("failed to register translations: %v", err))
+	}
+
+	return &Handler{
+		apiKeyService: apiKeyService,
+		validator:     validate,
+		translator:    translator,
+	}
+}
+
+// APIKeyRequest 创建或修改 API Key 请求
+type APIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=100" example:"nightly-export"`                                     // 名称
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=users:read users:write admin" example:"users:read"` // 权限范围: users:read, users:write, admin
+	ExpiresAt *time.Time `json:"expires_at" example:"2027-01-08T10:00:00Z"`                                                     // 过期时间，为空表示永不过期
+}
+
+// CreateAPIKeyResponse 创建 API Key 响应
+type CreateAPIKeyResponse struct {
+	apikeymodel.APIKey
+	Key string `json:"key" example:"gsk_Ab3dEf9h_0123456789abcdefghijABCDEFGHIJ"` // 完整的 API Key，只在创建时返回一次
+}
+
+// ListAPIKeys 获取当前用户的 API Key 列表
+// @Summary      获取 API Key 列表
+// @Description  获取当前用户的所有 API Key（不包含 secret）
+// @Tags         API Key
+// @Produce      json
+// @Security     Bearer
+// @Success      200 {object} common.Response{data=[]apikeymodel.APIKey}
+// @Failure      401 {object} common.Response
+// @Router       /api/keys [get]
+func (h *Handler) ListAPIKeys(c echo.Context) error {
+	keys, err := h.apiKeyService.WithContext(c.Request().Context()).List(currentUserID(c))
+	if err != nil {
+		return common.Error(c, common.CodeInternalError, "获取 API Key 列表失败")
+	}
+	return common.Success(c, keys)
+}
+
+// CreateAPIKey 创建 API Key
+// @Summary      创建 API Key
+// @Description  为当前用户创建 API Key，完整的 key 只在响应中返回一次，请求时使用 Authorization: ApiKey {key}
+// @Tags         API Key
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body APIKeyRequest true "API Key 信息"
+// @Success      201 {object} common.Response{data=CreateAPIKeyResponse}
+// @Failure      400 {object} common.Response
+// @Failure      422 {object} common.Response
+// @Router       /api/keys [post]
+func (h *Handler) CreateAPIKey(c echo.Context) error {
+	var req APIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return common.Error(c, common.CodeBadRequest, "请求参数解析失败")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return common.Error(c, common.CodeValidationError, h.translateValidationError(err))
+	}
+
+	key, rawKey, err := h.apiKeyService.WithContext(c.Request().Context()).Create(currentUserID(c), apikey.KeyRequest{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		return h.serviceError(c, err, "创建 API Key 失败")
+	}
+	return common.Created(c, CreateAPIKeyResponse{APIKey: *key, Key: rawKey})
+}
+
+// GetAPIKey 获取 API Key 详情
+// @Summary      获取 API Key 详情
+// @Description  获取当前用户的 API Key 详情（不包含 secret）
+// @Tags         API Key
+// @Produce      json
+// @Security     Bearer
+// @Param        id path string true "API Key ID"
+// @Success      200 {object} common.Response{data=apikeymodel.APIKey}
+// @Failure      404 {object} common.Response
+// @Router       /api/keys/{id} [get]
+func (h *Handler) GetAPIKey(c echo.Context) error {
+	key, err := h.apiKeyService.WithContext(c.Request().Context()).Get(currentUserID(c), c.Param("id"))
+	if err != nil {
+		return h.serviceError(c, err, "获取 API Key 失败")
+	}
+	return common.Success(c, key)
+}
+
+// UpdateAPIKey 修改 API Key
+// @Summary      修改 API Key
+// @Description  修改当前用户 API Key 的名称、权限范围和过期时间，key 本身不变
+// @Tags         API Key
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id path string true "API Key ID"
+// @Param        request body APIKeyRequest true "API Key 信息"
+// @Success      200 {object} common.Response{data=apikeymodel.APIKey}
+// @Failure      404 {object} common.Response
+// @Failure      422 {object} common.Response
+// @Router       /api/keys/{id} [put]
+func (h *Handler) UpdateAPIKey(c echo.Context) error {
+	var req APIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return common.Error(c, common.CodeBadRequest, "请求参数解析失败")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return common.Error(c, common.CodeValidationError, h.translateValidationError(err))
+	}
+
+	key, err := h.apiKeyService.WithContext(c.Request().Context()).Update(currentUserID(c), c.Param("id"), apikey.KeyRequest{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		return h.serviceError(c, err, "修改 API Key 失败")
+	}
+	return common.Success(c, key)
+}
+
+// DeleteAPIKey 删除 API Key
+// @Summary      删除 API Key
+// @Description  删除（吊销）当前用户的 API Key，立即失效
+// @Tags         API Key
+// @Produce      json
+// @Security     Bearer
+// @Param        id path string true "API Key ID"
+// @Success      200 {object} common.Response
+// @Failure      404 {object} common.Response
+// @Router       /api/keys/{id} [delete]
+func (h *Handler) DeleteAPIKey(c echo.Context) error {
+	if err := h.apiKeyService.WithContext(c.Request().Context()).Delete(currentUserID(c), c.Param("id")); err != nil {
+		return h.serviceError(c, err, "删除 API Key 失败")
+	}
+	return common.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// serviceError 将服务层错误转换为响应
+func (h *Handler) serviceError(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, apikey.ErrAPIKeyNotFound):
+		return common.Error(c, common.CodeNotFound, "API Key 不存在")
+	case errors.Is(err, apikey.ErrInvalidExpiry):
+		return common.Error(c, common.CodeValidationError, "过期时间必须晚于当前时间")
+	case errors.Is(err, apikey.ErrInvalidScope):
+		return common.Error(c, common.CodeValidationError, "无效的权限范围")
+	case errors.Is(err, apikey.ErrTooManyKeys):
+		return common.Error(c, common.CodeBadRequest, "API Key 数量已达上限")
+	default:
+		return common.ErrorWithDetail(c, common.CodeInternalError, fallback, err)
+	}
+}
+
+// currentUserID 当前登录用户 ID
+func currentUserID(c echo.Context) string {
+	userID, _ := c.Get("user_id").(string)
+	return userID
+}
+
+// 中文错误消息映射
+var fieldNames = map[string]string{
+	"Name":   "名称",
+	"Scopes": "权限范围",
+}
+
+// translateValidationError 翻译验证错误
+func (h *Handler) translateValidationError(err error) string {
+	var fieldMessages []string
+
+	for _, e := range err.(validator.ValidationErrors) {
+		// 列表元素的字段名为 Scopes[0]
+		fieldName, _, _ := strings.Cut(e.Field(), "[")
+		chineseField := fieldNames[fieldName]
+		if chineseField == "" {
+			chineseField = fieldName
+		}
+
+		var errorMsg string
+		switch e.Tag() {
+		case "required":
+			errorMsg = fmt.Sprintf("%s不能为空", chineseField)
+		case "min":
+			errorMsg = fmt.Sprintf("%s至少需要%s项", chineseField, e.Param())
+		case "max":
+			errorMsg = fmt.Sprintf("%s长度不能超过%s个字符", chineseField, e.Param())
+		case "oneof":
+			errorMsg = fmt.Sprintf("%s只能是 %s", chineseField, e.Param())
+		default:
+			errorMsg = fmt.Sprintf("%s验证失败: %s", chineseField, e.Tag())
+		}
+		fieldMessages = append(fieldMessages, errorMsg)
+	}
+
+	return strings.Join(fieldMessages, "；")
+}
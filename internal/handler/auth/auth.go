@@ -1,8 +1,13 @@
 package auth
 
 import (
+	"errors"
 	"fmt"
 	"gosir/internal/common"
+	"gosir/internal/logger"
+	"gosir/internal/metrics"
+	model "gosir/internal/model/user"
+	"gosir/internal/service/audit"
 	"gosir/internal/service/auth"
 	"gosir/internal/service/user"
 	"strings"
@@ -12,13 +17,17 @@ import (
 	"github.com/go-playground/validator/v10"
 	zhtranslations "github.com/go-playground/validator/v10/translations/zh"
 	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
 )
 
 type Handler struct {
 	loginService *auth.LoginService
+	oidcService  *auth.OIDCLoginService
+	passwordless *auth.PasswordlessService
 	userService  *user.UserService
 	validator    *validator.Validate
 	translator   ut.Translator
+	register     *auth.RegisterService
 }
 
 // New 创建认证处理器
@@ -40,9 +49,12 @@ func New(userService *user.UserService) *Handler {
 
 	return &Handler{
 		loginService: auth.NewLoginService(),
+		oidcService:  auth.NewOIDCLoginService(),
+		passwordless: auth.NewPasswordlessService(),
 		userService:  userService,
 		validator:    validate,
 		translator:   translator,
+		register:     auth.NewRegisterService(),
 	}
 }
 
@@ -54,8 +66,9 @@ type LoginRequest struct {
 
 // LoginResponse 登录响应
 type LoginResponse struct {
-	Token string      `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."` // JWT token
-	User  interface{} `json:"user"`                                                    // 用户信息
+	Token              string      `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."` // JWT token
+	User               interface{} `json:"user"`                                                    // 用户信息
+	MustChangePassword bool        `json:"must_change_password" example:"false"`                    // 是否必须先修改密码（为 true 时 token 只能用于修改密码）
 }
 
 // Login 登录
@@ -68,6 +81,7 @@ type LoginResponse struct {
 // @Success      200 {object} common.Response{data=LoginResponse}
 // @Failure      400 {object} common.Response
 // @Failure      401 {object} common.Response
+// @Failure      403 {object} common.Response
 // @Router       /auth/login [post]
 func (h *Handler) Login(c echo.Context) error {
 	var req LoginRequest
@@ -83,23 +97,74 @@ func (h *Handler) Login(c echo.Context) error {
 	}
 
 	// 验证账号密码
-	userData, err := h.loginService.LoginByAccount(req.Account, req.Password)
+	ctx := c.Request().Context()
+	userData, err := h.loginService.WithContext(ctx).LoginByAccount(req.Account, req.Password)
 	if err != nil {
+		metrics.LoginTotal.WithLabelValues(metrics.LoginFailure).Inc()
+		auditLoginFailed(c, req.Account, "password", err)
+		logger.NamedCtx(ctx, logger.ModuleAuth).Warn("Login failed",
+			zap.String("account", req.Account),
+			zap.String("ip", c.RealIP()),
+			zap.Error(err),
+		)
+		switch {
+		case errors.Is(err, auth.ErrUserNotVerified):
+			return common.Error(c, common.CodeForbidden, "邮箱尚未验证，请先点击验证邮件中的链接")
+		case errors.Is(err, auth.ErrUserDisabled):
+			return common.Error(c, common.CodeForbidden, "用户已被禁用")
+		}
 		return common.Error(c, common.CodeUnauthorized, "账号或密码错误")
 	}
 
-	// 生成 token
-	token, err := common.GenerateToken(userData.ID)
+	token, err := loginToken(userData)
 	if err != nil {
 		return common.Error(c, common.CodeInternalError, "生成 token 失败")
 	}
 
+	metrics.LoginTotal.WithLabelValues(metrics.LoginSuccess).Inc()
+	auditLogin(c, userData.ID, "password")
+	logger.NamedCtx(ctx, logger.ModuleAuth).Info("Login succeeded",
+		zap.String("user_id", userData.ID),
+		zap.String("ip", c.RealIP()),
+		zap.Bool("must_change_password", userData.MustChangePassword),
+	)
+
 	// 不返回密码
 	userData.Password = ""
 
 	return common.Success(c, LoginResponse{
-		Token: token,
-		User:  userData,
+		Token:              token,
+		User:               userData,
+		MustChangePassword: userData.MustChangePassword,
+	})
+}
+
+// loginToken 生成登录 token（必须修改密码的用户只签发受限 token）
+func loginToken(userData *model.User) (string, error) {
+	if userData.MustChangePassword {
+		return common.GeneratePasswordChangeToken(userData.ID)
+	}
+	return common.GenerateToken(userData.ID)
+}
+
+// auditLogin 记录登录成功审计日志，method 为登录方式
+func auditLogin(c echo.Context, userID, method string) {
+	audit.Record(c.Request().Context(), audit.Entry{
+		ActorID:    userID,
+		Action:     audit.ActionLogin,
+		TargetType: audit.TargetUser,
+		TargetID:   userID,
+		Detail:     method,
+	})
+}
+
+// auditLoginFailed 记录登录失败审计日志，account 为提交的账号（可能为空）
+func auditLoginFailed(c echo.Context, account, method string, err error) {
+	audit.Record(c.Request().Context(), audit.Entry{
+		Action:     audit.ActionLoginFailed,
+		TargetType: audit.TargetAccount,
+		TargetID:   account,
+		Detail:     method + ": " + err.Error(),
 	})
 }
 
@@ -116,6 +181,18 @@ func (h *Handler) translateValidationError(err error) string {
 			chineseField = "账号"
 		case "Password":
 			chineseField = "密码"
+		case "OldPassword":
+			chineseField = "旧密码"
+		case "NewPassword":
+			chineseField = "新密码"
+		case "Method":
+			chineseField = "登录方式"
+		case "Code":
+			chineseField = "验证码"
+		case "Name":
+			chineseField = "姓名"
+		case "Email":
+			chineseField = "邮箱"
 		default:
 			chineseField = fieldName
 		}
@@ -2,8 +2,11 @@ package auth
 
 import (
 	"gosir/internal/common"
+	"gosir/internal/logger"
+	"gosir/internal/service/audit"
 
 	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
 )
 
 // Logout 登出
@@ -29,7 +32,18 @@ func (h *Handler) Logout(c echo.Context) error {
 		return common.Error(c, common.CodeInternalError, "JWT 管理器未初始化")
 	}
 
-	jwtManager.AddToBlacklist(claims.JTI, claims.ExpiresAt.Time)
+	log := logger.NamedCtx(c.Request().Context(), logger.ModuleAuth)
+	if err := jwtManager.AddToBlacklist(claims.JTI, claims.ExpiresAt.Time); err != nil {
+		log.Error("Failed to blacklist token on logout", zap.String("user_id", claims.UserID), zap.Error(err))
+		return common.Error(c, common.CodeInternalError, "登出失败")
+	}
+
+	audit.Record(c.Request().Context(), audit.Entry{
+		Action:     audit.ActionLogout,
+		TargetType: audit.TargetUser,
+		TargetID:   claims.UserID,
+	})
+	log.Info("User logged out", zap.String("user_id", claims.UserID))
 
 	return common.Success(c, nil)
 }
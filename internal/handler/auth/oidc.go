@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"gosir/internal/common"
+	"gosir/internal/logger"
+	"gosir/internal/metrics"
+	"gosir/internal/oidc"
+	"gosir/internal/service/auth"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	oidcCookieName = "gosir_oidc"     // 保存登录临时参数的 cookie
+	oidcCookiePath = "/auth/oidc/"    // cookie 只在登录和回调地址上发送
+	oidcStateTTL   = 10 * time.Minute // 完成登录的时限
+)
+
+// OIDCProvider 可用的 OIDC 提供方
+type OIDCProvider struct {
+	Name     string `json:"name" example:"google"`                       // 提供方名称
+	LoginURL string `json:"login_url" example:"/auth/oidc/google/login"` // 发起登录的地址（浏览器跳转）
+}
+
+// ListOIDCProviders 获取可用的 OIDC 提供方
+// @Summary      OIDC 提供方列表
+// @Description  获取已配置的外部身份提供方，前端据此展示第三方登录入口
+// @Tags         认证
+// @Produce      json
+// @Success      200 {object} common.Response{data=[]OIDCProvider}
+// @Router       /auth/oidc/providers [get]
+func (h *Handler) ListOIDCProviders(c echo.Context) error {
+	providers := make([]OIDCProvider, 0)
+	for _, name := range oidc.Names() {
+		providers = append(providers, OIDCProvider{
+			Name:     name,
+			LoginURL: "/auth/oidc/" + name + "/login",
+		})
+	}
+	return common.Success(c, providers)
+}
+
+// OIDCLogin 发起 OIDC 登录
+// @Summary      OIDC 登录
+// @Description  跳转到外部身份提供方登录（authorization code + PKCE），登录完成后提供方回调 /auth/oidc/{provider}/callback
+// @Tags         认证
+// @Param        provider path string true "提供方名称"
+// @Success      302
+// @Failure      404 {object} common.Response
+// @Router       /auth/oidc/{provider}/login [get]
+func (h *Handler) OIDCLogin(c echo.Context) error {
+	ctx := c.Request().Context()
+	providerName := c.Param("provider")
+
+	authURL, state, err := h.oidcService.WithContext(ctx).Begin(providerName)
+	if errors.Is(err, auth.ErrUnknownProvider) {
+		return common.Error(c, common.CodeNotFound, "登录方式不存在")
+	}
+	if err != nil {
+		logger.NamedCtx(ctx, logger.ModuleAuth).Error("OIDC login failed to start",
+			zap.String("provider", providerName),
+			zap.Error(err),
+		)
+		return common.Error(c, common.CodeInternalError, "暂时无法使用该登录方式")
+	}
+
+	value, err := json.Marshal(state)
+	if err != nil {
+		return common.Error(c, common.CodeInternalError, "暂时无法使用该登录方式")
+	}
+	c.SetCookie(&http.Cookie{
+		Name:     oidcCookieName,
+		Value:    base64.RawURLEncoding.EncodeToString(value),
+		Path:     oidcCookiePath,
+		MaxAge:   int(oidcStateTTL / time.Second),
+		Secure:   c.Scheme() == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback OIDC 登录回调
+// @Summary      OIDC 登录回调
+// @Description  提供方登录完成后的回调地址，校验 state 和 ID Token 后签发 JWT token
+// @Tags         认证
+// @Produce      json
+// @Param        provider path string true "提供方名称"
+// @Param        code query string true "授权码"
+// @Param        state query string true "state"
+// @Success      200 {object} common.Response{data=LoginResponse}
+// @Failure      400 {object} common.Response
+// @Failure      401 {object} common.Response
+// @Failure      403 {object} common.Response
+// @Router       /auth/oidc/{provider}/callback [get]
+func (h *Handler) OIDCCallback(c echo.Context) error {
+	ctx := c.Request().Context()
+	providerName := c.Param("provider")
+	log := logger.NamedCtx(ctx, logger.ModuleAuth).With(
+		zap.String("provider", providerName),
+		zap.String("ip", c.RealIP()),
+	)
+
+	// 临时参数只能使用一次
+	state, err := readOIDCState(c)
+	c.SetCookie(&http.Cookie{
+		Name:     oidcCookieName,
+		Path:     oidcCookiePath,
+		MaxAge:   -1,
+		Secure:   c.Scheme() == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	if err != nil || state.Provider != providerName ||
+		subtle.ConstantTimeCompare([]byte(state.State), []byte(c.QueryParam("state"))) != 1 {
+		log.Warn("OIDC callback with invalid state", zap.Error(err))
+		return common.Error(c, common.CodeBadRequest, "登录已过期或无效，请重新登录")
+	}
+
+	if reason := c.QueryParam("error"); reason != "" {
+		log.Warn("OIDC provider returned error",
+			zap.String("error", reason),
+			zap.String("error_description", c.QueryParam("error_description")),
+		)
+		metrics.LoginTotal.WithLabelValues(metrics.LoginFailure).Inc()
+		return common.Error(c, common.CodeUnauthorized, "第三方登录未完成")
+	}
+	code := c.QueryParam("code")
+	if code == "" {
+		return common.Error(c, common.CodeBadRequest, "缺少授权码")
+	}
+
+	userData, err := h.oidcService.WithContext(ctx).Complete(state, code)
+	if err != nil {
+		metrics.LoginTotal.WithLabelValues(metrics.LoginFailure).Inc()
+		auditLoginFailed(c, "", "oidc:"+providerName, err)
+		log.Warn("OIDC login failed", zap.Error(err))
+		return oidcError(c, err)
+	}
+
+	token, err := common.GenerateToken(userData.ID)
+	if err != nil {
+		return common.Error(c, common.CodeInternalError, "生成 token 失败")
+	}
+
+	metrics.LoginTotal.WithLabelValues(metrics.LoginSuccess).Inc()
+	auditLogin(c, userData.ID, "oidc:"+providerName)
+	log.Info("OIDC login succeeded", zap.String("user_id", userData.ID))
+
+	userData.Password = ""
+	return common.Success(c, LoginResponse{
+		Token: token,
+		User:  userData,
+	})
+}
+
+// readOIDCState 读取发起登录时保存的临时参数
+func readOIDCState(c echo.Context) (*auth.OIDCState, error) {
+	cookie, err := c.Cookie(oidcCookieName)
+	if err != nil {
+		return nil, err
+	}
+	value, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return nil, err
+	}
+	var state auth.OIDCState
+	if err := json.Unmarshal(value, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// oidcError 将 OIDC 登录错误转换为响应
+func oidcError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, auth.ErrUnknownProvider):
+		return common.Error(c, common.CodeNotFound, "登录方式不存在")
+	case errors.Is(err, auth.ErrIdentityNotLinked):
+		return common.Error(c, common.CodeForbidden, "该账号尚未开通，请联系管理员")
+	case errors.Is(err, auth.ErrEmailRequired):
+		return common.Error(c, common.CodeForbidden, "无法获取邮箱，请在身份提供方授权邮箱信息")
+	case errors.Is(err, auth.ErrEmailNotVerified):
+		return common.Error(c, common.CodeForbidden, "邮箱未验证")
+	case errors.Is(err, auth.ErrEmailDomainNotAllowed):
+		return common.Error(c, common.CodeForbidden, "该邮箱域名不允许登录")
+	case errors.Is(err, auth.ErrEmailTaken):
+		return common.Error(c, common.CodeForbidden, "该邮箱已被其他账号使用")
+	case errors.Is(err, auth.ErrUserDisabled):
+		return common.Error(c, common.CodeForbidden, "用户已被禁用")
+	case errors.Is(err, auth.ErrUserNotVerified):
+		return common.Error(c, common.CodeForbidden, "邮箱尚未验证，请先点击验证邮件中的链接")
+	case errors.Is(err, oidc.ErrInvalidIDToken):
+		return common.Error(c, common.CodeUnauthorized, "身份验证失败")
+	default:
+		return common.Error(c, common.CodeUnauthorized, "第三方登录失败")
+	}
+}
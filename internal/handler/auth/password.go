@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"errors"
+	"gosir/internal/common"
+	"gosir/internal/service/user"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ChangePasswordRequest 修改密码请求
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" validate:"required" example:"old-password"`       // 旧密码
+	NewPassword string `json:"new_password" validate:"required,min=8" example:"new-password"` // 新密码
+}
+
+// ChangePasswordResponse 修改密码响应
+type ChangePasswordResponse struct {
+	Token string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."` // 新 token（旧 token 已失效）
+}
+
+// ChangePassword 修改密码
+// @Summary      修改密码
+// @Description  修改当前用户密码，成功后旧 token 失效并返回新 token。必须修改密码的用户（如初始管理员）登录后只能调用该接口
+// @Tags         认证
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body ChangePasswordRequest true "修改密码信息"
+// @Success      200 {object} common.Response{data=ChangePasswordResponse}
+// @Failure      400 {object} common.Response
+// @Failure      401 {object} common.Response
+// @Router       /api/auth/password [post]
+func (h *Handler) ChangePassword(c echo.Context) error {
+	var req ChangePasswordRequest
+
+	// 解析请求
+	if err := c.Bind(&req); err != nil {
+		return common.Error(c, common.CodeBadRequest, "请求参数解析失败")
+	}
+
+	// 验证参数
+	if err := h.validator.Struct(&req); err != nil {
+		return common.Error(c, common.CodeValidationError, h.translateValidationError(err))
+	}
+
+	claims, ok := c.Get("claims").(*common.JWTClaims)
+	if !ok {
+		return common.Error(c, common.CodeUnauthorized, "无效的认证信息")
+	}
+
+	jwtManager := common.GetJWTManager()
+	if jwtManager == nil {
+		return common.Error(c, common.CodeInternalError, "JWT 管理器未初始化")
+	}
+
+	if _, err := h.userService.WithContext(c.Request().Context()).ChangePassword(claims.UserID, req.OldPassword, req.NewPassword); err != nil {
+		switch {
+		case errors.Is(err, user.ErrPasswordMismatch):
+			return common.Error(c, common.CodeBadRequest, "旧密码错误")
+		case errors.Is(err, user.ErrPasswordUnchanged):
+			return common.Error(c, common.CodeBadRequest, "新密码不能与旧密码相同")
+		default:
+			return common.Error(c, common.CodeInternalError, "修改密码失败")
+		}
+	}
+
+	// 旧 token 加入黑名单，签发新的完整权限 token
+	if err := jwtManager.AddToBlacklist(claims.JTI, claims.ExpiresAt.Time); err != nil {
+		return common.Error(c, common.CodeInternalError, "修改密码失败")
+	}
+
+	token, err := jwtManager.GenerateToken(claims.UserID)
+	if err != nil {
+		return common.Error(c, common.CodeInternalError, "生成 token 失败")
+	}
+
+	return common.Success(c, ChangePasswordResponse{
+		Token: token,
+	})
+}
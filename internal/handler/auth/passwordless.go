@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"errors"
+
+	"gosir/internal/common"
+	"gosir/internal/logger"
+	"gosir/internal/metrics"
+	model "gosir/internal/model/user"
+	"gosir/internal/service/auth"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// PasswordlessStartRequest 发送验证码或登录链接请求
+type PasswordlessStartRequest struct {
+	Account string `json:"account" validate:"required,max=100" example:"user@example.com"` // 账号（邮箱或手机号）
+	Method  string `json:"method" validate:"required,oneof=code link" example:"code"`      // 登录方式：code 验证码，link 登录链接
+}
+
+// PasswordlessVerifyRequest 验证码或登录链接登录请求
+type PasswordlessVerifyRequest struct {
+	Account string `json:"account" validate:"max=100" example:"user@example.com"` // 账号（使用验证码时必填）
+	Code    string `json:"code" validate:"max=20" example:"123456"`               // 验证码
+	Token   string `json:"token" validate:"max=200" example:""`                   // 登录链接中的 token（使用登录链接时必填）
+}
+
+// PasswordlessStart 发送验证码或登录链接
+// @Summary      发送免密登录验证码或登录链接
+// @Description  向邮箱或手机号发送一次性验证码或登录链接。为避免暴露账号是否存在，账号不存在时同样返回成功
+// @Tags         认证
+// @Accept       json
+// @Produce      json
+// @Param        request body PasswordlessStartRequest true "账号和登录方式"
+// @Success      200 {object} common.Response
+// @Failure      400 {object} common.Response
+// @Failure      422 {object} common.Response
+// @Failure      429 {object} common.Response
+// @Router       /auth/passwordless/start [post]
+func (h *Handler) PasswordlessStart(c echo.Context) error {
+	var req PasswordlessStartRequest
+	if err := c.Bind(&req); err != nil {
+		return common.Error(c, common.CodeBadRequest, "请求参数解析失败")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return common.Error(c, common.CodeValidationError, h.translateValidationError(err))
+	}
+
+	ctx := c.Request().Context()
+	err := h.passwordless.WithContext(ctx).Start(req.Account, req.Method)
+	switch {
+	case err == nil:
+		return common.SuccessWithMessage(c, "如果账号存在，验证码或登录链接已发送", nil)
+	case errors.Is(err, auth.ErrLinkNotConfigured):
+		return common.Error(c, common.CodeBadRequest, "不支持登录链接，请使用验证码")
+	case errors.Is(err, auth.ErrChannelUnsupported):
+		return common.Error(c, common.CodeBadRequest, "暂不支持向该类型账号发送验证码")
+	case errors.Is(err, auth.ErrTooManyCodeRequests):
+		return common.Error(c, common.CodeTooManyRequests, "发送过于频繁，请稍后再试")
+	default:
+		logger.NamedCtx(ctx, logger.ModuleAuth).Error("Passwordless login failed to start",
+			zap.String("ip", c.RealIP()),
+			zap.Error(err),
+		)
+		return common.Error(c, common.CodeInternalError, "发送失败")
+	}
+}
+
+// PasswordlessVerify 使用验证码或登录链接登录
+// @Summary      免密登录
+// @Description  使用账号和验证码，或登录链接中的 token 登录。验证码和登录链接只能使用一次
+// @Tags         认证
+// @Accept       json
+// @Produce      json
+// @Param        request body PasswordlessVerifyRequest true "验证码或登录链接 token"
+// @Success      200 {object} common.Response{data=LoginResponse}
+// @Failure      400 {object} common.Response
+// @Failure      401 {object} common.Response
+// @Failure      403 {object} common.Response
+// @Router       /auth/passwordless/verify [post]
+func (h *Handler) PasswordlessVerify(c echo.Context) error {
+	var req PasswordlessVerifyRequest
+	if err := c.Bind(&req); err != nil {
+		return common.Error(c, common.CodeBadRequest, "请求参数解析失败")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return common.Error(c, common.CodeValidationError, h.translateValidationError(err))
+	}
+	if req.Token == "" && (req.Account == "" || req.Code == "") {
+		return common.Error(c, common.CodeValidationError, "请提供账号和验证码，或登录链接中的 token")
+	}
+
+	ctx := c.Request().Context()
+	log := logger.NamedCtx(ctx, logger.ModuleAuth).With(zap.String("ip", c.RealIP()))
+	service := h.passwordless.WithContext(ctx)
+
+	var userData *model.User
+	var err error
+	if req.Token != "" {
+		userData, err = service.VerifyLink(req.Token)
+	} else {
+		userData, err = service.VerifyCode(req.Account, req.Code)
+	}
+	if err != nil {
+		metrics.LoginTotal.WithLabelValues(metrics.LoginFailure).Inc()
+		auditLoginFailed(c, req.Account, "passwordless", err)
+		log.Warn("Passwordless login failed", zap.String("account", req.Account), zap.Error(err))
+		switch {
+		case errors.Is(err, auth.ErrInvalidLoginCode):
+			return common.Error(c, common.CodeUnauthorized, "验证码或登录链接无效或已过期")
+		case errors.Is(err, auth.ErrUserDisabled):
+			return common.Error(c, common.CodeForbidden, "用户已被禁用")
+		case errors.Is(err, auth.ErrUserNotVerified):
+			return common.Error(c, common.CodeForbidden, "邮箱尚未验证，请先点击验证邮件中的链接")
+		default:
+			return common.Error(c, common.CodeInternalError, "登录失败")
+		}
+	}
+
+	token, err := loginToken(userData)
+	if err != nil {
+		return common.Error(c, common.CodeInternalError, "生成 token 失败")
+	}
+
+	metrics.LoginTotal.WithLabelValues(metrics.LoginSuccess).Inc()
+	auditLogin(c, userData.ID, "passwordless")
+	log.Info("Passwordless login succeeded",
+		zap.String("user_id", userData.ID),
+		zap.Bool("must_change_password", userData.MustChangePassword),
+	)
+
+	userData.Password = ""
+	return common.Success(c, LoginResponse{
+		Token:              token,
+		User:               userData,
+		MustChangePassword: userData.MustChangePassword,
+	})
+}
@@ -1,7 +1,11 @@
 package auth
 
 import (
+	"errors"
 	"gosir/internal/common"
+	"gosir/internal/repository"
+	"gosir/internal/service/audit"
+	"gosir/internal/service/auth"
 	"strings"
 
 	"github.com/labstack/echo/v4"
@@ -47,12 +51,36 @@ func (h *Handler) RefreshToken(c echo.Context) error {
 		return common.Error(c, common.CodeInternalError, "JWT 管理器未初始化")
 	}
 
+	// 被刷新 token 的用户被禁用或 token 已被撤销时不能刷新
+	if claims, err := jwtManager.ValidateToken(req.Token); err == nil && claims.UserID != "" {
+		var notFound *repository.UserNotFoundError
+		_, err := auth.TokenUser(c.Request().Context(), claims)
+		switch {
+		case err == nil:
+		case errors.Is(err, auth.ErrUserDisabled):
+			return common.Error(c, common.CodeForbidden, "用户已被禁用")
+		case errors.As(err, &notFound), errors.Is(err, auth.ErrTokenRevoked), errors.Is(err, auth.ErrUserNotVerified):
+			return common.Error(c, common.CodeUnauthorized, "token 已失效，请重新登录")
+		default:
+			return common.ErrorWithDetail(c, common.CodeInternalError, "刷新 token 失败", err)
+		}
+	}
+
 	newToken, err := jwtManager.RefreshToken(req.Token)
 	if err != nil {
 		if strings.Contains(err.Error(), "已失效") {
 			return common.Error(c, common.CodeUnauthorized, "token 已失效，请重新登录")
 		}
-		return common.Error(c, common.CodeUnauthorized, "刷新 token 失败: "+err.Error())
+		return common.ErrorWithDetail(c, common.CodeUnauthorized, "刷新 token 失败", err)
+	}
+
+	// 审计对象为被刷新 token 的用户
+	if claims, err := jwtManager.ValidateToken(newToken); err == nil {
+		audit.Record(c.Request().Context(), audit.Entry{
+			Action:     audit.ActionTokenRefresh,
+			TargetType: audit.TargetUser,
+			TargetID:   claims.UserID,
+		})
 	}
 
 	return common.Success(c, RefreshTokenResponse{
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"errors"
+
+	"gosir/internal/common"
+	"gosir/internal/logger"
+	"gosir/internal/service/auth"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// RegisterRequest 注册请求
+type RegisterRequest struct {
+	Name     string `json:"name" validate:"required,max=50" example:"张三"`                           // 姓名
+	Email    string `json:"email" validate:"required,email,max=100" example:"zhangsan@example.com"` // 邮箱
+	Password string `json:"password" validate:"required,min=8,max=72" example:"password123"`        // 密码
+}
+
+// ResendVerificationRequest 重新发送验证邮件请求
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email,max=100" example:"zhangsan@example.com"` // 注册邮箱
+}
+
+// Register 注册
+// @Summary      用户注册
+// @Description  使用邮箱注册账号，注册后向邮箱发送验证链接，验证邮箱后才能登录。为避免暴露邮箱是否已注册，邮箱已被使用时同样返回成功，并通过邮件通知邮箱所有人
+// @Tags         认证
+// @Accept       json
+// @Produce      json
+// @Param        request body RegisterRequest true "注册信息"
+// @Success      200 {object} common.Response
+// @Failure      400 {object} common.Response
+// @Failure      422 {object} common.Response
+// @Router       /auth/register [post]
+func (h *Handler) Register(c echo.Context) error {
+	var req RegisterRequest
+	if err := c.Bind(&req); err != nil {
+		return common.Error(c, common.CodeBadRequest, "请求参数解析失败")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return common.Error(c, common.CodeValidationError, h.translateValidationError(err))
+	}
+
+	ctx := c.Request().Context()
+	if err := h.register.WithContext(ctx).Register(req.Name, req.Email, req.Password); err != nil {
+		logger.NamedCtx(ctx, logger.ModuleAuth).Error("Registration failed",
+			zap.String("ip", c.RealIP()),
+			zap.Error(err),
+		)
+		return common.Error(c, common.CodeInternalError, "注册失败")
+	}
+
+	return common.SuccessWithMessage(c, "注册成功，请查收验证邮件并点击其中的链接完成验证", nil)
+}
+
+// VerifyEmail 验证邮箱
+// @Summary      验证邮箱
+// @Description  使用验证邮件中的链接激活注册的账号，已激活的账号再次验证同样返回成功
+// @Tags         认证
+// @Produce      json
+// @Param        token query string true "验证链接中的 token"
+// @Success      200 {object} common.Response
+// @Failure      400 {object} common.Response
+// @Failure      403 {object} common.Response
+// @Router       /auth/verify [get]
+func (h *Handler) VerifyEmail(c echo.Context) error {
+	token := c.QueryParam("token")
+	if token == "" {
+		return common.Error(c, common.CodeBadRequest, "缺少验证 token")
+	}
+
+	ctx := c.Request().Context()
+	_, err := h.register.WithContext(ctx).Verify(token)
+	switch {
+	case err == nil:
+		return common.SuccessWithMessage(c, "邮箱验证成功，请登录", nil)
+	case errors.Is(err, auth.ErrInvalidVerifyToken):
+		return common.Error(c, common.CodeBadRequest, "验证链接无效或已过期")
+	case errors.Is(err, auth.ErrUserDisabled):
+		return common.Error(c, common.CodeForbidden, "用户已被禁用")
+	default:
+		logger.NamedCtx(ctx, logger.ModuleAuth).Error("Email verification failed", zap.Error(err))
+		return common.Error(c, common.CodeInternalError, "验证失败")
+	}
+}
+
+// ResendVerification 重新发送验证邮件
+// @Summary      重新发送验证邮件
+// @Description  向尚未验证的注册邮箱重新发送验证链接（不延长验证期限）。为避免暴露账号是否存在，邮箱未注册时同样返回成功
+// @Tags         认证
+// @Accept       json
+// @Produce      json
+// @Param        request body ResendVerificationRequest true "注册邮箱"
+// @Success      200 {object} common.Response
+// @Failure      422 {object} common.Response
+// @Failure      429 {object} common.Response
+// @Router       /auth/verify/resend [post]
+func (h *Handler) ResendVerification(c echo.Context) error {
+	var req ResendVerificationRequest
+	if err := c.Bind(&req); err != nil {
+		return common.Error(c, common.CodeBadRequest, "请求参数解析失败")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return common.Error(c, common.CodeValidationError, h.translateValidationError(err))
+	}
+
+	ctx := c.Request().Context()
+	err := h.register.WithContext(ctx).Resend(req.Email)
+	switch {
+	case err == nil:
+		return common.SuccessWithMessage(c, "如果该邮箱已注册且尚未验证，验证邮件已重新发送", nil)
+	case errors.Is(err, auth.ErrTooManyVerifyRequests):
+		return common.Error(c, common.CodeTooManyRequests, "发送过于频繁，请稍后再试")
+	default:
+		logger.NamedCtx(ctx, logger.ModuleAuth).Error("Failed to resend verification email",
+			zap.String("ip", c.RealIP()),
+			zap.Error(err),
+		)
+		return common.Error(c, common.CodeInternalError, "发送失败")
+	}
+}
@@ -0,0 +1,426 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gosir/internal/logger"
+	"gosir/internal/metrics"
+	usermodel "gosir/internal/model/user"
+	"gosir/internal/service/audit"
+	authservice "gosir/internal/service/auth"
+	"gosir/internal/service/oauth"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	sessionCookieName = "gosir_oauth_session" // 授权页面的登录会话
+	csrfCookieName    = "gosir_oauth_csrf"    // 授权页面表单的 CSRF token（double submit）
+	cookiePath        = "/oauth"
+
+	// pageCSP 授权页面的 CSP：只允许内联样式，禁止脚本和被嵌入（防止点击劫持）
+	pageCSP = "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors 'none'"
+)
+
+// 授权页面表单的操作
+const (
+	actionLogin   = "login"   // 登录
+	actionApprove = "approve" // 同意授权
+	actionDeny    = "deny"    // 拒绝授权
+	actionSwitch  = "switch"  // 切换账号
+)
+
+// authorizeParams 授权请求参数，授权页面表单以隐藏字段原样提交
+var authorizeParams = []string{
+	"client_id", "redirect_uri", "response_type", "scope", "state",
+	"nonce", "code_challenge", "code_challenge_method", "prompt",
+}
+
+// scopeView 授权页面展示的 scope
+type scopeView struct {
+	Name        string
+	Description string
+}
+
+// pageData 授权页面数据
+type pageData struct {
+	Mode       string // login、consent 或 error
+	ClientName string
+	Scopes     []scopeView
+	User       *usermodel.User
+	Error      string
+	CSRFToken  string
+	Params     map[string]string
+}
+
+// Authorize 授权端点
+// @Summary      授权端点
+// @Description  授权码模式（必须使用 PKCE S256）的浏览器入口：未登录时展示登录页面，已登录时展示授权确认页面（第一方应用可跳过），完成后带 code 和 state 重定向到 redirect_uri
+// @Tags         OAuth
+// @Produce      html
+// @Param        client_id query string true "client_id"
+// @Param        redirect_uri query string false "回调地址（只登记了一个时可省略）"
+// @Param        response_type query string true "固定为 code" Enums(code)
+// @Param        scope query string true "申请的 scope（空格分隔）"
+// @Param        state query string false "state"
+// @Param        nonce query string false "ID Token nonce"
+// @Param        code_challenge query string true "PKCE code_challenge"
+// @Param        code_challenge_method query string true "固定为 S256" Enums(S256)
+// @Param        prompt query string false "none、login 或 consent"
+// @Success      200 {string} string "登录或授权确认页面"
+// @Success      302
+// @Failure      400 {string} string "错误页面"
+// @Router       /oauth/authorize [get]
+func (h *Handler) Authorize(c echo.Context) error {
+	params := readParams(c, c.QueryParam)
+	auth, err := h.server.WithContext(c.Request().Context()).ValidateAuthorize(authorizeRequest(params))
+	if err != nil {
+		return h.authorizeError(c, auth, err)
+	}
+
+	var userData *usermodel.User
+	if auth.Prompt != oauth.PromptLogin {
+		userData = h.sessionUser(c)
+	}
+	if userData == nil {
+		if auth.Prompt == oauth.PromptNone {
+			return h.redirectError(c, auth, oauth.ErrCodeLoginRequired)
+		}
+		return h.renderPage(c, http.StatusOK, pageData{Mode: "login"}, auth, params)
+	}
+	return h.consent(c, auth, params, userData)
+}
+
+// AuthorizeSubmit 授权页面表单提交
+// @Summary      授权页面表单提交
+// @Description  授权页面的登录、同意、拒绝和切换账号操作（需要 CSRF token）
+// @Tags         OAuth
+// @Accept       x-www-form-urlencoded
+// @Produce      html
+// @Param        action formData string true "操作" Enums(login, approve, deny, switch)
+// @Param        csrf_token formData string true "CSRF token"
+// @Param        account formData string false "账号（登录时）"
+// @Param        password formData string false "密码（登录时）"
+// @Success      200 {string} string "登录或授权确认页面"
+// @Success      302
+// @Failure      400 {string} string "错误页面"
+// @Router       /oauth/authorize [post]
+func (h *Handler) AuthorizeSubmit(c echo.Context) error {
+	req := c.Request()
+	if !h.validCSRF(c, req.PostFormValue("csrf_token")) {
+		return h.renderPage(c, http.StatusBadRequest, pageData{Mode: "error", Error: "页面已过期，请返回应用重新发起登录"}, nil, nil)
+	}
+
+	params := readParams(c, req.PostFormValue)
+	auth, err := h.server.WithContext(req.Context()).ValidateAuthorize(authorizeRequest(params))
+	if err != nil {
+		return h.authorizeError(c, auth, err)
+	}
+
+	switch req.PostFormValue("action") {
+	case actionLogin:
+		return h.login(c, auth, params)
+	case actionApprove:
+		userData := h.sessionUser(c)
+		if userData == nil {
+			return h.renderPage(c, http.StatusOK, pageData{Mode: "login", Error: "登录已过期，请重新登录"}, auth, params)
+		}
+		return h.issueCode(c, auth, userData)
+	case actionDeny:
+		return h.redirectError(c, auth, oauth.ErrCodeAccessDenied)
+	case actionSwitch:
+		h.setSession(c, "", -1)
+		return h.renderPage(c, http.StatusOK, pageData{Mode: "login"}, auth, params)
+	default:
+		return h.renderPage(c, http.StatusBadRequest, pageData{Mode: "error", Error: "无效的操作"}, nil, nil)
+	}
+}
+
+// login 授权页面登录，成功后保存登录会话并进入授权确认
+func (h *Handler) login(c echo.Context, auth *oauth.Authorization, params map[string]string) error {
+	ctx := c.Request().Context()
+	log := logger.NamedCtx(ctx, logger.ModuleAuth).With(
+		zap.String("client_id", auth.Client.ID),
+		zap.String("ip", c.RealIP()),
+	)
+
+	account := strings.TrimSpace(c.Request().PostFormValue("account"))
+	userData, err := h.loginService.WithContext(ctx).LoginByAccount(account, c.Request().PostFormValue("password"))
+	if err != nil {
+		metrics.LoginTotal.WithLabelValues(metrics.LoginFailure).Inc()
+		audit.Record(ctx, audit.Entry{
+			Action:     audit.ActionLoginFailed,
+			TargetType: audit.TargetAccount,
+			TargetID:   account,
+			Detail:     "oauth:" + auth.Client.ID + ": " + err.Error(),
+		})
+		log.Warn("OAuth login failed", zap.String("account", account), zap.Error(err))
+		switch {
+		case errors.Is(err, authservice.ErrUserNotVerified):
+			return h.renderPage(c, http.StatusOK, pageData{Mode: "login", Error: "邮箱尚未验证，请先点击验证邮件中的链接"}, auth, params)
+		case errors.Is(err, authservice.ErrUserDisabled):
+			return h.renderPage(c, http.StatusOK, pageData{Mode: "login", Error: "用户已被禁用"}, auth, params)
+		}
+		return h.renderPage(c, http.StatusOK, pageData{Mode: "login", Error: "账号或密码错误"}, auth, params)
+	}
+	if userData.MustChangePassword {
+		log.Warn("OAuth login rejected: password change required", zap.String("user_id", userData.ID))
+		return h.renderPage(c, http.StatusOK, pageData{Mode: "login", Error: "请先登录 gosir 修改初始密码后再授权"}, auth, params)
+	}
+
+	token, ttl, err := h.server.WithContext(ctx).CreateSession(userData.ID)
+	if err != nil {
+		log.Error("Failed to create OAuth session", zap.String("user_id", userData.ID), zap.Error(err))
+		return h.renderPage(c, http.StatusInternalServerError, pageData{Mode: "error", Error: "登录失败，请稍后重试"}, nil, nil)
+	}
+	h.setSession(c, token, int(ttl/time.Second))
+
+	metrics.LoginTotal.WithLabelValues(metrics.LoginSuccess).Inc()
+	audit.Record(ctx, audit.Entry{
+		ActorID:    userData.ID,
+		Action:     audit.ActionLogin,
+		TargetType: audit.TargetUser,
+		TargetID:   userData.ID,
+		Detail:     "oauth:" + auth.Client.ID,
+	})
+	log.Info("OAuth login succeeded", zap.String("user_id", userData.ID))
+
+	// 刚刚完成登录，prompt=login 已满足
+	if auth.Prompt == oauth.PromptLogin {
+		auth.Prompt = ""
+		params["prompt"] = ""
+	}
+	return h.consent(c, auth, params, userData)
+}
+
+// consent 已登录用户的授权确认，第一方应用除 prompt=consent 外直接签发授权码
+func (h *Handler) consent(c echo.Context, auth *oauth.Authorization, params map[string]string, userData *usermodel.User) error {
+	if auth.Client.SkipConsent && auth.Prompt != oauth.PromptConsent {
+		return h.issueCode(c, auth, userData)
+	}
+	if auth.Prompt == oauth.PromptNone {
+		return h.redirectError(c, auth, oauth.ErrCodeConsentRequired)
+	}
+	return h.renderPage(c, http.StatusOK, pageData{Mode: "consent", User: userData}, auth, params)
+}
+
+// issueCode 签发授权码并重定向回客户端
+func (h *Handler) issueCode(c echo.Context, auth *oauth.Authorization, userData *usermodel.User) error {
+	code, err := h.server.WithContext(c.Request().Context()).IssueCode(auth, userData.ID)
+	if err != nil {
+		logger.NamedCtx(c.Request().Context(), logger.ModuleAuth).Error("Failed to issue authorization code", zap.Error(err))
+		return h.renderPage(c, http.StatusInternalServerError, pageData{Mode: "error", Error: "授权失败，请稍后重试"}, nil, nil)
+	}
+
+	logger.NamedCtx(c.Request().Context(), logger.ModuleAuth).Info("OAuth authorization granted",
+		zap.String("client_id", auth.Client.ID),
+		zap.String("user_id", userData.ID),
+		zap.Strings("scopes", auth.Scopes),
+	)
+	return h.redirect(c, auth, url.Values{"code": {code}})
+}
+
+// authorizeError 授权请求错误：client_id 或 redirect_uri 无效时展示错误页面，否则重定向回客户端
+func (h *Handler) authorizeError(c echo.Context, auth *oauth.Authorization, err error) error {
+	var oauthErr *oauth.Error
+	if !errors.As(err, &oauthErr) {
+		logger.NamedCtx(c.Request().Context(), logger.ModuleAuth).Error("OAuth authorize failed", zap.Error(err))
+		return h.renderPage(c, http.StatusInternalServerError, pageData{Mode: "error", Error: "服务暂时不可用，请稍后重试"}, nil, nil)
+	}
+	if auth == nil {
+		return h.renderPage(c, http.StatusBadRequest, pageData{Mode: "error", Error: "无效的授权请求：应用不存在或回调地址未登记"}, nil, nil)
+	}
+	return h.redirect(c, auth, url.Values{
+		"error":             {oauthErr.Code},
+		"error_description": {oauthErr.Description},
+	})
+}
+
+// redirectError 重定向回客户端并返回错误码
+func (h *Handler) redirectError(c echo.Context, auth *oauth.Authorization, code string) error {
+	return h.redirect(c, auth, url.Values{"error": {code}})
+}
+
+// redirect 带上 state 重定向到客户端回调地址
+func (h *Handler) redirect(c echo.Context, auth *oauth.Authorization, values url.Values) error {
+	target, err := url.Parse(auth.RedirectURI)
+	if err != nil {
+		return h.renderPage(c, http.StatusBadRequest, pageData{Mode: "error", Error: "无效的回调地址"}, nil, nil)
+	}
+	query := target.Query()
+	for key, value := range values {
+		query[key] = value
+	}
+	if auth.State != "" {
+		query.Set("state", auth.State)
+	}
+	target.RawQuery = query.Encode()
+	return c.Redirect(http.StatusFound, target.String())
+}
+
+// sessionUser 读取授权页面的登录会话，无效时返回 nil
+func (h *Handler) sessionUser(c echo.Context) *usermodel.User {
+	cookie, err := c.Cookie(sessionCookieName)
+	if err != nil || cookie.Value == "" {
+		return nil
+	}
+	userData, err := h.server.WithContext(c.Request().Context()).SessionUser(cookie.Value)
+	if err != nil {
+		return nil
+	}
+	return userData
+}
+
+// setSession 设置授权页面的登录会话（只能用于授权页面的短期 token），maxAge 为 -1 时删除
+func (h *Handler) setSession(c echo.Context, token string, maxAge int) {
+	c.SetCookie(&http.Cookie{
+		Name:     sessionCookieName,
+		Value:    token,
+		Path:     cookiePath,
+		MaxAge:   maxAge,
+		Secure:   c.Scheme() == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// csrfToken 获取或生成表单的 CSRF token
+func (h *Handler) csrfToken(c echo.Context) string {
+	if cookie, err := c.Cookie(csrfCookieName); err == nil && len(cookie.Value) >= 32 {
+		return cookie.Value
+	}
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	token := base64.RawURLEncoding.EncodeToString(b)
+	c.SetCookie(&http.Cookie{
+		Name:     csrfCookieName,
+		Value:    token,
+		Path:     cookiePath,
+		Secure:   c.Scheme() == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return token
+}
+
+// validCSRF 校验表单提交的 CSRF token 与 cookie 一致
+func (h *Handler) validCSRF(c echo.Context, token string) bool {
+	cookie, err := c.Cookie(csrfCookieName)
+	if err != nil || cookie.Value == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(token)) == 1
+}
+
+// renderPage 渲染授权页面
+func (h *Handler) renderPage(c echo.Context, status int, data pageData, auth *oauth.Authorization, params map[string]string) error {
+	if auth != nil {
+		data.ClientName = auth.Client.Name
+		for _, scope := range auth.Scopes {
+			data.Scopes = append(data.Scopes, scopeView{Name: scope, Description: oauth.ScopeDescriptions[scope]})
+		}
+		data.Params = params
+		data.CSRFToken = h.csrfToken(c)
+	}
+
+	header := c.Response().Header()
+	header.Set("Content-Security-Policy", pageCSP)
+	header.Set("X-Frame-Options", "DENY")
+	header.Set("Cache-Control", "no-store")
+	header.Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+	c.Response().WriteHeader(status)
+	return pageTemplate.Execute(c.Response(), data)
+}
+
+// readParams 读取授权请求参数
+func readParams(c echo.Context, get func(string) string) map[string]string {
+	params := make(map[string]string, len(authorizeParams))
+	for _, name := range authorizeParams {
+		params[name] = get(name)
+	}
+	return params
+}
+
+// authorizeRequest 将授权请求参数转换为服务层请求
+func authorizeRequest(params map[string]string) oauth.AuthorizeRequest {
+	return oauth.AuthorizeRequest{
+		ClientID:            params["client_id"],
+		RedirectURI:         params["redirect_uri"],
+		ResponseType:        params["response_type"],
+		Scope:               params["scope"],
+		State:               params["state"],
+		Nonce:               params["nonce"],
+		CodeChallenge:       params["code_challenge"],
+		CodeChallengeMethod: params["code_challenge_method"],
+		Prompt:              params["prompt"],
+	}
+}
+
+// pageTemplate 授权页面模板（不使用脚本，CSP 禁止脚本执行）
+var pageTemplate = template.Must(template.New("authorize").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{if eq .Mode "error"}}授权失败{{else if eq .Mode "login"}}登录{{else}}授权确认{{end}} - gosir</title>
+<style>
+body{margin:0;font-family:-apple-system,"Segoe UI","PingFang SC","Microsoft YaHei",sans-serif;background:#f5f6f8;color:#1f2328}
+main{max-width:380px;margin:64px auto;padding:32px;background:#fff;border-radius:8px;box-shadow:0 1px 3px rgba(0,0,0,.12)}
+h1{font-size:20px;margin:0 0 16px}
+p{line-height:1.6}
+label{display:block;margin:12px 0 4px;font-size:14px}
+input[type=text],input[type=password]{width:100%;box-sizing:border-box;padding:8px;border:1px solid #d0d7de;border-radius:6px;font-size:14px}
+button{padding:8px 16px;border:1px solid #d0d7de;border-radius:6px;background:#f6f8fa;font-size:14px;cursor:pointer}
+button.primary{background:#1f6feb;border-color:#1f6feb;color:#fff}
+.actions{display:flex;gap:8px;margin-top:20px}
+.error{padding:8px 12px;border-radius:6px;background:#ffebe9;color:#cf222e;font-size:14px}
+.link{border:none;background:none;color:#0969da;padding:0}
+ul{padding-left:20px}
+small{color:#656d76}
+</style>
+</head>
+<body>
+<main>
+{{if eq .Mode "error"}}
+<h1>授权失败</h1>
+<p class="error">{{.Error}}</p>
+{{else}}
+<form method="post" action="/oauth/authorize">
+<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+{{range $name, $value := .Params}}<input type="hidden" name="{{$name}}" value="{{$value}}">
+{{end}}
+{{if eq .Mode "login"}}
+<h1>登录以继续使用 {{.ClientName}}</h1>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<label for="account">账号</label>
+<input type="text" id="account" name="account" autocomplete="username" placeholder="邮箱或手机号" required autofocus>
+<label for="password">密码</label>
+<input type="password" id="password" name="password" autocomplete="current-password" required>
+<div class="actions"><button class="primary" type="submit" name="action" value="login">登录</button></div>
+{{else}}
+<h1>{{.ClientName}} 请求访问你的账号</h1>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<p>当前账号：{{.User.Name}} <small>{{.User.Email}}</small>
+<button class="link" type="submit" name="action" value="switch" formnovalidate>切换账号</button></p>
+<p>授权后该应用将可以：</p>
+<ul>{{range .Scopes}}<li>{{if .Description}}{{.Description}}{{else}}{{.Name}}{{end}} <small>{{.Name}}</small></li>{{end}}</ul>
+<div class="actions">
+<button class="primary" type="submit" name="action" value="approve">授权</button>
+<button type="submit" name="action" value="deny">拒绝</button>
+</div>
+{{end}}
+</form>
+{{end}}
+</main>
+</body>
+</html>
+`))
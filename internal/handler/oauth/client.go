@@ -0,0 +1,238 @@
+package oauth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gosir/internal/common"
+	oauthmodel "gosir/internal/model/oauth"
+	"gosir/internal/service/oauth"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// ClientRequest 创建或修改 OAuth 客户端请求
+type ClientRequest struct {
+	Name         string   `json:"name" validate:"required,max=100" example:"工单系统"`                                                                                   // 名称，展示在授权页面
+	RedirectURIs []string `json:"redirect_uris" validate:"max=10,dive,required,max=500" example:"https://app.example.com/callback"`                                  // 允许的回调地址（精确匹配），授权码模式必填
+	GrantTypes   []string `json:"grant_types" validate:"required,min=1,dive,oneof=authorization_code refresh_token client_credentials" example:"authorization_code"` // 允许的授权类型
+	Scopes       []string `json:"scopes" validate:"required,min=1,dive,oneof=openid profile email users:read users:write admin" example:"openid"`                    // 允许申请的 scope
+	SkipConsent  bool     `json:"skip_consent" example:"false"`                                                                                                      // 第一方应用，已登录时跳过授权确认
+}
+
+// CreateClientRequest 创建 OAuth 客户端请求
+type CreateClientRequest struct {
+	ClientRequest
+	Public bool `json:"public" example:"false"` // 公开客户端（浏览器和移动应用，没有 client_secret，创建后不能修改）
+}
+
+// ClientSecretResponse 包含 client_secret 的客户端响应
+type ClientSecretResponse struct {
+	oauthmodel.Client
+	ClientSecret string `json:"client_secret,omitempty" example:"Zx8vQ2mN..."` // client_secret，只在创建和重新生成时返回一次
+}
+
+// ListClients 获取 OAuth 客户端列表
+// @Summary      获取 OAuth 客户端列表
+// @Description  获取所有已登记的 OAuth 客户端（不包含 client_secret）
+// @Tags         OAuth 客户端
+// @Produce      json
+// @Security     Bearer
+// @Success      200 {object} common.Response{data=[]oauthmodel.Client}
+// @Failure      401 {object} common.Response
+// @Failure      403 {object} common.Response
+// @Router       /api/admin/oauth/clients [get]
+func (h *Handler) ListClients(c echo.Context) error {
+	clients, err := h.clientService.WithContext(c.Request().Context()).List()
+	if err != nil {
+		return common.Error(c, common.CodeInternalError, "获取客户端列表失败")
+	}
+	return common.Success(c, clients)
+}
+
+// CreateClient 创建 OAuth 客户端
+// @Summary      创建 OAuth 客户端
+// @Description  登记通过 gosir 登录的应用，机密客户端的 client_secret 只在响应中返回一次
+// @Tags         OAuth 客户端
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body CreateClientRequest true "客户端信息"
+// @Success      201 {object} common.Response{data=ClientSecretResponse}
+// @Failure      400 {object} common.Response
+// @Failure      422 {object} common.Response
+// @Router       /api/admin/oauth/clients [post]
+func (h *Handler) CreateClient(c echo.Context) error {
+	var req CreateClientRequest
+	if err := c.Bind(&req); err != nil {
+		return common.Error(c, common.CodeBadRequest, "请求参数解析失败")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return common.Error(c, common.CodeValidationError, h.translateValidationError(err))
+	}
+
+	client, secret, err := h.clientService.WithContext(c.Request().Context()).Create(clientRequest(req.ClientRequest), req.Public)
+	if err != nil {
+		return h.clientError(c, err, "创建客户端失败")
+	}
+	return common.Created(c, ClientSecretResponse{Client: *client, ClientSecret: secret})
+}
+
+// GetClient 获取 OAuth 客户端详情
+// @Summary      获取 OAuth 客户端详情
+// @Description  获取 OAuth 客户端详情（不包含 client_secret）
+// @Tags         OAuth 客户端
+// @Produce      json
+// @Security     Bearer
+// @Param        id path string true "client_id"
+// @Success      200 {object} common.Response{data=oauthmodel.Client}
+// @Failure      404 {object} common.Response
+// @Router       /api/admin/oauth/clients/{id} [get]
+func (h *Handler) GetClient(c echo.Context) error {
+	client, err := h.clientService.WithContext(c.Request().Context()).Get(c.Param("id"))
+	if err != nil {
+		return h.clientError(c, err, "获取客户端失败")
+	}
+	return common.Success(c, client)
+}
+
+// UpdateClient 修改 OAuth 客户端
+// @Summary      修改 OAuth 客户端
+// @Description  修改客户端的名称、回调地址、授权类型、scope 和是否跳过授权确认，client_id 和 client_secret 不变
+// @Tags         OAuth 客户端
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id path string true "client_id"
+// @Param        request body ClientRequest true "客户端信息"
+// @Success      200 {object} common.Response{data=oauthmodel.Client}
+// @Failure      404 {object} common.Response
+// @Failure      422 {object} common.Response
+// @Router       /api/admin/oauth/clients/{id} [put]
+func (h *Handler) UpdateClient(c echo.Context) error {
+	var req ClientRequest
+	if err := c.Bind(&req); err != nil {
+		return common.Error(c, common.CodeBadRequest, "请求参数解析失败")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return common.Error(c, common.CodeValidationError, h.translateValidationError(err))
+	}
+
+	client, err := h.clientService.WithContext(c.Request().Context()).Update(c.Param("id"), clientRequest(req))
+	if err != nil {
+		return h.clientError(c, err, "修改客户端失败")
+	}
+	return common.Success(c, client)
+}
+
+// RotateClientSecret 重新生成 client_secret
+// @Summary      重新生成 client_secret
+// @Description  重新生成机密客户端的 client_secret，旧密钥立即失效，新密钥只在响应中返回一次
+// @Tags         OAuth 客户端
+// @Produce      json
+// @Security     Bearer
+// @Param        id path string true "client_id"
+// @Success      200 {object} common.Response{data=ClientSecretResponse}
+// @Failure      400 {object} common.Response
+// @Failure      404 {object} common.Response
+// @Router       /api/admin/oauth/clients/{id}/secret [post]
+func (h *Handler) RotateClientSecret(c echo.Context) error {
+	client, secret, err := h.clientService.WithContext(c.Request().Context()).RotateSecret(c.Param("id"))
+	if err != nil {
+		return h.clientError(c, err, "重新生成密钥失败")
+	}
+	return common.Success(c, ClientSecretResponse{Client: *client, ClientSecret: secret})
+}
+
+// DeleteClient 删除 OAuth 客户端
+// @Summary      删除 OAuth 客户端
+// @Description  删除客户端，已签发的刷新令牌同时失效（访问令牌到期前仍然有效）
+// @Tags         OAuth 客户端
+// @Produce      json
+// @Security     Bearer
+// @Param        id path string true "client_id"
+// @Success      200 {object} common.Response
+// @Failure      404 {object} common.Response
+// @Router       /api/admin/oauth/clients/{id} [delete]
+func (h *Handler) DeleteClient(c echo.Context) error {
+	if err := h.clientService.WithContext(c.Request().Context()).Delete(c.Param("id")); err != nil {
+		return h.clientError(c, err, "删除客户端失败")
+	}
+	return common.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// clientRequest 将请求转换为服务层参数
+func clientRequest(req ClientRequest) oauth.ClientRequest {
+	return oauth.ClientRequest{
+		Name:         req.Name,
+		RedirectURIs: req.RedirectURIs,
+		GrantTypes:   req.GrantTypes,
+		Scopes:       req.Scopes,
+		SkipConsent:  req.SkipConsent,
+	}
+}
+
+// clientError 将服务层错误转换为响应
+func (h *Handler) clientError(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, oauth.ErrClientNotFound):
+		return common.Error(c, common.CodeNotFound, "客户端不存在")
+	case errors.Is(err, oauth.ErrInvalidRedirectURI):
+		return common.Error(c, common.CodeValidationError, "回调地址必须是不含 fragment 的绝对地址")
+	case errors.Is(err, oauth.ErrRedirectURIRequired):
+		return common.Error(c, common.CodeValidationError, "授权码模式必须登记回调地址")
+	case errors.Is(err, oauth.ErrInvalidGrantType):
+		return common.Error(c, common.CodeValidationError, "无效的授权类型")
+	case errors.Is(err, oauth.ErrPublicClientCredentials):
+		return common.Error(c, common.CodeBadRequest, "公开客户端不能使用客户端凭证")
+	case errors.Is(err, oauth.ErrInvalidScope):
+		return common.Error(c, common.CodeValidationError, "无效的 scope")
+	default:
+		return common.ErrorWithDetail(c, common.CodeInternalError, fallback, err)
+	}
+}
+
+// 中文错误消息映射
+var fieldNames = map[string]string{
+	"Name":         "名称",
+	"RedirectURIs": "回调地址",
+	"GrantTypes":   "授权类型",
+	"Scopes":       "scope",
+}
+
+// translateValidationError 翻译验证错误
+func (h *Handler) translateValidationError(err error) string {
+	var fieldMessages []string
+
+	for _, e := range err.(validator.ValidationErrors) {
+		// 列表元素的字段名为 Scopes[0]
+		fieldName, _, _ := strings.Cut(e.Field(), "[")
+		chineseField := fieldNames[fieldName]
+		if chineseField == "" {
+			chineseField = fieldName
+		}
+
+		var errorMsg string
+		switch e.Tag() {
+		case "required":
+			errorMsg = fmt.Sprintf("%s不能为空", chineseField)
+		case "min":
+			errorMsg = fmt.Sprintf("%s至少需要%s项", chineseField, e.Param())
+		case "max":
+			if e.Kind().String() == "slice" {
+				errorMsg = fmt.Sprintf("%s最多%s项", chineseField, e.Param())
+			} else {
+				errorMsg = fmt.Sprintf("%s长度不能超过%s个字符", chineseField, e.Param())
+			}
+		case "oneof":
+			errorMsg = fmt.Sprintf("%s只能是 %s", chineseField, e.Param())
+		default:
+			errorMsg = fmt.Sprintf("%s验证失败: %s", chineseField, e.Tag())
+		}
+		fieldMessages = append(fieldMessages, errorMsg)
+	}
+
+	return strings.Join(fieldMessages, "；")
+}
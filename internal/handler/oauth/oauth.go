@@ -0,0 +1,201 @@
+package oauth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gosir/internal/logger"
+	"gosir/internal/service/auth"
+	"gosir/internal/service/oauth"
+
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	zhtranslations "github.com/go-playground/validator/v10/translations/zh"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	server        *oauth.Server
+	clientService *oauth.ClientService
+	loginService  *auth.LoginService
+	validator     *validator.Validate
+	translator    ut.Translator
+}
+
+// New 创建 OAuth 授权服务处理器
+func New() *Handler {
+	validate := validator.New()
+
+	// 获取中文翻译器
+	zhLocale := zh.New()
+	uni := ut.New(zhLocale, zhLocale)
+	translator, ok := uni.GetTranslator("zh")
+	if !ok {
+		panic("failed to get chinese translator")
+	}
+
+	// 注册默认翻译
+	if err := zhtranslations.RegisterDefaultTranslations(validate, translator); err != nil {
+		panic(fmt.Sprintf("failed to register translations: %v", err))
+	}
+
+	return &Handler{
+		server:        oauth.NewServer(),
+		clientService: oauth.NewClientService(),
+		loginService:  auth.NewLoginService(),
+		validator:     validate,
+		translator:    translator,
+	}
+}
+
+// ErrorResponse OAuth 协议错误响应（RFC 6749 5.2）
+type ErrorResponse struct {
+	Error            string `json:"error" example:"invalid_grant"`                      // 错误码
+	ErrorDescription string `json:"error_description,omitempty" example:"code expired"` // 错误说明
+}
+
+// Discovery 授权服务元数据
+// @Summary      OIDC Discovery
+// @Description  授权服务元数据（OpenID Connect Discovery 和 RFC 8414），也可通过 /.well-known/oauth-authorization-server 获取
+// @Tags         OAuth
+// @Produce      json
+// @Success      200 {object} map[string]interface{}
+// @Router       /.well-known/openid-configuration [get]
+func (h *Handler) Discovery(c echo.Context) error {
+	metadata, err := oauth.Metadata()
+	if err != nil {
+		return h.protocolError(c, err)
+	}
+	return c.JSON(http.StatusOK, metadata)
+}
+
+// JWKS ID Token 签名公钥
+// @Summary      JWKS
+// @Description  ID Token 签名公钥（JSON Web Key Set）
+// @Tags         OAuth
+// @Produce      json
+// @Success      200 {object} map[string]interface{}
+// @Router       /oauth/jwks [get]
+func (h *Handler) JWKS(c echo.Context) error {
+	keys, err := oauth.JWKS()
+	if err != nil {
+		return h.protocolError(c, err)
+	}
+	return c.JSON(http.StatusOK, keys)
+}
+
+// Token 令牌端点
+// @Summary      令牌端点
+// @Description  使用授权码（必须带 PKCE code_verifier）、刷新令牌或客户端凭证换取访问令牌；机密客户端通过 HTTP Basic 或表单参数提供 client_secret，公开客户端只提供 client_id
+// @Tags         OAuth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        grant_type formData string true "授权类型" Enums(authorization_code, refresh_token, client_credentials)
+// @Param        client_id formData string false "client_id（未使用 HTTP Basic 时必填）"
+// @Param        client_secret formData string false "client_secret"
+// @Param        code formData string false "授权码"
+// @Param        redirect_uri formData string false "授权请求中的回调地址"
+// @Param        code_verifier formData string false "PKCE code_verifier"
+// @Param        refresh_token formData string false "刷新令牌"
+// @Param        scope formData string false "申请的 scope（空格分隔）"
+// @Success      200 {object} oauth.TokenResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Router       /oauth/token [post]
+func (h *Handler) Token(c echo.Context) error {
+	noStore(c)
+	req := c.Request()
+
+	clientID, clientSecret := req.PostFormValue("client_id"), req.PostFormValue("client_secret")
+	if user, pass, ok := req.BasicAuth(); ok {
+		// RFC 6749 2.3.1：Basic 认证的用户名和密码先进行表单编码
+		if clientSecret != "" {
+			return h.protocolError(c, &oauth.Error{Code: oauth.ErrCodeInvalidRequest, Description: "multiple client authentication methods"})
+		}
+		var err1, err2 error
+		clientID, err1 = url.QueryUnescape(user)
+		clientSecret, err2 = url.QueryUnescape(pass)
+		if err1 != nil || err2 != nil {
+			return h.protocolError(c, &oauth.Error{Code: oauth.ErrCodeInvalidClient, Description: "malformed client credentials"})
+		}
+	}
+
+	resp, err := h.server.WithContext(req.Context()).Token(oauth.TokenRequest{
+		GrantType:    req.PostFormValue("grant_type"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Code:         req.PostFormValue("code"),
+		RedirectURI:  req.PostFormValue("redirect_uri"),
+		CodeVerifier: req.PostFormValue("code_verifier"),
+		RefreshToken: req.PostFormValue("refresh_token"),
+		Scope:        req.PostFormValue("scope"),
+	})
+	if err != nil {
+		logger.NamedCtx(req.Context(), logger.ModuleAuth).Warn("OAuth token request failed",
+			zap.String("client_id", clientID),
+			zap.String("grant_type", req.PostFormValue("grant_type")),
+			zap.Error(err),
+		)
+		return h.protocolError(c, err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// UserInfo 用户信息端点
+// @Summary      用户信息
+// @Description  返回访问令牌对应用户的 OIDC 声明，访问令牌必须包含 openid scope，profile 和 email scope 决定返回的字段
+// @Tags         OAuth
+// @Produce      json
+// @Security     Bearer
+// @Success      200 {object} map[string]interface{}
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Router       /oauth/userinfo [get]
+func (h *Handler) UserInfo(c echo.Context) error {
+	noStore(c)
+	token, ok := strings.CutPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		c.Response().Header().Set("WWW-Authenticate", `Bearer`)
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: oauth.ErrCodeInvalidToken, ErrorDescription: "bearer token required"})
+	}
+
+	info, err := h.server.WithContext(c.Request().Context()).UserInfo(token)
+	if err != nil {
+		return h.protocolError(c, err)
+	}
+	return c.JSON(http.StatusOK, info)
+}
+
+// protocolError 按 OAuth 协议格式返回错误
+func (h *Handler) protocolError(c echo.Context, err error) error {
+	var oauthErr *oauth.Error
+	if !errors.As(err, &oauthErr) {
+		logger.NamedCtx(c.Request().Context(), logger.ModuleAuth).Error("OAuth request failed", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "server_error"})
+	}
+
+	status := http.StatusBadRequest
+	switch oauthErr.Code {
+	case oauth.ErrCodeInvalidClient:
+		status = http.StatusUnauthorized
+		c.Response().Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	case oauth.ErrCodeInvalidToken:
+		status = http.StatusUnauthorized
+		c.Response().Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error=%q, error_description=%q`, oauthErr.Code, oauthErr.Description))
+	case oauth.ErrCodeInsufficientScope:
+		status = http.StatusForbidden
+		c.Response().Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error=%q, scope="openid"`, oauthErr.Code))
+	}
+	return c.JSON(status, ErrorResponse{Error: oauthErr.Code, ErrorDescription: oauthErr.Description})
+}
+
+// noStore 令牌相关响应禁止缓存（RFC 6749 5.1）
+func noStore(c echo.Context) {
+	c.Response().Header().Set("Cache-Control", "no-store")
+	c.Response().Header().Set("Pragma", "no-cache")
+}
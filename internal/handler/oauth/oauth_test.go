@@ -0,0 +1,315 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gosir/internal/common"
+	"gosir/internal/oidc"
+	"gosir/internal/service/apikey"
+	"gosir/internal/service/oauth"
+	"gosir/internal/service/user"
+	"gosir/internal/testutil"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/oauth2"
+)
+
+const testRedirectURL = "https://app.example.com/callback"
+
+// testEnv 运行授权服务的 HTTP 服务器、已登录授权页面的用户和第一方客户端
+type testEnv struct {
+	srv     *httptest.Server
+	userID  string
+	session string // 授权页面的登录会话 token
+	config  *oauth2.Config
+}
+
+// setup 启动授权服务，创建用户、登录会话和跳过授权确认的机密客户端
+func setup(t *testing.T) *testEnv {
+	t.Helper()
+	// 签发者是服务器地址，先启动服务器再初始化授权服务；处理器创建时绑定数据库，在初始化数据库后注册路由
+	e := echo.New()
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+	testutil.SetupOAuth(t, srv.URL)
+
+	h := New()
+	e.GET("/.well-known/openid-configuration", h.Discovery)
+	e.GET("/oauth/jwks", h.JWKS)
+	e.GET("/oauth/authorize", h.Authorize)
+	e.POST("/oauth/token", h.Token)
+
+	u, err := user.NewUserService().CreateUser(&user.CreateUserRequest{
+		Name:     "alice",
+		Email:    "alice@example.com",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+	session, _, err := oauth.NewServer().CreateSession(u.ID)
+	if err != nil {
+		t.Fatalf("CreateSession() error: %v", err)
+	}
+
+	scopes := []string{oauth.ScopeOpenID, oauth.ScopeEmail, apikey.ScopeUsersRead}
+	client, secret, err := oauth.NewClientService().Create(oauth.ClientRequest{
+		Name:         "app",
+		RedirectURIs: []string{testRedirectURL, "https://app.example.com/other"},
+		GrantTypes:   []string{oauth.GrantAuthorizationCode, oauth.GrantRefreshToken},
+		Scopes:       scopes,
+		SkipConsent:  true,
+	}, false)
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+
+	return &testEnv{
+		srv:     srv,
+		userID:  u.ID,
+		session: session,
+		config: &oauth2.Config{
+			ClientID:     client.ID,
+			ClientSecret: secret,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:   srv.URL + "/oauth/authorize",
+				TokenURL:  srv.URL + "/oauth/token",
+				AuthStyle: oauth2.AuthStyleInHeader,
+			},
+			RedirectURL: testRedirectURL,
+			Scopes:      scopes,
+		},
+	}
+}
+
+// authorize 带登录会话访问授权地址，返回重定向回客户端时的授权码
+func (env *testEnv) authorize(t *testing.T, authURL string) string {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, authURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: env.session})
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d, want %d", resp.StatusCode, http.StatusFound)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := location.Query()
+	if got := location.Scheme + "://" + location.Host + location.Path; got != testRedirectURL {
+		t.Fatalf("redirected to %s, want %s", got, testRedirectURL)
+	}
+	if q.Get("error") != "" || q.Get("state") != "state-1" || q.Get("code") == "" {
+		t.Fatalf("callback query = %v", q)
+	}
+	return q.Get("code")
+}
+
+// assertRetrieveError 断言令牌端点按协议返回了指定的 HTTP 状态和错误码
+func assertRetrieveError(t *testing.T, err error, status int, code string) {
+	t.Helper()
+	var retrieveErr *oauth2.RetrieveError
+	if !errors.As(err, &retrieveErr) {
+		t.Fatalf("error = %v, want token endpoint error %s", err, code)
+	}
+	if retrieveErr.Response.StatusCode != status || retrieveErr.ErrorCode != code {
+		t.Errorf("token endpoint = %d %s (%s), want %d %s",
+			retrieveErr.Response.StatusCode, retrieveErr.ErrorCode, retrieveErr.ErrorDescription, status, code)
+	}
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	tests := []struct {
+		name      string
+		authStyle oauth2.AuthStyle
+	}{
+		{name: "client_secret_basic", authStyle: oauth2.AuthStyleInHeader},
+		{name: "client_secret_post", authStyle: oauth2.AuthStyleInParams},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setup(t)
+			env.config.Endpoint.AuthStyle = tt.authStyle
+			ctx := context.Background()
+
+			verifier := oauth2.GenerateVerifier()
+			code := env.authorize(t, env.config.AuthCodeURL("state-1",
+				oauth2.S256ChallengeOption(verifier),
+				oauth2.SetAuthURLParam("nonce", "nonce-1"),
+			))
+			token, err := env.config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+			if err != nil {
+				t.Fatalf("Exchange() error: %v", err)
+			}
+			if token.TokenType != "Bearer" || token.RefreshToken == "" || token.Expiry.IsZero() {
+				t.Fatalf("token = %+v, want bearer token with refresh token and expiry", token)
+			}
+
+			claims, err := common.GetJWTManager().ValidateToken(token.AccessToken)
+			if err != nil {
+				t.Fatalf("ValidateToken() error: %v", err)
+			}
+			if claims.UserID != env.userID || claims.ClientID != env.config.ClientID {
+				t.Errorf("access token user = %s, client = %s", claims.UserID, claims.ClientID)
+			}
+			if scopes := claims.Scopes(); !slices.Equal(scopes, []string{"email", "openid", "users:read"}) {
+				t.Errorf("access token scopes = %v", scopes)
+			}
+
+			// ID Token 按标准 OIDC 客户端的方式校验：签名（JWKS）、iss、aud、exp 和 nonce
+			idToken, _ := token.Extra("id_token").(string)
+			provider := oidc.NewProvider("gosir", oidc.Config{
+				Issuer:       env.srv.URL,
+				ClientID:     env.config.ClientID,
+				ClientSecret: env.config.ClientSecret,
+				RedirectURL:  testRedirectURL,
+			})
+			idClaims, err := provider.VerifyIDToken(ctx, idToken, "nonce-1")
+			if err != nil {
+				t.Fatalf("VerifyIDToken() error: %v", err)
+			}
+			if idClaims.Subject != env.userID || idClaims.Email != "alice@example.com" {
+				t.Errorf("id token claims = %+v", idClaims)
+			}
+
+			// 访问令牌过期后 TokenSource 使用刷新令牌换取新令牌，刷新令牌同时轮换
+			expired := *token
+			expired.Expiry = time.Now().Add(-time.Minute)
+			refreshed, err := env.config.TokenSource(ctx, &expired).Token()
+			if err != nil {
+				t.Fatalf("refresh error: %v", err)
+			}
+			if refreshed.AccessToken == token.AccessToken || refreshed.RefreshToken == token.RefreshToken {
+				t.Error("refresh did not issue new access and refresh tokens")
+			}
+
+			// 旧的刷新令牌再次使用被拒绝，并吊销轮换后的令牌
+			_, err = env.config.TokenSource(ctx, &expired).Token()
+			assertRetrieveError(t, err, http.StatusBadRequest, oauth.ErrCodeInvalidGrant)
+			refreshed.Expiry = time.Now().Add(-time.Minute)
+			_, err = env.config.TokenSource(ctx, refreshed).Token()
+			assertRetrieveError(t, err, http.StatusBadRequest, oauth.ErrCodeInvalidGrant)
+		})
+	}
+}
+
+func TestTokenExchangeRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		exchange func(ctx context.Context, cfg *oauth2.Config, code, verifier string) error
+		status   int
+		code     string
+	}{
+		{
+			name: "code_verifier 不匹配",
+			exchange: func(ctx context.Context, cfg *oauth2.Config, code, _ string) error {
+				_, err := cfg.Exchange(ctx, code, oauth2.VerifierOption(oauth2.GenerateVerifier()))
+				return err
+			},
+			status: http.StatusBadRequest,
+			code:   oauth.ErrCodeInvalidGrant,
+		},
+		{
+			name: "缺少 code_verifier",
+			exchange: func(ctx context.Context, cfg *oauth2.Config, code, _ string) error {
+				_, err := cfg.Exchange(ctx, code)
+				return err
+			},
+			status: http.StatusBadRequest,
+			code:   oauth.ErrCodeInvalidRequest,
+		},
+		{
+			name: "redirect_uri 与授权请求不一致",
+			exchange: func(ctx context.Context, cfg *oauth2.Config, code, verifier string) error {
+				other := *cfg
+				other.RedirectURL = "https://app.example.com/other"
+				_, err := other.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+				return err
+			},
+			status: http.StatusBadRequest,
+			code:   oauth.ErrCodeInvalidGrant,
+		},
+		{
+			name: "client_secret 错误",
+			exchange: func(ctx context.Context, cfg *oauth2.Config, code, verifier string) error {
+				other := *cfg
+				other.ClientSecret = "wrong"
+				_, err := other.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+				return err
+			},
+			status: http.StatusUnauthorized,
+			code:   oauth.ErrCodeInvalidClient,
+		},
+		{
+			name: "授权码重复使用",
+			exchange: func(ctx context.Context, cfg *oauth2.Config, code, verifier string) error {
+				if _, err := cfg.Exchange(ctx, code, oauth2.VerifierOption(verifier)); err != nil {
+					return err
+				}
+				_, err := cfg.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+				return err
+			},
+			status: http.StatusBadRequest,
+			code:   oauth.ErrCodeInvalidGrant,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setup(t)
+			verifier := oauth2.GenerateVerifier()
+			code := env.authorize(t, env.config.AuthCodeURL("state-1", oauth2.S256ChallengeOption(verifier)))
+
+			err := tt.exchange(context.Background(), env.config, code, verifier)
+			assertRetrieveError(t, err, tt.status, tt.code)
+		})
+	}
+}
+
+func TestAuthorizeRejectsUnregisteredRedirectURI(t *testing.T) {
+	env := setup(t)
+	cfg := *env.config
+	cfg.RedirectURL = testRedirectURL + "/evil"
+
+	req, err := http.NewRequest(http.MethodGet, cfg.AuthCodeURL("state-1", oauth2.S256ChallengeOption(oauth2.GenerateVerifier())), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: env.session})
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// 回调地址未登记时展示错误页面，不能带授权码或错误信息重定向到该地址
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+	if location := resp.Header.Get("Location"); strings.Contains(location, "evil") {
+		t.Errorf("redirected to unregistered redirect_uri %s", location)
+	}
+}
@@ -1,44 +1,140 @@
 package handler
 
 import (
+	apikeyhandler "gosir/internal/handler/apikey"
 	"gosir/internal/handler/auth"
+	oauthhandler "gosir/internal/handler/oauth"
 	"gosir/internal/handler/system"
 	userhandler "gosir/internal/handler/user"
+	"gosir/internal/middleware"
+	"gosir/internal/service/apikey"
+	authservice "gosir/internal/service/auth"
+	"gosir/internal/service/oauth"
 	"gosir/internal/service/user"
 
 	"github.com/labstack/echo/v4"
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
+// RouteOptions 路由选项
+type RouteOptions struct {
+	Swagger   bool // 注册 Swagger 文档路由
+	TestClock bool // 注册测试时钟路由（仅 test 模式）
+
+	MetricsAuth echo.MiddlewareFunc // /metrics 的认证中间件，为空时不注册 /metrics
+
+	AuthMiddlewares []echo.MiddlewareFunc // 认证路由（/auth/*）的中间件，如限流
+}
+
 // SetupPublicRoutes 设置公开路由（无需鉴权）
-func SetupPublicRoutes(e *echo.Echo) {
+func SetupPublicRoutes(e *echo.Echo, opts RouteOptions) {
 	userService := user.NewUserService()
 	authHandler := auth.New(userService)
 
 	// 系统路由
 	e.GET("/health", system.HealthCheck)
+	e.GET("/health/live", system.Liveness)
+	e.GET("/health/ready", system.Readiness)
+	if opts.MetricsAuth != nil {
+		e.GET("/metrics", system.Metrics, opts.MetricsAuth)
+	}
 
 	// Swagger 文档路由
-	e.GET("/swagger/*", echoSwagger.WrapHandler)
+	if opts.Swagger {
+		e.GET("/swagger/*", echoSwagger.WrapHandler)
+	}
+
+	// 测试时钟路由
+	if opts.TestClock {
+		e.GET("/test/clock", system.GetClock)
+		e.PUT("/test/clock", system.SetClock)
+	}
 
 	// 认证路由
-	e.POST("/auth/login", authHandler.Login)
+	authGroup := e.Group("/auth", opts.AuthMiddlewares...)
+	authGroup.POST("/login", authHandler.Login)
+	authGroup.GET("/oidc/providers", authHandler.ListOIDCProviders)
+	authGroup.GET("/oidc/:provider/login", authHandler.OIDCLogin)
+	authGroup.GET("/oidc/:provider/callback", authHandler.OIDCCallback)
+	if authservice.PasswordlessEnabled() {
+		authGroup.POST("/passwordless/start", authHandler.PasswordlessStart)
+		authGroup.POST("/passwordless/verify", authHandler.PasswordlessVerify)
+	}
+	if authservice.RegisterEnabled() {
+		authGroup.POST("/register", authHandler.Register)
+		authGroup.GET("/verify", authHandler.VerifyEmail)
+		authGroup.POST("/verify/resend", authHandler.ResendVerification)
+	}
+
+	// OAuth 授权服务路由（开启授权服务时注册）
+	if oauth.Enabled() {
+		oauthHandler := oauthhandler.New()
+		e.GET("/.well-known/openid-configuration", oauthHandler.Discovery)
+		e.GET("/.well-known/oauth-authorization-server", oauthHandler.Discovery)
+
+		oauthGroup := e.Group("/oauth")
+		oauthGroup.GET("/jwks", oauthHandler.JWKS)
+		oauthGroup.GET("/userinfo", oauthHandler.UserInfo)
+		oauthGroup.POST("/userinfo", oauthHandler.UserInfo)
+		oauthGroup.GET("/authorize", oauthHandler.Authorize, opts.AuthMiddlewares...)
+		oauthGroup.POST("/authorize", oauthHandler.AuthorizeSubmit, opts.AuthMiddlewares...)
+		oauthGroup.POST("/token", oauthHandler.Token, opts.AuthMiddlewares...)
+	}
 }
 
 // SetupRoutes 设置受保护路由（需要鉴权）
+// 通过 API Key 或 OAuth 访问令牌访问时按权限范围校验，认证和 API Key 管理路由只允许用户本人登录的 JWT 访问
 func SetupRoutes(e *echo.Group) {
 	userService := user.NewUserService()
 	authHandler := auth.New(userService)
 	userHandler := userhandler.New(userService)
+	apiKeyHandler := apikeyhandler.New(apikey.NewAPIKeyService())
 
 	// 认证路由
-	e.POST("/auth/logout", authHandler.Logout)
-	e.POST("/auth/refresh", authHandler.RefreshToken)
-
-	// 用户路由
-	e.GET("/users", userHandler.ListUsers)
-	e.POST("/users", userHandler.CreateUser)
-	e.GET("/users/:id", userHandler.GetUser)
-	e.PUT("/users/:id", userHandler.UpdateUser)
-	e.DELETE("/users/:id", userHandler.DeleteUser)
+	authGroup := e.Group("/auth", middleware.DenyDelegated())
+	authGroup.POST("/logout", authHandler.Logout)
+	authGroup.POST("/refresh", authHandler.RefreshToken)
+	authGroup.POST("/password", authHandler.ChangePassword)
+
+	// 用户路由：列表、创建和删除只允许管理员，查询和修改允许管理员或用户本人（本人不能修改邮箱和状态）
+	usersRead := middleware.RequireScope(apikey.ScopeUsersRead)
+	usersWrite := middleware.RequireScope(apikey.ScopeUsersWrite)
+	admin := middleware.AdminMiddleware()
+	adminOrSelf := middleware.AdminOrSelfMiddleware("id")
+	e.GET("/users", userHandler.ListUsers, usersRead, admin)
+	e.POST("/users", userHandler.CreateUser, usersWrite, admin)
+	e.GET("/users/:id", userHandler.GetUser, usersRead, adminOrSelf)
+	e.PUT("/users/:id", userHandler.UpdateUser, usersWrite, adminOrSelf)
+	e.PATCH("/users/:id", userHandler.PatchUser, usersWrite, adminOrSelf)
+	e.DELETE("/users/:id", userHandler.DeleteUser, usersWrite, admin)
+
+	// API Key 路由（管理当前用户自己的 key）
+	keys := e.Group("/keys", middleware.DenyDelegated())
+	keys.GET("", apiKeyHandler.ListAPIKeys)
+	keys.POST("", apiKeyHandler.CreateAPIKey)
+	keys.GET("/:id", apiKeyHandler.GetAPIKey)
+	keys.PUT("/:id", apiKeyHandler.UpdateAPIKey)
+	keys.DELETE("/:id", apiKeyHandler.DeleteAPIKey)
+}
+
+// SetupAdminRoutes 设置管理员路由（需要鉴权和管理员权限）
+func SetupAdminRoutes(e *echo.Group) {
+	// 日志级别
+	e.GET("/log-level", system.GetLogLevel)
+	e.PUT("/log-level", system.SetLogLevel)
+
+	// 审计日志
+	e.GET("/audit-logs", system.ListAuditLogs)
+
+	// OAuth 客户端管理
+	if oauth.Enabled() {
+		oauthHandler := oauthhandler.New()
+		clients := e.Group("/oauth/clients")
+		clients.GET("", oauthHandler.ListClients)
+		clients.POST("", oauthHandler.CreateClient)
+		clients.GET("/:id", oauthHandler.GetClient)
+		clients.PUT("/:id", oauthHandler.UpdateClient)
+		clients.DELETE("/:id", oauthHandler.DeleteClient)
+		clients.POST("/:id/secret", oauthHandler.RotateClientSecret)
+	}
 }
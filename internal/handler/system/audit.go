@@ -0,0 +1,104 @@
+package system
+
+import (
+	"time"
+
+	"gosir/internal/common"
+	"gosir/internal/logger"
+	"gosir/internal/repository"
+	"gosir/internal/service/audit"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// 审计日志分页参数
+const (
+	defaultAuditPageSize = 20
+	maxAuditPageSize     = 100
+)
+
+// ListAuditLogsRequest 审计日志查询条件（为空的条件不过滤）
+type ListAuditLogsRequest struct {
+	ActorID    string `query:"actor_id"`                             // 操作人 ID
+	Action     string `query:"action" example:"user.update"`         // 操作，如 auth.login、user.update
+	TargetType string `query:"target_type" example:"user"`           // 操作对象类型：user, account
+	TargetID   string `query:"target_id"`                            // 操作对象 ID
+	RequestID  string `query:"request_id"`                           // 请求 ID
+	Since      string `query:"since" example:"2026-01-01T00:00:00Z"` // 起始时间（RFC3339，包含）
+	Until      string `query:"until" example:"2026-02-01T00:00:00Z"` // 截止时间（RFC3339，不包含）
+	Page       int    `query:"page" example:"1"`                     // 页码，从 1 开始
+	PageSize   int    `query:"page_size" example:"20"`               // 每页条数，最大 100
+}
+
+// ListAuditLogs 查询审计日志
+// @Summary      查询审计日志
+// @Description  按操作人、操作、操作对象、请求 ID 和时间范围分页查询审计日志，按时间倒序（仅管理员）
+// @Tags         系统
+// @Produce      json
+// @Security     Bearer
+// @Param        actor_id query string false "操作人 ID"
+// @Param        action query string false "操作，如 auth.login、auth.login_failed、user.update"
+// @Param        target_type query string false "操作对象类型：user, account"
+// @Param        target_id query string false "操作对象 ID"
+// @Param        request_id query string false "请求 ID"
+// @Param        since query string false "起始时间（RFC3339，包含）"
+// @Param        until query string false "截止时间（RFC3339，不包含）"
+// @Param        page query int false "页码，默认 1"
+// @Param        page_size query int false "每页条数，默认 20，最大 100"
+// @Success      200 {object} common.Response{data=common.Pagination{items=[]model.AuditLog}}
+// @Failure      400 {object} common.Response
+// @Failure      401 {object} common.Response
+// @Failure      403 {object} common.Response
+// @Router       /api/admin/audit-logs [get]
+func ListAuditLogs(c echo.Context) error {
+	var req ListAuditLogsRequest
+	if err := c.Bind(&req); err != nil {
+		return common.Error(c, common.CodeBadRequest, "请求参数解析失败")
+	}
+
+	filter := repository.AuditLogFilter{
+		ActorID:    req.ActorID,
+		Action:     req.Action,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		RequestID:  req.RequestID,
+	}
+	var err error
+	if filter.Since, err = parseTimeParam(req.Since); err != nil {
+		return common.Error(c, common.CodeBadRequest, "since 必须是 RFC3339 格式的时间")
+	}
+	if filter.Until, err = parseTimeParam(req.Until); err != nil {
+		return common.Error(c, common.CodeBadRequest, "until 必须是 RFC3339 格式的时间")
+	}
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	switch {
+	case req.PageSize < 1:
+		req.PageSize = defaultAuditPageSize
+	case req.PageSize > maxAuditPageSize:
+		req.PageSize = maxAuditPageSize
+	}
+
+	ctx := c.Request().Context()
+	logs, total, err := audit.NewAuditService().WithContext(ctx).List(filter, req.Page, req.PageSize)
+	if err != nil {
+		logger.Ctx(ctx).Error("Failed to list audit logs", zap.Error(err))
+		return common.Error(c, common.CodeInternalError, "查询审计日志失败")
+	}
+	return common.Paginate(c, req.Page, req.PageSize, total, logs)
+}
+
+// parseTimeParam 解析 RFC3339 时间参数，为空时返回 nil
+func parseTimeParam(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,65 @@
+package system
+
+import (
+	"time"
+
+	"gosir/internal/clock"
+	"gosir/internal/common"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ClockResponse 测试时钟响应
+type ClockResponse struct {
+	Now time.Time `json:"now" example:"2026-01-08T10:00:00Z"` // 当前时间
+}
+
+// SetClockRequest 调整测试时钟请求
+type SetClockRequest struct {
+	Now     *time.Time `json:"now" example:"2026-01-08T10:00:00Z"` // 设置为指定时间
+	Advance int        `json:"advance" example:"3600"`             // 在 now（或当前时间）的基础上推进的秒数
+}
+
+// GetClock 获取测试时钟
+// @Summary      获取测试时钟
+// @Description  获取业务代码使用的当前时间（仅 test 模式注册）
+// @Tags         系统
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} common.Response{data=ClockResponse}
+// @Router       /test/clock [get]
+func GetClock(c echo.Context) error {
+	return common.Success(c, ClockResponse{Now: clock.Now()})
+}
+
+// SetClock 调整测试时钟
+// @Summary      调整测试时钟
+// @Description  设置或推进业务代码使用的当前时间，用于测试 token 过期等场景（仅 test 模式注册）
+// @Tags         系统
+// @Accept       json
+// @Produce      json
+// @Param        request body SetClockRequest true "时间调整"
+// @Success      200 {object} common.Response{data=ClockResponse}
+// @Failure      400 {object} common.Response
+// @Router       /test/clock [put]
+func SetClock(c echo.Context) error {
+	fake, ok := clock.Current().(*clock.Fake)
+	if !ok {
+		return common.Error(c, common.CodeBadRequest, "当前未使用测试时钟")
+	}
+
+	var req SetClockRequest
+	if err := c.Bind(&req); err != nil {
+		return common.Error(c, common.CodeBadRequest, "请求参数解析失败")
+	}
+	if req.Advance < 0 {
+		return common.Error(c, common.CodeValidationError, "advance 不能为负数")
+	}
+
+	if req.Now != nil {
+		fake.Set(*req.Now)
+	}
+	now := fake.Advance(time.Duration(req.Advance) * time.Second)
+
+	return common.Success(c, ClockResponse{Now: now})
+}
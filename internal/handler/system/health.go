@@ -4,7 +4,12 @@ import (
 	"net/http"
 	"time"
 
+	"gosir/internal/common"
+	"gosir/internal/health"
+	"gosir/internal/logger"
+
 	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
 )
 
 // HealthResponse 健康检查响应
@@ -15,7 +20,7 @@ type HealthResponse struct {
 
 // HealthCheck 健康检查
 // @Summary      健康检查
-// @Description  检查服务健康状态
+// @Description  检查服务健康状态（等同于存活探针）
 // @Tags         系统
 // @Accept       json
 // @Produce      json
@@ -23,7 +28,53 @@ type HealthResponse struct {
 // @Router       /health [get]
 func HealthCheck(c echo.Context) error {
 	return c.JSON(http.StatusOK, HealthResponse{
-		Status:    "ok",
+		Status:    health.StatusOK,
 		Timestamp: time.Now(),
 	})
 }
+
+// Liveness 存活探针
+// @Summary      存活探针
+// @Description  进程能够处理请求即返回 ok，不检查外部依赖
+// @Tags         系统
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} HealthResponse
+// @Router       /health/live [get]
+func Liveness(c echo.Context) error {
+	return HealthCheck(c)
+}
+
+// Readiness 就绪探针
+// @Summary      就绪探针
+// @Description  检查数据库、迁移、定时任务和 JWT 等依赖，全部通过返回 200，否则返回 503；服务关闭期间始终返回 503
+// @Tags         系统
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} health.Report
+// @Failure      503 {object} health.Report
+// @Router       /health/ready [get]
+func Readiness(c echo.Context) error {
+	report := health.Check(c.Request().Context())
+
+	// 未开启 server.errorDetail 时不向客户端暴露依赖的错误详情，只记录到日志
+	if !common.ErrorDetail() {
+		for name, result := range report.Checks {
+			if result.Error == "" {
+				continue
+			}
+			logger.NamedCtx(c.Request().Context(), logger.ModuleHTTP).Warn("Readiness check failed",
+				zap.String("check", name),
+				zap.String("error", result.Error),
+			)
+			result.Error = ""
+			report.Checks[name] = result
+		}
+	}
+
+	status := http.StatusOK
+	if report.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, report)
+}
@@ -0,0 +1,69 @@
+package system
+
+import (
+	"errors"
+	"time"
+
+	"gosir/internal/common"
+	"gosir/internal/service/system"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SetLogLevelRequest 调整日志级别请求（为空的字段不修改）
+type SetLogLevelRequest struct {
+	Level   string            `json:"level" example:"debug"` // 全局级别：debug, info, warn, error
+	Modules map[string]string `json:"modules"`               // 模块级别，如 {"sql": "debug"}；inherit 表示跟随全局级别
+	GORM    string            `json:"gorm" example:"info"`   // SQL 日志级别：silent, error, warn, info
+	TTL     int               `json:"ttl" example:"600"`     // 自动恢复时间（秒），0 表示永久生效
+}
+
+// GetLogLevel 获取日志级别
+// @Summary      获取日志级别
+// @Description  获取全局、各模块和 GORM 的当前日志级别（仅管理员）
+// @Tags         系统
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Success      200 {object} common.Response{data=system.LogLevels}
+// @Failure      401 {object} common.Response
+// @Failure      403 {object} common.Response
+// @Router       /api/admin/log-level [get]
+func GetLogLevel(c echo.Context) error {
+	return common.Success(c, system.GetLogLevels())
+}
+
+// SetLogLevel 调整日志级别
+// @Summary      调整日志级别
+// @Description  运行时调整全局、模块或 GORM 日志级别，无需重启；指定 ttl 时到期自动恢复（仅管理员）
+// @Tags         系统
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body SetLogLevelRequest true "日志级别"
+// @Success      200 {object} common.Response{data=system.LogLevels}
+// @Failure      400 {object} common.Response
+// @Failure      401 {object} common.Response
+// @Failure      403 {object} common.Response
+// @Router       /api/admin/log-level [put]
+func SetLogLevel(c echo.Context) error {
+	var req SetLogLevelRequest
+	if err := c.Bind(&req); err != nil {
+		return common.Error(c, common.CodeBadRequest, "请求参数解析失败")
+	}
+
+	levels, err := system.SetLogLevels(system.LogLevelChange{
+		Root:    req.Level,
+		Modules: req.Modules,
+		GORM:    req.GORM,
+		TTL:     time.Duration(req.TTL) * time.Second,
+	})
+	if err != nil {
+		if errors.Is(err, system.ErrInvalidLogLevel) {
+			return common.Error(c, common.CodeValidationError, "日志级别无效: "+err.Error())
+		}
+		return common.Error(c, common.CodeInternalError, "调整日志级别失败")
+	}
+
+	return common.Success(c, levels)
+}
@@ -0,0 +1,24 @@
+package system
+
+import (
+	"net/http"
+
+	"gosir/internal/metrics"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Metrics Prometheus 指标
+// @Summary      Prometheus 指标
+// @Description  以 Prometheus 文本格式输出 HTTP、数据库、定时任务和认证相关指标，需要 HTTP Basic 认证（metrics.username / metrics.password），未设置密码时不提供
+// @Tags         系统
+// @Produce      plain
+// @Security     BasicAuth
+// @Success      200 {string} string "Prometheus 文本格式指标"
+// @Failure      401 {string} string "未认证"
+// @Router       /metrics [get]
+func Metrics(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+	c.Response().WriteHeader(http.StatusOK)
+	return metrics.DefaultRegistry.Write(c.Response())
+}
@@ -1,10 +1,19 @@
 package user
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"gosir/internal/common"
+	"gosir/internal/jsonpatch"
+	"gosir/internal/logger"
 	usermodel "gosir/internal/model/user"
+	"gosir/internal/repository"
 	"gosir/internal/service/user"
+	"io"
+	"mime"
+	"net/http"
 	"strings"
 
 	"github.com/go-playground/locales/zh"
@@ -12,6 +21,16 @@ import (
 	"github.com/go-playground/validator/v10"
 	zhtranslations "github.com/go-playground/validator/v10/translations/zh"
 	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+var (
+	// errIfMatchMissing 修改用户时缺少 If-Match 请求头
+	errIfMatchMissing = errors.New("if-match header required")
+	// errIfMatchWeak If-Match 请求头是弱 ETag
+	errIfMatchWeak = errors.New("weak etag in if-match header")
+	// errIfMatchInvalid If-Match 请求头不是有效的用户版本 ETag
+	errIfMatchInvalid = errors.New("invalid if-match header")
 )
 
 type UserResponse struct {
@@ -77,6 +96,8 @@ func (h *Handler) translateValidationError(err error) string {
 			errorMsg = fmt.Sprintf("%s长度不能少于%s个字符", chineseField, e.Param())
 		case "max":
 			errorMsg = fmt.Sprintf("%s长度不能超过%s个字符", chineseField, e.Param())
+		case "oneof":
+			errorMsg = fmt.Sprintf("%s必须是 %s 之一", chineseField, e.Param())
 		default:
 			errorMsg = fmt.Sprintf("%s验证失败: %s", chineseField, e.Tag())
 		}
@@ -96,45 +117,75 @@ type CreateUserRequest struct {
 	Status   *int   `json:"status" validate:"omitempty,oneof=1 2" example:"1"`                           // 状态：1-正常 2-禁用
 }
 
-// UpdateUserRequest 更新用户请求
+// UpdateUserRequest 更新用户请求（PUT 整体替换，PATCH 修改后的结果同样按此校验）
 type UpdateUserRequest struct {
-	Name   string `json:"name" example:"李四"`                                  // 姓名
-	Email  string `json:"email" example:"lisi@example.com"`                   // 邮箱
-	Phone  string `json:"phone" example:"13900139000"`                        // 手机号
-	Avatar string `json:"avatar" example:"http://example.com/new-avatar.jpg"` // 头像
-	Status *int   `json:"status" example:"2"`                                 // 状态：1-正常 2-禁用
+	Name   string `json:"name" validate:"required,max=255" example:"李四"`                               // 姓名
+	Email  string `json:"email" validate:"required,email,max=255" example:"lisi@example.com"`          // 邮箱
+	Phone  string `json:"phone" validate:"omitempty,max=20" example:"13900139000"`                     // 手机号，为空表示清除
+	Avatar string `json:"avatar" validate:"omitempty,max=500" example:"http://example.com/avatar.jpg"` // 头像，为空表示清除
+	Status *int   `json:"status" validate:"required,oneof=1 2 3" example:"2"`                          // 状态：1-正常 2-禁用 3-待验证（待验证用户改为 1 即手动通过邮箱验证）
+}
+
+// newUpdateUserRequest 由当前用户生成可修改字段的文档，作为 PATCH 的修改对象
+func newUpdateUserRequest(u *usermodel.User) UpdateUserRequest {
+	status := u.Status
+	return UpdateUserRequest{
+		Name:   u.Name,
+		Email:  u.Email,
+		Phone:  u.Phone,
+		Avatar: u.Avatar,
+		Status: &status,
+	}
+}
+
+// serviceRequest 转换为服务层请求
+func (r *UpdateUserRequest) serviceRequest() *user.UpdateUserRequest {
+	return &user.UpdateUserRequest{
+		Name:   r.Name,
+		Email:  r.Email,
+		Phone:  r.Phone,
+		Avatar: r.Avatar,
+		Status: r.Status,
+	}
 }
 
 // GetUser 获取用户详情
 // @Summary      获取用户详情
-// @Description  根据用户ID获取用户详细信息
+// @Description  根据用户ID获取用户详细信息，非管理员只能获取自己的信息
 // @Tags         用户管理
 // @Accept       json
 // @Produce      json
 // @Security     Bearer
+// @Security     ApiKey
 // @Param        id path string true "用户ID"
 // @Success      200 {object} common.Response{data=UserResponse}
+// @Header       200 {string} ETag "用户版本，修改用户时通过 If-Match 请求头传回"
+// @Failure      403 {object} common.Response
 // @Failure      404 {object} common.Response
 // @Router       /api/users/{id} [get]
 func (h *Handler) GetUser(c echo.Context) error {
 	id := c.Param("id")
-	userModel, err := h.userService.GetUserByID(id)
+	userModel, err := h.userService.WithContext(c.Request().Context()).GetUserByID(id)
 	if err != nil {
 		return common.Error(c, common.CodeNotFound, "用户不存在")
 	}
+	common.SetVersionETag(c, userModel.Version)
 	return common.Success(c, userModel)
 }
 
 // CreateUser 创建用户
 // @Summary      创建用户
-// @Description  创建新用户
+// @Description  创建新用户（需要管理员权限）
 // @Tags         用户管理
 // @Accept       json
 // @Produce      json
 // @Security     Bearer
+// @Security     ApiKey
 // @Param        request body CreateUserRequest true "用户信息"
 // @Success      201 {object} common.Response{data=UserResponse}
 // @Failure      400 {object} common.Response
+// @Failure      403 {object} common.Response
+// @Failure      409 {object} common.Response "邮箱已被其他用户使用（HTTP 状态码 409）"
 // @Failure      500 {object} common.Response
 // @Router       /api/users [post]
 func (h *Handler) CreateUser(c echo.Context) error {
@@ -157,25 +208,31 @@ func (h *Handler) CreateUser(c echo.Context) error {
 		Status:   req.Status,
 	}
 
-	newUser, err := h.userService.CreateUser(createReq)
+	newUser, err := h.userService.WithContext(c.Request().Context()).CreateUser(createReq)
+	if errors.Is(err, user.ErrEmailTaken) {
+		return common.ErrorWithStatus(c, http.StatusConflict, common.CodeConflict, "该邮箱已被其他用户使用")
+	}
 	if err != nil {
 		return common.Error(c, common.CodeInternalError, "创建用户失败")
 	}
+	common.SetVersionETag(c, newUser.Version)
 	return common.Created(c, newUser)
 }
 
 // ListUsers 获取用户列表
 // @Summary      获取用户列表
-// @Description  获取所有用户列表
+// @Description  获取所有用户列表（需要管理员权限）
 // @Tags         用户管理
 // @Accept       json
 // @Produce      json
 // @Security     Bearer
+// @Security     ApiKey
 // @Success      200 {object} common.Response{data=[]UserResponse}
+// @Failure      403 {object} common.Response
 // @Failure      500 {object} common.Response
 // @Router       /api/users [get]
 func (h *Handler) ListUsers(c echo.Context) error {
-	users, err := h.userService.GetAllUsers()
+	users, err := h.userService.WithContext(c.Request().Context()).GetAllUsers()
 	if err != nil {
 		return common.Error(c, common.CodeInternalError, "获取用户列表失败")
 	}
@@ -184,16 +241,24 @@ func (h *Handler) ListUsers(c echo.Context) error {
 
 // UpdateUser 更新用户
 // @Summary      更新用户
-// @Description  更新用户信息
+// @Description  整体替换用户信息，未传的手机号和头像会被清除；只修改部分字段请使用 PATCH。非管理员只能修改自己的姓名、手机号和头像（邮箱和状态必须与当前值相同）。需要通过 If-Match 请求头传入获取用户时返回的 ETag，用户已被他人修改时返回 412
 // @Tags         用户管理
 // @Accept       json
 // @Produce      json
 // @Security     Bearer
+// @Security     ApiKey
 // @Param        id path string true "用户ID"
+// @Param        If-Match header string true "获取用户时返回的强 ETag，* 表示不检查版本"
 // @Param        request body UpdateUserRequest true "用户信息"
 // @Success      200 {object} common.Response{data=UserResponse}
+// @Header       200 {string} ETag "修改后的用户版本"
 // @Failure      400 {object} common.Response
+// @Failure      403 {object} common.Response
 // @Failure      404 {object} common.Response
+// @Failure      409 {object} common.Response "邮箱或手机号已被其他用户使用（HTTP 状态码 409）"
+// @Failure      412 {object} common.Response "用户已被修改、If-Match 不匹配或使用了弱 ETag（HTTP 状态码 412）"
+// @Failure      422 {object} common.Response
+// @Failure      428 {object} common.Response "缺少 If-Match 请求头（HTTP 状态码 428）"
 // @Router       /api/users/{id} [put]
 func (h *Handler) UpdateUser(c echo.Context) error {
 	id := c.Param("id")
@@ -203,27 +268,178 @@ func (h *Handler) UpdateUser(c echo.Context) error {
 		return common.Error(c, common.CodeBadRequest, "请求参数解析失败")
 	}
 
-	updatedUser, err := h.userService.UpdateUser(id, req.Name, req.Email, req.Phone, req.Avatar, req.Status)
+	if err := h.validator.Struct(&req); err != nil {
+		return common.Error(c, common.CodeValidationError, h.translateValidationError(err))
+	}
+
+	version, err := ifMatchVersion(c)
 	if err != nil {
-		return common.Error(c, common.CodeNotFound, "用户不存在")
+		return h.updateError(c, err)
+	}
+
+	return h.saveUser(c, id, version, &req)
+}
+
+// PatchUser 修改用户部分字段
+// @Summary      修改用户部分字段
+// @Description  使用 JSON Merge Patch（Content-Type: application/merge-patch+json，值为 null 表示清除）或 JSON Patch（Content-Type: application/json-patch+json）修改用户，修改后的用户按 PUT 的规则校验。可修改的字段为 name、email、phone、avatar、status，非管理员只能修改自己的 name、phone、avatar。需要通过 If-Match 请求头传入获取用户时返回的 ETag
+// @Tags         用户管理
+// @Accept       application/merge-patch+json
+// @Accept       application/json-patch+json
+// @Produce      json
+// @Security     Bearer
+// @Security     ApiKey
+// @Param        id path string true "用户ID"
+// @Param        If-Match header string true "获取用户时返回的强 ETag，* 表示不检查版本"
+// @Param        request body object true "Merge Patch 对象或 JSON Patch 操作数组"
+// @Success      200 {object} common.Response{data=UserResponse}
+// @Header       200 {string} ETag "修改后的用户版本"
+// @Failure      400 {object} common.Response
+// @Failure      403 {object} common.Response
+// @Failure      404 {object} common.Response
+// @Failure      409 {object} common.Response "邮箱或手机号已被其他用户使用（HTTP 状态码 409）；JSON Patch 的 test 操作未通过（业务码 409）"
+// @Failure      412 {object} common.Response "用户已被修改、If-Match 不匹配或使用了弱 ETag（HTTP 状态码 412）"
+// @Failure      415 {object} common.Response
+// @Failure      422 {object} common.Response
+// @Failure      428 {object} common.Response "缺少 If-Match 请求头（HTTP 状态码 428）"
+// @Router       /api/users/{id} [patch]
+func (h *Handler) PatchUser(c echo.Context) error {
+	id := c.Param("id")
+
+	var apply func(doc, patch []byte) ([]byte, error)
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	switch mediaType {
+	case jsonpatch.MediaTypeMergePatch:
+		apply = jsonpatch.MergePatch
+	case jsonpatch.MediaTypeJSONPatch:
+		apply = jsonpatch.Apply
+	default:
+		return common.Error(c, common.CodeUnsupportedMedia, "Content-Type 必须是 application/merge-patch+json 或 application/json-patch+json")
+	}
+
+	patch, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return common.Error(c, common.CodeBadRequest, "请求参数解析失败")
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return h.updateError(c, err)
+	}
+
+	current, err := h.userService.WithContext(c.Request().Context()).GetUserByID(id)
+	if err != nil {
+		return h.updateError(c, err)
+	}
+	// If-Match: * 时以读取到的版本保存，避免 patch 应用到已过期的数据上
+	if version == common.AnyVersion {
+		version = current.Version
+	}
+	if current.Version != version {
+		return h.updateError(c, user.ErrVersionConflict)
+	}
+
+	doc, err := json.Marshal(newUpdateUserRequest(current))
+	if err != nil {
+		return h.updateError(c, err)
+	}
+	patched, err := apply(doc, patch)
+	switch {
+	case errors.Is(err, jsonpatch.ErrInvalidPatch):
+		return common.ErrorWithDetail(c, common.CodeBadRequest, "patch 格式不正确", err)
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		return common.ErrorWithDetail(c, common.CodeConflict, "patch 的 test 操作未通过", err)
+	case err != nil:
+		return common.ErrorWithDetail(c, common.CodeValidationError, "patch 无法应用到用户", err)
+	}
+
+	// 修改后的文档只能包含可修改的字段
+	var req UpdateUserRequest
+	dec := json.NewDecoder(bytes.NewReader(patched))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		return common.ErrorWithDetail(c, common.CodeValidationError, "只能修改 name、email、phone、avatar、status，且字段类型必须正确", err)
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return common.Error(c, common.CodeValidationError, h.translateValidationError(err))
+	}
+
+	return h.saveUser(c, id, version, &req)
+}
+
+// saveUser 保存修改后的用户并返回新的 ETag
+// 非管理员（用户本人）不能修改邮箱和状态
+func (h *Handler) saveUser(c echo.Context, id string, version int64, req *UpdateUserRequest) error {
+	serviceReq := req.serviceRequest()
+	isAdmin, _ := c.Get("is_admin").(bool)
+	serviceReq.Self = !isAdmin
+	updatedUser, err := h.userService.WithContext(c.Request().Context()).UpdateUser(id, version, serviceReq)
+	if err != nil {
+		return h.updateError(c, err)
 	}
+	common.SetVersionETag(c, updatedUser.Version)
 	return common.Success(c, updatedUser)
 }
 
+// ifMatchVersion 从 If-Match 请求头读取用户版本号，* 返回 common.AnyVersion
+func ifMatchVersion(c echo.Context) (int64, error) {
+	value := c.Request().Header.Get(common.HeaderIfMatch)
+	if value == "" {
+		return 0, errIfMatchMissing
+	}
+	if common.IsWeakETag(value) {
+		return 0, errIfMatchWeak
+	}
+	version, ok := common.ParseVersionETag(value)
+	if !ok {
+		return 0, errIfMatchInvalid
+	}
+	return version, nil
+}
+
+// updateError 修改用户失败时的错误响应
+func (h *Handler) updateError(c echo.Context, err error) error {
+	var notFound *repository.UserNotFoundError
+	switch {
+	case errors.Is(err, errIfMatchMissing):
+		return common.ErrorWithStatus(c, http.StatusPreconditionRequired, common.CodePreconditionRequired, "缺少 If-Match 请求头，请传入获取用户时返回的 ETag")
+	case errors.Is(err, errIfMatchWeak):
+		// If-Match 使用强比较（RFC 7232 3.1），弱 ETag 永远不匹配
+		return common.ErrorWithStatus(c, http.StatusPreconditionFailed, common.CodePreconditionFailed, "If-Match 不能使用弱 ETag，请传入获取用户时返回的 ETag")
+	case errors.Is(err, errIfMatchInvalid):
+		return common.ErrorWithStatus(c, http.StatusPreconditionFailed, common.CodePreconditionFailed, "If-Match 与用户当前版本不匹配")
+	case errors.As(err, &notFound):
+		return common.Error(c, common.CodeNotFound, "用户不存在")
+	case errors.Is(err, user.ErrVersionConflict):
+		return common.ErrorWithStatus(c, http.StatusPreconditionFailed, common.CodePreconditionFailed, "用户已被修改，请重新获取后再提交")
+	case errors.Is(err, user.ErrEmailTaken):
+		return common.ErrorWithStatus(c, http.StatusConflict, common.CodeConflict, "该邮箱已被其他用户使用")
+	case errors.Is(err, user.ErrPhoneTaken):
+		return common.ErrorWithStatus(c, http.StatusConflict, common.CodeConflict, "该手机号已被其他用户使用")
+	case errors.Is(err, user.ErrPrivilegedField):
+		return common.Error(c, common.CodeForbidden, "邮箱和状态只能由管理员修改")
+	default:
+		logger.Ctx(c.Request().Context()).Error("Failed to update user", zap.String("user_id", c.Param("id")), zap.Error(err))
+		return common.Error(c, common.CodeInternalError, "更新用户失败")
+	}
+}
+
 // DeleteUser 删除用户
 // @Summary      删除用户
-// @Description  根据用户ID删除用户
+// @Description  根据用户ID删除用户（需要管理员权限）
 // @Tags         用户管理
 // @Accept       json
 // @Produce      json
 // @Security     Bearer
+// @Security     ApiKey
 // @Param        id path string true "用户ID"
 // @Success      200 {object} common.Response
+// @Failure      403 {object} common.Response
 // @Failure      404 {object} common.Response
 // @Router       /api/users/{id} [delete]
 func (h *Handler) DeleteUser(c echo.Context) error {
 	id := c.Param("id")
-	err := h.userService.DeleteUser(id)
+	err := h.userService.WithContext(c.Request().Context()).DeleteUser(id)
 	if err != nil {
 		return common.Error(c, common.CodeNotFound, "用户不存在")
 	}
@@ -0,0 +1,177 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 检查状态
+const (
+	StatusOK           = "ok"
+	StatusFail         = "fail"
+	StatusShuttingDown = "shutting_down"
+)
+
+// defaultCheckTimeout 单次检查的默认超时时间
+const defaultCheckTimeout = 2 * time.Second
+
+// Checker 依赖检查函数，返回 nil 表示检查通过
+type Checker func(ctx context.Context) error
+
+// CheckResult 单项检查结果
+type CheckResult struct {
+	Status    string  `json:"status" example:"ok"`        // 状态：ok, fail
+	LatencyMs float64 `json:"latency_ms" example:"0.42"`  // 检查耗时（毫秒）
+	Error     string  `json:"error,omitempty" example:""` // 错误信息
+}
+
+// Report 检查报告
+type Report struct {
+	Status    string                 `json:"status" example:"ok"`                      // 总体状态：ok, fail, shutting_down
+	Timestamp time.Time              `json:"timestamp" example:"2026-01-08T10:00:00Z"` // 时间戳
+	Checks    map[string]CheckResult `json:"checks,omitempty"`                         // 各项检查结果
+}
+
+// namedChecker 已注册的检查项
+type namedChecker struct {
+	name  string
+	check Checker
+}
+
+// Registry 检查项注册表
+type Registry struct {
+	mu           sync.RWMutex
+	checkers     []namedChecker
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+// NewRegistry 创建检查项注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		timeout: defaultCheckTimeout,
+	}
+}
+
+// Register 注册检查项，同名检查项会被替换
+func (r *Registry) Register(name string, check Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, c := range r.checkers {
+		if c.name == name {
+			r.checkers[i].check = check
+			return
+		}
+	}
+	r.checkers = append(r.checkers, namedChecker{name: name, check: check})
+}
+
+// SetShuttingDown 标记服务正在关闭，之后的就绪检查直接返回失败
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// IsShuttingDown 服务是否正在关闭
+func (r *Registry) IsShuttingDown() bool {
+	return r.shuttingDown.Load()
+}
+
+// Check 并发执行所有检查项
+func (r *Registry) Check(ctx context.Context) Report {
+	report := Report{
+		Status:    StatusOK,
+		Timestamp: time.Now(),
+		Checks:    make(map[string]CheckResult),
+	}
+
+	if r.IsShuttingDown() {
+		report.Status = StatusShuttingDown
+		return report
+	}
+
+	r.mu.RLock()
+	checkers := make([]namedChecker, len(r.checkers))
+	copy(checkers, r.checkers)
+	r.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range checkers {
+		wg.Add(1)
+		go func(c namedChecker) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := runCheck(ctx, c.check)
+			result := CheckResult{
+				Status:    StatusOK,
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				result.Status = StatusFail
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			report.Checks[c.name] = result
+			if err != nil {
+				report.Status = StatusFail
+			}
+			mu.Unlock()
+		}(c)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// runCheck 执行检查，超时或 panic 都视为失败
+func runCheck(ctx context.Context, check Checker) (err error) {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("check panicked: %v", r)
+			}
+		}()
+		done <- check(ctx)
+	}()
+
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ============ 全局注册表 ============
+
+var defaultRegistry = NewRegistry()
+
+// Register 向全局注册表注册检查项
+func Register(name string, check Checker) {
+	defaultRegistry.Register(name, check)
+}
+
+// SetShuttingDown 标记服务正在关闭
+func SetShuttingDown() {
+	defaultRegistry.SetShuttingDown()
+}
+
+// IsShuttingDown 服务是否正在关闭
+func IsShuttingDown() bool {
+	return defaultRegistry.IsShuttingDown()
+}
+
+// Check 执行全局注册表中的所有检查项
+func Check(ctx context.Context) Report {
+	return defaultRegistry.Check(ctx)
+}
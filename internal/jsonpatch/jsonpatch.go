@@ -0,0 +1,104 @@
+// Package jsonpatch 实现 JSON Merge Patch（RFC 7396）和 JSON Patch（RFC 6902）
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// patch 文档的媒体类型
+const (
+	MediaTypeMergePatch = "application/merge-patch+json"
+	MediaTypeJSONPatch  = "application/json-patch+json"
+)
+
+var (
+	// ErrInvalidPatch patch 文档格式不正确
+	ErrInvalidPatch = errors.New("invalid patch document")
+	// ErrApplyFailed patch 无法应用到文档，如路径不存在
+	ErrApplyFailed = errors.New("patch cannot be applied")
+	// ErrTestFailed JSON Patch 的 test 操作比较失败
+	ErrTestFailed = errors.New("patch test operation failed")
+)
+
+// decode 解析 JSON 文档，数字保留为 json.Number 以免精度丢失
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after top-level value")
+	}
+	return v, nil
+}
+
+// equal 按 JSON 语义比较两个值，数字按数值比较
+func equal(a, b any) bool {
+	switch x := a.(type) {
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fx, okx := new(big.Float).SetString(x.String())
+		fy, oky := new(big.Float).SetString(y.String())
+		return okx && oky && fx.Cmp(fy) == 0
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			w, ok := y[k]
+			if !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// clone 深拷贝 JSON 值
+func clone(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(x))
+		for k, e := range x {
+			m[k] = clone(e)
+		}
+		return m
+	case []any:
+		s := make([]any, len(x))
+		for i, e := range x {
+			s[i] = clone(e)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+// applyError 应用失败的错误
+func applyError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrApplyFailed, fmt.Sprintf(format, args...))
+}
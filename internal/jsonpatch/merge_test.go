@@ -0,0 +1,56 @@
+package jsonpatch
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestMergePatch 覆盖 RFC 7396 第 3 节和附录 A 的示例
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{
+			name:  "第 3 节示例",
+			doc:   `{"title":"Goodbye!","author":{"givenName":"John","familyName":"Doe"},"tags":["example","sample"],"content":"This will be unchanged"}`,
+			patch: `{"title":"Hello!","phoneNumber":"+01-123-456-7890","author":{"familyName":null},"tags":["example"]}`,
+			want:  `{"title":"Hello!","author":{"givenName":"John"},"tags":["example"],"content":"This will be unchanged","phoneNumber":"+01-123-456-7890"}`,
+		},
+		{name: "A 替换成员", doc: `{"a":"b"}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{name: "A 新增成员", doc: `{"a":"b"}`, patch: `{"b":"c"}`, want: `{"a":"b","b":"c"}`},
+		{name: "A 删除成员", doc: `{"a":"b"}`, patch: `{"a":null}`, want: `{}`},
+		{name: "A 删除其中一个成员", doc: `{"a":"b","b":"c"}`, patch: `{"a":null}`, want: `{"b":"c"}`},
+		{name: "A 数组替换为字符串", doc: `{"a":["b"]}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{name: "A 字符串替换为数组", doc: `{"a":"c"}`, patch: `{"a":["b"]}`, want: `{"a":["b"]}`},
+		{name: "A 递归合并", doc: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, want: `{"a":{"b":"d"}}`},
+		{name: "A 数组整体替换", doc: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, want: `{"a":[1]}`},
+		{name: "A 顶层数组替换", doc: `["a","b"]`, patch: `["c","d"]`, want: `["c","d"]`},
+		{name: "A 对象替换为数组", doc: `{"a":"b"}`, patch: `["c"]`, want: `["c"]`},
+		{name: "A patch 为 null", doc: `{"a":"foo"}`, patch: `null`, want: `null`},
+		{name: "A patch 为字符串", doc: `{"a":"foo"}`, patch: `"bar"`, want: `"bar"`},
+		{name: "A 保留文档中的 null", doc: `{"e":null}`, patch: `{"a":1}`, want: `{"e":null,"a":1}`},
+		{name: "A 数组替换为对象", doc: `[1,2]`, patch: `{"a":"b","c":null}`, want: `{"a":"b"}`},
+		{name: "A 嵌套 null 不新增成员", doc: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, want: `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("MergePatch() unexpected error: %v", err)
+			}
+			assertJSONEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestMergePatchInvalid(t *testing.T) {
+	for _, patch := range []string{`{"a":`, `{"a":1} {}`, ``} {
+		if _, err := MergePatch([]byte(`{}`), []byte(patch)); !errors.Is(err, ErrInvalidPatch) {
+			t.Errorf("MergePatch(%q) error = %v, want %v", patch, err, ErrInvalidPatch)
+		}
+	}
+}
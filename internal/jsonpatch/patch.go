@@ -0,0 +1,257 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Operation JSON Patch 操作
+type Operation struct {
+	Op    string          `json:"op"`              // add, remove, replace, move, copy, test
+	Path  *string         `json:"path"`            // 目标位置（JSON Pointer）
+	From  *string         `json:"from,omitempty"`  // move、copy 的来源位置
+	Value json.RawMessage `json:"value,omitempty"` // add、replace、test 的值
+}
+
+// Apply 将 JSON Patch（RFC 6902）应用到 doc，返回修改后的文档
+// 操作按顺序执行，任一操作失败时整个 patch 不生效
+func Apply(doc, patch []byte) ([]byte, error) {
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	target, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	for i, op := range ops {
+		if target, err = op.apply(target); err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+	return json.Marshal(target)
+}
+
+// apply 执行单个操作，返回修改后的文档
+func (op Operation) apply(doc any) (any, error) {
+	if op.Path == nil {
+		return nil, fmt.Errorf("%w: missing path", ErrInvalidPatch)
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("%w: %s requires value", ErrInvalidPatch, op.Op)
+		}
+		value, err := decode(op.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		switch op.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			return replace(doc, path, value)
+		default:
+			current, err := get(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !equal(current, value) {
+				return nil, fmt.Errorf("%w: value at %q differs", ErrTestFailed, *op.Path)
+			}
+			return doc, nil
+		}
+	case "remove":
+		doc, _, err := remove(doc, path)
+		return doc, err
+	case "move", "copy":
+		if op.From == nil {
+			return nil, fmt.Errorf("%w: %s requires from", ErrInvalidPatch, op.Op)
+		}
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "copy" {
+			value, err := get(doc, from)
+			if err != nil {
+				return nil, err
+			}
+			return add(doc, path, clone(value))
+		}
+		if *op.From == *op.Path {
+			_, err := get(doc, from)
+			return doc, err
+		}
+		if strings.HasPrefix(*op.Path, *op.From+"/") {
+			return nil, applyError("cannot move %q into its own child %q", *op.From, *op.Path)
+		}
+		doc, value, err := remove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	default:
+		return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, op.Op)
+	}
+}
+
+// parsePointer 解析 JSON Pointer（RFC 6901），空字符串表示整个文档
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("%w: pointer %q must start with /", ErrInvalidPatch, pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 == len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+				return nil, fmt.Errorf("%w: invalid escape in pointer %q", ErrInvalidPatch, pointer)
+			}
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// arrayIndex 解析数组下标，max 为允许的最大下标
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.TrimLeft(token, "0123456789") != "" {
+		return 0, applyError("invalid array index %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i > max {
+		return 0, applyError("array index %q out of range", token)
+	}
+	return i, nil
+}
+
+// get 读取 path 处的值
+func get(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch c := doc.(type) {
+		case map[string]any:
+			v, ok := c[token]
+			if !ok {
+				return nil, applyError("member %q not found", token)
+			}
+			doc = v
+		case []any:
+			i, err := arrayIndex(token, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = c[i]
+		default:
+			return nil, applyError("cannot traverse into %q", token)
+		}
+	}
+	return doc, nil
+}
+
+// modify 找到 path 的父节点并用 fn 修改，返回修改后的文档
+// 数组修改后可能重新分配，因此沿路径把新值写回父节点
+func modify(doc any, path []string, fn func(parent any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+	child, err := get(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	child, err = modify(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+	switch c := doc.(type) {
+	case map[string]any:
+		c[path[0]] = child
+	case []any:
+		i, _ := arrayIndex(path[0], len(c)-1)
+		c[i] = child
+	}
+	return doc, nil
+}
+
+// add 在 path 处添加值：对象成员不存在时新增、存在时替换，数组在下标处插入（- 表示末尾）
+func add(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return modify(doc, path, func(parent any, token string) (any, error) {
+		switch c := parent.(type) {
+		case map[string]any:
+			c[token] = value
+			return c, nil
+		case []any:
+			i := len(c)
+			if token != "-" {
+				var err error
+				if i, err = arrayIndex(token, len(c)); err != nil {
+					return nil, err
+				}
+			}
+			c = append(c, nil)
+			copy(c[i+1:], c[i:])
+			c[i] = value
+			return c, nil
+		default:
+			return nil, applyError("cannot add member %q to a scalar", token)
+		}
+	})
+}
+
+// replace 替换 path 处已存在的值
+func replace(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	if _, err := get(doc, path); err != nil {
+		return nil, err
+	}
+	return modify(doc, path, func(parent any, token string) (any, error) {
+		switch c := parent.(type) {
+		case map[string]any:
+			c[token] = value
+			return c, nil
+		default:
+			s := c.([]any)
+			i, _ := arrayIndex(token, len(s)-1)
+			s[i] = value
+			return s, nil
+		}
+	})
+}
+
+// remove 删除 path 处已存在的值，返回修改后的文档和被删除的值
+func remove(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, applyError("cannot remove the whole document")
+	}
+	removed, err := get(doc, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, err = modify(doc, path, func(parent any, token string) (any, error) {
+		switch c := parent.(type) {
+		case map[string]any:
+			delete(c, token)
+			return c, nil
+		default:
+			s := c.([]any)
+			i, _ := arrayIndex(token, len(s)-1)
+			return append(s[:i], s[i+1:]...), nil
+		}
+	})
+	return doc, removed, err
+}
@@ -0,0 +1,218 @@
+package jsonpatch
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestApply 覆盖 RFC 6902 附录 A 的示例及若干边界情况
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "A.1 添加对象成员",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/baz","value":"qux"}]`,
+			want:  `{"baz":"qux","foo":"bar"}`,
+		},
+		{
+			name:  "A.2 添加数组元素",
+			doc:   `{"foo":["bar","baz"]}`,
+			patch: `[{"op":"add","path":"/foo/1","value":"qux"}]`,
+			want:  `{"foo":["bar","qux","baz"]}`,
+		},
+		{
+			name:  "A.3 删除对象成员",
+			doc:   `{"baz":"qux","foo":"bar"}`,
+			patch: `[{"op":"remove","path":"/baz"}]`,
+			want:  `{"foo":"bar"}`,
+		},
+		{
+			name:  "A.4 删除数组元素",
+			doc:   `{"foo":["bar","qux","baz"]}`,
+			patch: `[{"op":"remove","path":"/foo/1"}]`,
+			want:  `{"foo":["bar","baz"]}`,
+		},
+		{
+			name:  "A.5 替换值",
+			doc:   `{"baz":"qux","foo":"bar"}`,
+			patch: `[{"op":"replace","path":"/baz","value":"boo"}]`,
+			want:  `{"baz":"boo","foo":"bar"}`,
+		},
+		{
+			name:  "A.6 移动值",
+			doc:   `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			patch: `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			want:  `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			name:  "A.7 移动数组元素",
+			doc:   `{"foo":["all","grass","cows","eat"]}`,
+			patch: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			want:  `{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			name:  "A.8 test 成功",
+			doc:   `{"baz":"qux","foo":["a",2,"c"]}`,
+			patch: `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			want:  `{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{
+			name:    "A.9 test 失败",
+			doc:     `{"baz":"qux"}`,
+			patch:   `[{"op":"test","path":"/baz","value":"bar"}]`,
+			wantErr: ErrTestFailed,
+		},
+		{
+			name:  "A.10 添加嵌套对象",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`,
+			want:  `{"foo":"bar","child":{"grandchild":{}}}`,
+		},
+		{
+			name:  "A.11 忽略未知成员",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`,
+			want:  `{"foo":"bar","baz":"qux"}`,
+		},
+		{
+			name:    "A.12 添加到不存在的父节点",
+			doc:     `{"foo":"bar"}`,
+			patch:   `[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+			wantErr: ErrApplyFailed,
+		},
+		{
+			name:    "A.13 重复的 op 成员",
+			doc:     `{"foo":"bar"}`,
+			patch:   `[{"op":"add","path":"/baz","value":"qux","op":"remove"}]`,
+			wantErr: ErrApplyFailed,
+		},
+		{
+			name:  "A.14 ~ 转义顺序",
+			doc:   `{"/":9,"~1":10}`,
+			patch: `[{"op":"test","path":"/~01","value":10}]`,
+			want:  `{"/":9,"~1":10}`,
+		},
+		{
+			name:    "A.15 字符串与数字不相等",
+			doc:     `{"/":9,"~1":10}`,
+			patch:   `[{"op":"test","path":"/~01","value":"10"}]`,
+			wantErr: ErrTestFailed,
+		},
+		{
+			name:  "A.16 添加数组值",
+			doc:   `{"foo":["bar"]}`,
+			patch: `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`,
+			want:  `{"foo":["bar",["abc","def"]]}`,
+		},
+		{
+			name:  "copy 深拷贝",
+			doc:   `{"a":{"b":1}}`,
+			patch: `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`,
+			want:  `{"a":{"b":1},"c":{"b":2}}`,
+		},
+		{
+			name:  "替换整个文档",
+			doc:   `{"a":1}`,
+			patch: `[{"op":"replace","path":"","value":[1]}]`,
+			want:  `[1]`,
+		},
+		{
+			name:  "数字按数值比较",
+			doc:   `{"a":1}`,
+			patch: `[{"op":"test","path":"/a","value":1.0}]`,
+			want:  `{"a":1}`,
+		},
+		{
+			name:    "任一操作失败时整体不生效",
+			doc:     `{"a":1}`,
+			patch:   `[{"op":"add","path":"/b","value":2},{"op":"test","path":"/a","value":2}]`,
+			wantErr: ErrTestFailed,
+		},
+		{
+			name:    "移动到自身子节点",
+			doc:     `{"a":{"b":1}}`,
+			patch:   `[{"op":"move","from":"/a","path":"/a/c"}]`,
+			wantErr: ErrApplyFailed,
+		},
+		{
+			name:    "数组下标越界",
+			doc:     `{"foo":["bar"]}`,
+			patch:   `[{"op":"add","path":"/foo/2","value":"qux"}]`,
+			wantErr: ErrApplyFailed,
+		},
+		{
+			name:    "数组下标有前导零",
+			doc:     `{"foo":["bar","baz"]}`,
+			patch:   `[{"op":"remove","path":"/foo/01"}]`,
+			wantErr: ErrApplyFailed,
+		},
+		{
+			name:    "未知操作",
+			doc:     `{}`,
+			patch:   `[{"op":"merge","path":"/a","value":1}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "缺少 value",
+			doc:     `{}`,
+			patch:   `[{"op":"add","path":"/a"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "缺少 path",
+			doc:     `{}`,
+			patch:   `[{"op":"remove"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "非法转义",
+			doc:     `{}`,
+			patch:   `[{"op":"add","path":"/a~2","value":1}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "patch 不是数组",
+			doc:     `{}`,
+			patch:   `{"op":"add","path":"/a","value":1}`,
+			wantErr: ErrInvalidPatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Apply() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply() unexpected error: %v", err)
+			}
+			assertJSONEqual(t, got, tt.want)
+		})
+	}
+}
+
+// assertJSONEqual 按 JSON 语义比较结果，忽略成员顺序
+func assertJSONEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+	g, err := decode(got)
+	if err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	w, err := decode([]byte(want))
+	if err != nil {
+		t.Fatalf("decode want: %v", err)
+	}
+	if !equal(g, w) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// requestIDKey context 中保存请求 ID 的 key
+type requestIDKey struct{}
+
+// WithRequestID 将请求 ID 存入 context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext 从 context 中获取请求 ID
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// ContextFields 从 context 中提取需要附加到日志的字段（请求 ID、trace ID、span ID）
+func ContextFields(ctx context.Context) []zap.Field {
+	if ctx == nil {
+		return nil
+	}
+
+	var fields []zap.Field
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		fields = append(fields, zap.String("request_id", requestID))
+	}
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+		fields = append(fields,
+			zap.String("trace_id", spanCtx.TraceID().String()),
+			zap.String("span_id", spanCtx.SpanID().String()),
+		)
+	}
+	return fields
+}
+
+// Ctx 获取附加了 context 字段（如请求 ID）的 logger
+func Ctx(ctx context.Context) *zap.Logger {
+	fields := ContextFields(ctx)
+	if len(fields) == 0 {
+		return Log
+	}
+	return Log.With(fields...)
+}
@@ -1,8 +1,10 @@
 package logger
 
 import (
+	"errors"
+	"fmt"
 	"io"
-	"os"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -11,60 +13,121 @@ import (
 var Log *zap.Logger
 var Sugar *zap.SugaredLogger
 
+// fileWriters 当前打开的日志文件（支持轮转和重新打开）
+var fileWriters []*rotatingWriter
+
+// closers 重新初始化时需要关闭的输出（日志文件、syslog 连接）
+var closers []io.Closer
+
 // LogConfig 日志配置
 type LogConfig struct {
-	Path   string
-	Level  string // debug, info, warn, error
-	Format string // json, text
+	Path           string
+	Level          string // debug, info, warn, error
+	Format         string // json, text
+	DisableConsole bool   // 不输出到控制台（命令行工具使用，避免日志与命令输出混在一起）
+	Rotate         RotateConfig
+	Outputs        []OutputConfig    // 日志输出，为空时同时输出到 Path 文件和标准输出
+	Modules        map[string]string // 模块日志级别（http, sql, cron, auth, notify），未设置的模块使用 Level
+	Sampling       SamplingConfig
+}
+
+// SamplingConfig debug 日志采样配置（按日志内容计数，每个周期内前 Initial 条全部输出，之后每 Thereafter 条输出 1 条）
+type SamplingConfig struct {
+	Enabled    bool
+	Tick       time.Duration
+	Initial    int
+	Thereafter int // 0 表示超出 Initial 后全部丢弃
 }
 
 // InitWithConfig 使用配置初始化日志
 func InitWithConfig(cfg *LogConfig) error {
-	// 创建日志文件
-	fileWriter, err := os.OpenFile(cfg.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		return err
+	outputs := cfg.Outputs
+	if len(outputs) == 0 {
+		outputs = []OutputConfig{{Type: OutputFile}, {Type: OutputStdout}}
 	}
 
-	// 同时输出到文件和控制台
-	multiWriter := io.MultiWriter(fileWriter, os.Stdout)
-
-	// 解析日志级别
-	level := parseLevel(cfg.Level)
-
-	// 编码器配置
-	encoderConfig := zapcore.EncoderConfig{
-		TimeKey:        "time",
-		LevelKey:       "level",
-		NameKey:        "logger",
-		CallerKey:      "source",
-		MessageKey:     "msg",
-		StacktraceKey:  "stacktrace",
-		LineEnding:     zapcore.DefaultLineEnding,
-		EncodeLevel:    zapcore.LowercaseLevelEncoder,
-		EncodeTime:     zapcore.ISO8601TimeEncoder,
-		EncodeDuration: zapcore.SecondsDurationEncoder,
-		EncodeCaller:   zapcore.ShortCallerEncoder,
+	var (
+		cores      []zapcore.Core
+		newWriters []*rotatingWriter
+		newClosers []io.Closer
+	)
+	// 同一路径的多个 file 输出共享同一个文件
+	filesByPath := make(map[string]*rotatingWriter)
+
+	for i, out := range outputs {
+		if cfg.DisableConsole && (out.Type == OutputStdout || out.Type == OutputStderr) {
+			continue
+		}
+		if out.Format == "" {
+			out.Format = cfg.Format
+		}
+		if out.Type == OutputFile && out.Path == "" {
+			out.Path = cfg.Path
+		}
+
+		core, writer, closer, err := newOutputCore(out, cfg.Rotate, filesByPath)
+		if err != nil {
+			closeAll(newClosers)
+			return fmt.Errorf("log output #%d (%s): %w", i+1, out.Type, err)
+		}
+		cores = append(cores, core)
+		if writer != nil {
+			newWriters = append(newWriters, writer)
+		}
+		if closer != nil {
+			newClosers = append(newClosers, closer)
+		}
 	}
 
-	// 根据格式选择编码器
-	var encoder zapcore.Encoder
-	if cfg.Format == "json" {
-		encoder = zapcore.NewJSONEncoder(encoderConfig)
-	} else {
-		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	base := zapcore.NewTee(cores...)
+	if cfg.Sampling.Enabled {
+		base = newDebugSampler(base, cfg.Sampling)
 	}
 
-	// 创建核心
-	core := zapcore.NewCore(encoder, zapcore.AddSync(multiWriter), level)
+	// 替换全局 logger，已创建的模块 logger 失效
+	rootLevel.SetLevel(parseLevel(cfg.Level))
+	initModules(base, cfg.Modules)
 
-	// 创建 logger
-	Log = zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
+	Log = newLogger(base, rootLevel)
 	Sugar = Log.Sugar()
 
+	closeAll(closers)
+	fileWriters = newWriters
+	closers = newClosers
+
 	return nil
 }
 
+// newLogger 创建按 level 过滤的 logger
+func newLogger(core zapcore.Core, level zapcore.LevelEnabler) *zap.Logger {
+	return zap.New(&levelFilterCore{Core: core, level: level}, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
+}
+
+// closeAll 关闭输出，忽略错误
+func closeAll(list []io.Closer) {
+	for _, c := range list {
+		_ = c.Close()
+	}
+}
+
+// Reopen 重新打开日志文件（收到 SIGHUP 时调用）
+func Reopen() error {
+	var errs []error
+	for _, w := range fileWriters {
+		errs = append(errs, w.Reopen())
+	}
+	return errors.Join(errs...)
+}
+
+// Rotate 立即轮转日志文件
+func Rotate() error {
+	var errs []error
+	for _, w := range fileWriters {
+		errs = append(errs, w.Rotate())
+	}
+	return errors.Join(errs...)
+}
+
 // parseLevel 解析日志级别
 func parseLevel(level string) zapcore.Level {
 	switch level {
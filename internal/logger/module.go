@@ -0,0 +1,236 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 模块名称（命名子 logger）
+const (
+	ModuleHTTP   = "http"
+	ModuleSQL    = "sql"
+	ModuleCron   = "cron"
+	ModuleAuth   = "auth"
+	ModuleNotify = "notify"
+)
+
+// rootLevel 全局日志级别，未单独设置级别的模块跟随该级别
+var rootLevel = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+
+// modules 模块 logger 注册表
+var modules = struct {
+	sync.RWMutex
+	core    zapcore.Core
+	levels  map[string]*moduleLevel
+	loggers map[string]*zap.Logger
+}{}
+
+// knownModules 内置模块，始终出现在模块级别列表中
+var knownModules = []string{ModuleHTTP, ModuleSQL, ModuleCron, ModuleAuth, ModuleNotify}
+
+// moduleLevel 模块日志级别，未单独设置时跟随全局级别
+type moduleLevel struct {
+	level   zap.AtomicLevel
+	inherit atomic.Bool
+}
+
+// newModuleLevel 创建跟随全局级别的模块级别
+func newModuleLevel() *moduleLevel {
+	m := &moduleLevel{level: zap.NewAtomicLevel()}
+	m.inherit.Store(true)
+	return m
+}
+
+// Enabled 实现 zapcore.LevelEnabler
+func (m *moduleLevel) Enabled(l zapcore.Level) bool {
+	if m.inherit.Load() {
+		return rootLevel.Enabled(l)
+	}
+	return m.level.Enabled(l)
+}
+
+// Level 返回模块当前生效的级别
+func (m *moduleLevel) Level() zapcore.Level {
+	if m.inherit.Load() {
+		return rootLevel.Level()
+	}
+	return m.level.Level()
+}
+
+// GetLevel 获取全局日志级别
+func GetLevel() zapcore.Level {
+	return rootLevel.Level()
+}
+
+// SetLevel 运行时修改全局日志级别（未单独设置级别的模块同时生效）
+func SetLevel(level zapcore.Level) {
+	rootLevel.SetLevel(level)
+}
+
+// ModuleLevel 获取模块当前生效的日志级别，inherited 表示跟随全局级别
+func ModuleLevel(name string) (level zapcore.Level, inherited bool) {
+	modules.RLock()
+	m, ok := modules.levels[name]
+	modules.RUnlock()
+	if !ok {
+		return rootLevel.Level(), true
+	}
+	return m.Level(), m.inherit.Load()
+}
+
+// SetModuleLevel 运行时修改模块日志级别，level 为 nil 时恢复跟随全局级别
+func SetModuleLevel(name string, level *zapcore.Level) error {
+	if name == "" {
+		return fmt.Errorf("module name is required")
+	}
+
+	modules.Lock()
+	defer modules.Unlock()
+
+	if modules.levels == nil {
+		return fmt.Errorf("logger is not initialized")
+	}
+	m, ok := modules.levels[name]
+	if !ok {
+		m = newModuleLevel()
+		modules.levels[name] = m
+	}
+	if level == nil {
+		m.inherit.Store(true)
+		return nil
+	}
+	m.level.SetLevel(*level)
+	m.inherit.Store(false)
+	return nil
+}
+
+// ModuleNames 返回所有模块名称（内置模块、配置中的模块和已使用的模块）
+func ModuleNames() []string {
+	modules.RLock()
+	defer modules.RUnlock()
+
+	names := make([]string, 0, len(modules.levels))
+	for name := range modules.levels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// initModules 使用新的日志核心重建模块 logger
+func initModules(core zapcore.Core, levels map[string]string) {
+	modules.Lock()
+	defer modules.Unlock()
+
+	modules.core = core
+	modules.levels = make(map[string]*moduleLevel, len(knownModules)+len(levels))
+	modules.loggers = make(map[string]*zap.Logger)
+	for _, name := range knownModules {
+		modules.levels[name] = newModuleLevel()
+	}
+	for name, level := range levels {
+		m := newModuleLevel()
+		m.level.SetLevel(parseLevel(level))
+		m.inherit.Store(false)
+		modules.levels[name] = m
+	}
+}
+
+// Named 获取模块 logger（如 http、sql、cron、auth），日志带 logger 字段且级别可单独配置
+func Named(name string) *zap.Logger {
+	modules.RLock()
+	log, ok := modules.loggers[name]
+	modules.RUnlock()
+	if ok {
+		return log
+	}
+
+	modules.Lock()
+	defer modules.Unlock()
+
+	if log, ok := modules.loggers[name]; ok {
+		return log
+	}
+	if modules.core == nil {
+		return zap.NewNop()
+	}
+
+	level, ok := modules.levels[name]
+	if !ok {
+		level = newModuleLevel()
+		modules.levels[name] = level
+	}
+	log = newLogger(modules.core, level).Named(name)
+	modules.loggers[name] = log
+	return log
+}
+
+// NamedCtx 获取附加了 context 字段（如请求 ID）的模块 logger
+func NamedCtx(ctx context.Context, name string) *zap.Logger {
+	log := Named(name)
+	if fields := ContextFields(ctx); len(fields) > 0 {
+		return log.With(fields...)
+	}
+	return log
+}
+
+// levelFilterCore 在输出级别之外再按 logger 级别过滤
+type levelFilterCore struct {
+	zapcore.Core
+	level zapcore.LevelEnabler
+}
+
+func (c *levelFilterCore) Enabled(l zapcore.Level) bool {
+	return c.level.Enabled(l) && c.Core.Enabled(l)
+}
+
+func (c *levelFilterCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelFilterCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c *levelFilterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// debugSamplerCore 只对 debug 日志采样，其他级别全部输出
+type debugSamplerCore struct {
+	zapcore.Core
+	sampled zapcore.Core
+}
+
+// newDebugSampler 创建 debug 日志采样核心
+func newDebugSampler(core zapcore.Core, cfg SamplingConfig) zapcore.Core {
+	tick := cfg.Tick
+	if tick <= 0 {
+		tick = time.Second
+	}
+	initial := cfg.Initial
+	if initial <= 0 {
+		initial = 1
+	}
+	return &debugSamplerCore{
+		Core:    core,
+		sampled: zapcore.NewSamplerWithOptions(core, tick, initial, cfg.Thereafter),
+	}
+}
+
+func (c *debugSamplerCore) With(fields []zapcore.Field) zapcore.Core {
+	return &debugSamplerCore{Core: c.Core.With(fields), sampled: c.sampled.With(fields)}
+}
+
+func (c *debugSamplerCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level == zapcore.DebugLevel {
+		return c.sampled.Check(ent, ce)
+	}
+	return c.Core.Check(ent, ce)
+}
@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// 日志输出类型
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+	OutputFile   = "file"
+	OutputSyslog = "syslog"
+)
+
+// defaultSyslogTag 默认 syslog 标签
+const defaultSyslogTag = "gosir"
+
+// OutputConfig 单个日志输出配置
+type OutputConfig struct {
+	Type    string // stdout, stderr, file, syslog
+	Format  string // json, text，为空时使用 LogConfig.Format
+	Level   string // 该输出的最低级别，为空时不额外过滤
+	Path    string // file: 文件路径，为空时使用 LogConfig.Path
+	Network string // syslog: unix, unixgram, udp, tcp，为空时连接本机 syslog socket
+	Address string // syslog: 地址，Network 为空时忽略
+	Tag     string // syslog: 标签，默认 gosir
+}
+
+// newOutputCore 根据输出配置创建日志核心
+// 返回的 rotatingWriter 用于轮转和重新打开，io.Closer 在重新初始化时关闭
+func newOutputCore(out OutputConfig, rotate RotateConfig, files map[string]*rotatingWriter) (zapcore.Core, *rotatingWriter, io.Closer, error) {
+	level, err := parseOutputLevel(out.Level)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	encoder := newEncoder(out.Format)
+
+	switch out.Type {
+	case OutputStdout:
+		return zapcore.NewCore(encoder, zapcore.Lock(os.Stdout), level), nil, nil, nil
+	case OutputStderr:
+		return zapcore.NewCore(encoder, zapcore.Lock(os.Stderr), level), nil, nil, nil
+	case OutputFile:
+		if out.Path == "" {
+			return nil, nil, nil, fmt.Errorf("path is required")
+		}
+		if writer, ok := files[out.Path]; ok {
+			return zapcore.NewCore(encoder, zapcore.AddSync(writer), level), nil, nil, nil
+		}
+		writer, err := openFileWriter(out.Path, rotate)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		files[out.Path] = writer
+		return zapcore.NewCore(encoder, zapcore.AddSync(writer), level), writer, writer, nil
+	case OutputSyslog:
+		tag := out.Tag
+		if tag == "" {
+			tag = defaultSyslogTag
+		}
+		core, closer, err := newSyslogCore(out.Network, out.Address, tag, encoder, level)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return core, nil, closer, nil
+	default:
+		return nil, nil, nil, fmt.Errorf("unknown output type %q", out.Type)
+	}
+}
+
+// openFileWriter 打开轮转日志文件
+func openFileWriter(path string, rotate RotateConfig) (*rotatingWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	// 预先创建日志文件，轮转后的新文件沿用该权限（lumberjack 默认创建 0600 文件）
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	_ = file.Close()
+
+	// 按大小/按天轮转，旧文件按数量和天数清理
+	writer := newRotatingWriter(path, rotate)
+	if _, err := writer.Write(nil); err != nil {
+		_ = writer.Close()
+		return nil, err
+	}
+	return writer, nil
+}
+
+// newEncoder 根据格式创建编码器
+func newEncoder(format string) zapcore.Encoder {
+	encoderConfig := zapcore.EncoderConfig{
+		TimeKey:        "time",
+		LevelKey:       "level",
+		NameKey:        "logger",
+		CallerKey:      "source",
+		MessageKey:     "msg",
+		StacktraceKey:  "stacktrace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.SecondsDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	}
+
+	if format == "json" {
+		return zapcore.NewJSONEncoder(encoderConfig)
+	}
+	return zapcore.NewConsoleEncoder(encoderConfig)
+}
+
+// parseOutputLevel 解析输出级别，为空时输出所有级别（由 logger 自身的级别控制）
+func parseOutputLevel(level string) (zapcore.Level, error) {
+	if level == "" {
+		return zapcore.DebugLevel, nil
+	}
+	l, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return l, fmt.Errorf("invalid level %q", level)
+	}
+	return l, nil
+}
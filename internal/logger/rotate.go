@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// RotateConfig 日志轮转配置
+type RotateConfig struct {
+	MaxSize    int  // 单个日志文件最大大小（MB），超过后轮转
+	MaxBackups int  // 保留的历史日志文件数量，0 表示不限制
+	MaxAge     int  // 历史日志文件保留天数，0 表示不限制
+	Compress   bool // 是否使用 gzip 压缩历史日志文件
+	Daily      bool // 是否每天零点轮转
+	LocalTime  bool // 历史日志文件名使用本地时间（默认 UTC）
+}
+
+// defaultMaxSize 默认单个日志文件最大大小（MB）
+const defaultMaxSize = 100
+
+// rotatingWriter 支持按大小和按天轮转的日志文件
+type rotatingWriter struct {
+	*lumberjack.Logger
+
+	mu   sync.Mutex
+	stop chan struct{}
+}
+
+// newRotatingWriter 创建轮转日志文件
+func newRotatingWriter(path string, cfg RotateConfig) *rotatingWriter {
+	maxSize := cfg.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxSize
+	}
+
+	w := &rotatingWriter{
+		Logger: &lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    maxSize,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAge,
+			Compress:   cfg.Compress,
+			LocalTime:  cfg.LocalTime,
+		},
+	}
+
+	if cfg.Daily {
+		w.stop = make(chan struct{})
+		go w.rotateDaily(cfg.LocalTime)
+	}
+	return w
+}
+
+// rotateDaily 每天零点轮转一次
+func (w *rotatingWriter) rotateDaily(localTime bool) {
+	for {
+		now := time.Now()
+		if !localTime {
+			now = now.UTC()
+		}
+		next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-w.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+			_ = w.Rotate()
+		}
+	}
+}
+
+// Reopen 关闭当前日志文件，下次写入时按原路径重新打开
+// 用于配合 logrotate 等外部工具（文件被移走后写入新文件）
+func (w *rotatingWriter) Reopen() error {
+	return w.Logger.Close()
+}
+
+// Close 停止按天轮转并关闭日志文件
+func (w *rotatingWriter) Close() error {
+	w.mu.Lock()
+	if w.stop != nil {
+		close(w.stop)
+		w.stop = nil
+	}
+	w.mu.Unlock()
+
+	return w.Logger.Close()
+}
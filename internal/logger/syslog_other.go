@@ -0,0 +1,15 @@
+//go:build windows || plan9
+
+package logger
+
+import (
+	"errors"
+	"io"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// newSyslogCore 当前平台不支持 syslog
+func newSyslogCore(network, address, tag string, encoder zapcore.Encoder, level zapcore.LevelEnabler) (zapcore.Core, io.Closer, error) {
+	return nil, nil, errors.New("syslog output is not supported on this platform")
+}
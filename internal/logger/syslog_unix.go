@@ -0,0 +1,68 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"io"
+	"log/syslog"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// syslogCore 将日志写入 syslog，按日志级别映射 syslog 优先级
+type syslogCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	writer  *syslog.Writer
+}
+
+// newSyslogCore 连接 syslog（network 为空时连接本机 /dev/log 等 socket）
+func newSyslogCore(network, address, tag string, encoder zapcore.Encoder, level zapcore.LevelEnabler) (zapcore.Core, io.Closer, error) {
+	writer, err := syslog.Dial(network, address, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &syslogCore{LevelEnabler: level, encoder: encoder, writer: writer}, writer, nil
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	encoder := c.encoder.Clone()
+	for _, f := range fields {
+		f.AddTo(encoder)
+	}
+	return &syslogCore{LevelEnabler: c.LevelEnabler, encoder: encoder, writer: c.writer}
+}
+
+func (c *syslogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *syslogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+
+	msg := strings.TrimSuffix(buf.String(), "\n")
+	switch ent.Level {
+	case zapcore.DebugLevel:
+		return c.writer.Debug(msg)
+	case zapcore.InfoLevel:
+		return c.writer.Info(msg)
+	case zapcore.WarnLevel:
+		return c.writer.Warning(msg)
+	case zapcore.ErrorLevel:
+		return c.writer.Err(msg)
+	default:
+		return c.writer.Crit(msg)
+	}
+}
+
+func (c *syslogCore) Sync() error {
+	return nil
+}
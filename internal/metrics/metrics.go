@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"runtime"
+	"time"
+)
+
+// ============ HTTP ============
+
+var (
+	// HTTPRequestsTotal HTTP 请求总数
+	HTTPRequestsTotal = NewCounterVec(
+		"gosir_http_requests_total",
+		"Total number of HTTP requests by method, route template and status.",
+		"method", "route", "status",
+	)
+
+	// HTTPRequestDuration HTTP 请求耗时
+	HTTPRequestDuration = NewHistogramVec(
+		"gosir_http_request_duration_seconds",
+		"HTTP request latency in seconds by method, route template and status.",
+		nil,
+		"method", "route", "status",
+	)
+)
+
+// ============ 数据库 ============
+
+var (
+	// DBQueryDuration SQL 执行耗时
+	DBQueryDuration = NewHistogramVec(
+		"gosir_db_query_duration_seconds",
+		"SQL query duration in seconds by operation.",
+		[]float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		"operation",
+	)
+
+	// DBQueryErrorsTotal SQL 执行错误数
+	DBQueryErrorsTotal = NewCounterVec(
+		"gosir_db_query_errors_total",
+		"Total number of failed SQL queries by operation.",
+		"operation",
+	)
+)
+
+// ============ 定时任务 ============
+
+var (
+	// CronJobRunsTotal 定时任务执行次数
+	CronJobRunsTotal = NewCounterVec(
+		"gosir_cron_job_runs_total",
+		"Total number of cron job runs by job name.",
+		"job",
+	)
+
+	// CronJobFailuresTotal 定时任务失败次数
+	CronJobFailuresTotal = NewCounterVec(
+		"gosir_cron_job_failures_total",
+		"Total number of failed cron job runs by job name.",
+		"job",
+	)
+
+	// CronJobDuration 定时任务执行耗时
+	CronJobDuration = NewHistogramVec(
+		"gosir_cron_job_duration_seconds",
+		"Cron job run duration in seconds by job name.",
+		[]float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+		"job",
+	)
+)
+
+// ============ 认证 ============
+
+// LoginTotal 登录次数
+var LoginTotal = NewCounterVec(
+	"gosir_auth_login_total",
+	"Total number of login attempts by result.",
+	"result",
+)
+
+// 登录结果
+const (
+	LoginSuccess = "success"
+	LoginFailure = "failure"
+)
+
+// ============ 限流 ============
+
+// RateLimitStoreErrorsTotal 限流存储异常次数，异常时请求会被放行
+var RateLimitStoreErrorsTotal = NewCounterVec(
+	"gosir_ratelimit_store_errors_total",
+	"Total number of rate limit store failures by group; affected requests are allowed without limiting.",
+	"group",
+)
+
+// ============ 运行时 ============
+
+var processStartTime = time.Now()
+
+func init() {
+	NewGaugeFunc("gosir_process_start_time_seconds", "Start time of the process since unix epoch in seconds.", func() float64 {
+		return float64(processStartTime.UnixNano()) / 1e9
+	})
+	NewGaugeFunc("gosir_go_goroutines", "Number of goroutines that currently exist.", func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+	NewGaugeFunc("gosir_go_memstats_heap_alloc_bytes", "Number of heap bytes allocated and still in use.", func() float64 {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		return float64(m.HeapAlloc)
+	})
+}
@@ -0,0 +1,351 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultBuckets 默认直方图桶（秒）
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// collector 可以输出 Prometheus 文本格式的指标
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+// Registry 指标注册表
+type Registry struct {
+	mu         sync.RWMutex
+	collectors []collector
+}
+
+// NewRegistry 创建指标注册表
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// register 注册指标
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// Write 以 Prometheus 文本格式（version 0.0.4）输出所有指标
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.RLock()
+	collectors := make([]collector, len(r.collectors))
+	copy(collectors, r.collectors)
+	r.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+	return bw.Flush()
+}
+
+// DefaultRegistry 全局指标注册表
+var DefaultRegistry = NewRegistry()
+
+// ============ Counter ============
+
+// Counter 单调递增计数器
+type Counter struct {
+	labelValues []string
+	bits        atomic.Uint64
+}
+
+// Inc 加 1
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add 增加指定值（必须为非负数）
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		return
+	}
+	for {
+		old := c.bits.Load()
+		updated := math.Float64bits(math.Float64frombits(old) + v)
+		if c.bits.CompareAndSwap(old, updated) {
+			return
+		}
+	}
+}
+
+// Value 当前值
+func (c *Counter) Value() float64 {
+	return math.Float64frombits(c.bits.Load())
+}
+
+// CounterVec 带标签的计数器
+type CounterVec struct {
+	name       string
+	help       string
+	labelNames []string
+
+	mu       sync.RWMutex
+	children map[string]*Counter
+}
+
+// NewCounterVec 创建带标签的计数器并注册到全局注册表
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		children:   make(map[string]*Counter),
+	}
+	DefaultRegistry.register(c)
+	return c
+}
+
+// WithLabelValues 获取指定标签值对应的计数器
+func (v *CounterVec) WithLabelValues(labelValues ...string) *Counter {
+	key := labelKey(labelValues)
+
+	v.mu.RLock()
+	child, ok := v.children[key]
+	v.mu.RUnlock()
+	if ok {
+		return child
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if child, ok = v.children[key]; !ok {
+		child = &Counter{labelValues: append([]string(nil), labelValues...)}
+		v.children[key] = child
+	}
+	return child
+}
+
+func (v *CounterVec) write(w *bufio.Writer) {
+	writeHeader(w, v.name, v.help, "counter")
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	for _, key := range sortedKeys(v.children) {
+		child := v.children[key]
+		writeSample(w, v.name, formatLabels(v.labelNames, child.labelValues, "", ""), child.Value())
+	}
+}
+
+// ============ Histogram ============
+
+// Histogram 直方图
+type Histogram struct {
+	labelValues []string
+	buckets     []float64
+
+	mu     sync.Mutex
+	counts []uint64 // 每个桶的计数（非累计）
+	sum    float64
+	count  uint64
+}
+
+// Observe 记录一个观测值
+func (h *Histogram) Observe(v float64) {
+	idx := sort.SearchFloat64s(h.buckets, v)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if idx < len(h.counts) {
+		h.counts[idx]++
+	}
+	h.sum += v
+	h.count++
+}
+
+// HistogramVec 带标签的直方图
+type HistogramVec struct {
+	name       string
+	help       string
+	labelNames []string
+	buckets    []float64
+
+	mu       sync.RWMutex
+	children map[string]*Histogram
+}
+
+// NewHistogramVec 创建带标签的直方图并注册到全局注册表，buckets 为空时使用 DefaultBuckets
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	h := &HistogramVec{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		buckets:    buckets,
+		children:   make(map[string]*Histogram),
+	}
+	DefaultRegistry.register(h)
+	return h
+}
+
+// WithLabelValues 获取指定标签值对应的直方图
+func (v *HistogramVec) WithLabelValues(labelValues ...string) *Histogram {
+	key := labelKey(labelValues)
+
+	v.mu.RLock()
+	child, ok := v.children[key]
+	v.mu.RUnlock()
+	if ok {
+		return child
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if child, ok = v.children[key]; !ok {
+		child = &Histogram{
+			labelValues: append([]string(nil), labelValues...),
+			buckets:     v.buckets,
+			counts:      make([]uint64, len(v.buckets)),
+		}
+		v.children[key] = child
+	}
+	return child
+}
+
+func (v *HistogramVec) write(w *bufio.Writer) {
+	writeHeader(w, v.name, v.help, "histogram")
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	for _, key := range sortedKeys(v.children) {
+		child := v.children[key]
+
+		child.mu.Lock()
+		var cumulative uint64
+		for i, upper := range child.buckets {
+			cumulative += child.counts[i]
+			labels := formatLabels(v.labelNames, child.labelValues, "le", formatFloat(upper))
+			writeSample(w, v.name+"_bucket", labels, float64(cumulative))
+		}
+		writeSample(w, v.name+"_bucket", formatLabels(v.labelNames, child.labelValues, "le", "+Inf"), float64(child.count))
+		writeSample(w, v.name+"_sum", formatLabels(v.labelNames, child.labelValues, "", ""), child.sum)
+		writeSample(w, v.name+"_count", formatLabels(v.labelNames, child.labelValues, "", ""), float64(child.count))
+		child.mu.Unlock()
+	}
+}
+
+// ============ GaugeFunc ============
+
+// GaugeFunc 采集时调用函数取值的仪表盘指标
+type GaugeFunc struct {
+	name string
+	help string
+	fn   func() float64
+}
+
+// NewGaugeFunc 创建 GaugeFunc 并注册到全局注册表
+func NewGaugeFunc(name, help string, fn func() float64) *GaugeFunc {
+	g := &GaugeFunc{
+		name: name,
+		help: help,
+		fn:   fn,
+	}
+	DefaultRegistry.register(g)
+	return g
+}
+
+func (g *GaugeFunc) write(w *bufio.Writer) {
+	writeHeader(w, g.name, g.help, "gauge")
+	writeSample(w, g.name, "", g.fn())
+}
+
+// ============ 文本格式输出 ============
+
+// labelKey 标签值组合成 map key
+func labelKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+// sortedKeys 排序后的 key，保证输出稳定
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeHeader(w *bufio.Writer, name, help, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, escapeHelp(help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+}
+
+func writeSample(w *bufio.Writer, name, labels string, value float64) {
+	w.WriteString(name)
+	w.WriteString(labels)
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+// formatLabels 格式化标签，extraName 不为空时追加一个额外标签（如直方图的 le）
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", name, escapeLabelValue(value))
+	}
+	if extraName != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", extraName, extraValue)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelEscaper.Replace(s)
+}
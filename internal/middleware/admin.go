@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"gosir/internal/common"
+	"gosir/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AdminMiddleware 管理员权限中间件（需在 AuthMiddleware 之后使用）
+// 管理员即 is_admin 标记的账号（初始化管理员账号时设置），与当前邮箱无关
+func AdminMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, ok := c.Get("user_id").(string)
+			if !ok || userID == "" {
+				return common.Error(c, common.CodeUnauthorized, "无效的认证信息")
+			}
+
+			user, err := repository.NewUserRepository().WithContext(c.Request().Context()).FindByID(userID)
+			if err != nil || !user.IsAdmin {
+				return common.Error(c, common.CodeForbidden, "需要管理员权限")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// AdminOrSelfMiddleware 管理员或路径参数 param 指定的用户本人才能访问（需在 AuthMiddleware 之后使用）
+// 在 context 中设置 is_admin，供 handler 区分管理员和用户本人可修改的字段
+func AdminOrSelfMiddleware(param string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, ok := c.Get("user_id").(string)
+			if !ok || userID == "" {
+				return common.Error(c, common.CodeUnauthorized, "无效的认证信息")
+			}
+
+			user, err := repository.NewUserRepository().WithContext(c.Request().Context()).FindByID(userID)
+			if err != nil {
+				return common.Error(c, common.CodeUnauthorized, "无效的认证信息")
+			}
+			if !user.IsAdmin && c.Param(param) != userID {
+				return common.Error(c, common.CodeForbidden, "只能访问自己的账号")
+			}
+
+			c.Set("is_admin", user.IsAdmin)
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"gosir/internal/service/audit"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AuditContextMiddleware 将客户端 IP 和 User-Agent 写入请求 context，供审计日志使用
+func AuditContextMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			c.SetRequest(req.WithContext(audit.WithClient(req.Context(), c.RealIP(), req.UserAgent())))
+			return next(c)
+		}
+	}
+}
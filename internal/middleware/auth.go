@@ -1,13 +1,28 @@
 package middleware
 
 import (
+	"errors"
 	"gosir/internal/common"
+	"gosir/internal/logger"
+	apikeymodel "gosir/internal/model/apikey"
+	"gosir/internal/repository"
+	"gosir/internal/service/apikey"
+	"gosir/internal/service/audit"
+	"gosir/internal/service/auth"
+	"slices"
 	"strings"
 
 	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
 )
 
-// AuthMiddleware JWT 认证中间件
+// passwordChangeAllowedPaths 受限 token（必须先修改密码）允许访问的路由
+var passwordChangeAllowedPaths = map[string]bool{
+	"/api/auth/password": true,
+	"/api/auth/logout":   true,
+}
+
+// AuthMiddleware 认证中间件，支持 Authorization: Bearer {jwt} 和 Authorization: ApiKey {key}
 func AuthMiddleware() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -18,33 +33,138 @@ func AuthMiddleware() echo.MiddlewareFunc {
 				return common.Error(c, common.CodeUnauthorized, "缺少 Authorization 请求头")
 			}
 
-			// 检查 Bearer 前缀
+			// 检查认证方式
 			parts := strings.SplitN(authHeader, " ", 2)
-			if len(parts) != 2 || parts[0] != "Bearer" {
-				return common.Error(c, common.CodeUnauthorized, "无效的 Authorization 格式，应为: Bearer {token}")
+			if len(parts) != 2 {
+				return common.Error(c, common.CodeUnauthorized, "无效的 Authorization 格式，应为: Bearer {token} 或 ApiKey {key}")
+			}
+			switch parts[0] {
+			case "Bearer":
+				return authenticateJWT(c, next, parts[1])
+			case "ApiKey":
+				return authenticateAPIKey(c, next, parts[1])
+			default:
+				return common.Error(c, common.CodeUnauthorized, "无效的 Authorization 格式，应为: Bearer {token} 或 ApiKey {key}")
 			}
+		}
+	}
+}
 
-			tokenString := parts[1]
+// authenticateJWT 校验 JWT
+func authenticateJWT(c echo.Context, next echo.HandlerFunc, tokenString string) error {
+	// 验证 token（包含黑名单检查）
+	jwtManager := common.GetJWTManager()
+	if jwtManager == nil {
+		return common.Error(c, common.CodeUnauthorized, "JWT 管理器未初始化")
+	}
 
-			// 验证 token（包含黑名单检查）
-			jwtManager := common.GetJWTManager()
-			if jwtManager == nil {
-				return common.Error(c, common.CodeUnauthorized, "JWT 管理器未初始化")
-			}
+	claims, err := jwtManager.ValidateToken(tokenString)
+	if err != nil {
+		if errors.Is(err, common.ErrTokenBlacklistUnavailable) {
+			logger.Ctx(c.Request().Context()).Error("Token blacklist unavailable, request rejected", zap.Error(err))
+			return common.Error(c, common.CodeInternalError, "暂时无法校验 token，请稍后重试")
+		}
+		if strings.Contains(err.Error(), "已失效") {
+			return common.Error(c, common.CodeUnauthorized, "token 已失效，请重新登录")
+		}
+		return common.ErrorWithDetail(c, common.CodeUnauthorized, "无效的 token", err)
+	}
 
-			claims, err := jwtManager.ValidateToken(tokenString)
-			if err != nil {
-				if strings.Contains(err.Error(), "已失效") {
-					return common.Error(c, common.CodeUnauthorized, "token 已失效，请重新登录")
-				}
-				return common.Error(c, common.CodeUnauthorized, "无效的 token: "+err.Error())
-			}
+	// 用户被禁用或 token 已被撤销时立即失效（客户端凭证令牌没有用户）
+	if claims.UserID != "" {
+		if _, err := auth.TokenUser(c.Request().Context(), claims); err != nil {
+			return tokenUserError(c, err)
+		}
+	}
 
-			// 将用户信息存入 context
-			c.Set("user_id", claims.UserID)
-			c.Set("claims", claims)
-			c.Set("jti", claims.JTI)
+	// 必须修改密码的用户只能访问修改密码和登出接口
+	if claims.MustChangePassword && !passwordChangeAllowedPaths[c.Path()] {
+		return common.Error(c, common.CodeForbidden, "请先修改密码")
+	}
+
+	// 将用户信息存入 context
+	c.Set("user_id", claims.UserID)
+	c.Set("claims", claims)
+	c.Set("jti", claims.JTI)
+	setAuditActor(c, claims.UserID)
 
+	return next(c)
+}
+
+// tokenUserError token 所属用户不能继续使用 token 时的错误响应
+func tokenUserError(c echo.Context, err error) error {
+	var notFound *repository.UserNotFoundError
+	switch {
+	case errors.As(err, &notFound), errors.Is(err, auth.ErrTokenRevoked):
+		return common.Error(c, common.CodeUnauthorized, "token 已失效，请重新登录")
+	case errors.Is(err, auth.ErrUserDisabled):
+		return common.Error(c, common.CodeForbidden, "用户已被禁用")
+	case errors.Is(err, auth.ErrUserNotVerified):
+		return common.Error(c, common.CodeForbidden, "邮箱尚未验证，请先点击验证邮件中的链接")
+	default:
+		return common.ErrorWithDetail(c, common.CodeInternalError, "校验用户失败", err)
+	}
+}
+
+// authenticateAPIKey 校验 API Key
+func authenticateAPIKey(c echo.Context, next echo.HandlerFunc, rawKey string) error {
+	key, _, err := apikey.NewAPIKeyService().WithContext(c.Request().Context()).Authenticate(rawKey, c.RealIP())
+	if err != nil {
+		switch {
+		case errors.Is(err, apikey.ErrAPIKeyExpired):
+			return common.Error(c, common.CodeUnauthorized, "API Key 已过期")
+		case errors.Is(err, apikey.ErrUserDisabled):
+			return common.Error(c, common.CodeForbidden, "用户已被禁用")
+		case errors.Is(err, apikey.ErrInvalidAPIKey):
+			return common.Error(c, common.CodeUnauthorized, "无效的 API Key")
+		default:
+			return common.ErrorWithDetail(c, common.CodeInternalError, "校验 API Key 失败", err)
+		}
+	}
+
+	// 将用户和 key 信息存入 context
+	c.Set("user_id", key.UserID)
+	c.Set("api_key", key)
+	setAuditActor(c, key.UserID)
+
+	return next(c)
+}
+
+// setAuditActor 将当前用户写入请求 context，审计日志以该用户为操作人
+func setAuditActor(c echo.Context, userID string) {
+	req := c.Request()
+	c.SetRequest(req.WithContext(audit.WithActor(req.Context(), userID)))
+}
+
+// RequireScope 要求 API Key 或 OAuth 访问令牌具有指定权限范围（需在 AuthMiddleware 之后使用），用户本人登录的 JWT 不受限制
+// 权限范围对应的接口都以用户身份操作，客户端凭证模式的令牌（不代表任何用户）一律拒绝
+func RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if key, ok := c.Get("api_key").(*apikeymodel.APIKey); ok && !key.HasScope(scope) {
+				return common.Error(c, common.CodeForbidden, "API Key 缺少权限: "+scope)
+			}
+			if claims, ok := c.Get("claims").(*common.JWTClaims); ok && claims.IsOAuth() && claims.UserID == "" {
+				return common.Error(c, common.CodeForbidden, "客户端凭证令牌不代表任何用户，不能访问该接口")
+			}
+			if claims, ok := c.Get("claims").(*common.JWTClaims); ok && claims.IsOAuth() && !slices.Contains(claims.Scopes(), scope) {
+				return common.Error(c, common.CodeForbidden, "访问令牌缺少权限: "+scope)
+			}
+			return next(c)
+		}
+	}
+}
+
+// DenyDelegated 拒绝通过 API Key 和 OAuth 访问令牌访问，只允许用户本人登录的 JWT（如登出、修改密码和管理 API Key 的接口）
+func DenyDelegated() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, ok := c.Get("api_key").(*apikeymodel.APIKey); ok {
+				return common.Error(c, common.CodeForbidden, "该接口不支持 API Key 访问")
+			}
+			if claims, ok := c.Get("claims").(*common.JWTClaims); ok && claims.IsOAuth() {
+				return common.Error(c, common.CodeForbidden, "该接口不支持 OAuth 访问令牌访问")
+			}
 			return next(c)
 		}
 	}
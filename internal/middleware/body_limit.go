@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
+)
+
+// BodyLimitMiddleware 请求体大小限制中间件，超过 maxMB 时返回 413，maxMB 为 0 时不限制
+func BodyLimitMiddleware(maxMB int) echo.MiddlewareFunc {
+	if maxMB <= 0 {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+	return echoMiddleware.BodyLimit(strconv.Itoa(maxMB) + "M")
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"sync/atomic"
+
+	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
+)
+
+// CORSOptions 跨域选项
+type CORSOptions struct {
+	AllowOrigins     []string // 允许的来源，* 表示全部
+	AllowMethods     []string // 允许的请求方法
+	AllowHeaders     []string // 允许的请求头，为空时允许预检请求中声明的所有请求头
+	ExposeHeaders    []string // 允许浏览器读取的响应头
+	AllowCredentials bool     // 是否允许携带凭证
+	MaxAge           int      // 预检请求结果的缓存时间（秒）
+}
+
+// corsHandler 当前生效的跨域中间件，配置变更时整体替换
+var corsHandler atomic.Pointer[echo.MiddlewareFunc]
+
+// CORSMiddleware 跨域中间件，选项可通过 SetCORSOptions 在运行时替换
+func CORSMiddleware(opts CORSOptions) echo.MiddlewareFunc {
+	SetCORSOptions(opts)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			return (*corsHandler.Load())(next)(c)
+		}
+	}
+}
+
+// SetCORSOptions 替换跨域选项，之后的请求立即使用新选项
+func SetCORSOptions(opts CORSOptions) {
+	mw := echoMiddleware.CORSWithConfig(echoMiddleware.CORSConfig{
+		AllowOrigins:     opts.AllowOrigins,
+		AllowMethods:     opts.AllowMethods,
+		AllowHeaders:     opts.AllowHeaders,
+		ExposeHeaders:    opts.ExposeHeaders,
+		AllowCredentials: opts.AllowCredentials,
+		MaxAge:           opts.MaxAge,
+	})
+	corsHandler.Store(&mw)
+}
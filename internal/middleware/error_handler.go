@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"errors"
+	"fmt"
 
 	"gosir/internal/common"
 	"gosir/internal/logger"
@@ -15,11 +16,13 @@ import (
 func ErrorHandler() echo.HTTPErrorHandler {
 	return func(err error, c echo.Context) {
 		req := c.Request()
+		log := logger.NamedCtx(req.Context(), logger.ModuleHTTP)
+		requestID := c.Response().Header().Get(echo.HeaderXRequestID)
 
 		// 检查是否是 AppError
 		var appErr *common.AppError
 		if errors.As(err, &appErr) {
-			logger.Error("AppError occurred",
+			log.Error("AppError occurred",
 				zap.String("method", req.Method),
 				zap.String("path", req.URL.Path),
 				zap.Int("code", appErr.Code),
@@ -31,6 +34,8 @@ func ErrorHandler() echo.HTTPErrorHandler {
 				Code:    appErr.Code,
 				Message: appErr.Message,
 				Data:    nil,
+
+				RequestID: requestID,
 			})
 			return
 		}
@@ -39,7 +44,7 @@ func ErrorHandler() echo.HTTPErrorHandler {
 		var httpErr *echo.HTTPError
 		if errors.As(err, &httpErr) {
 			code := common.CodeInternalError
-			message := httpErr.Message.(string)
+			message := fmt.Sprint(httpErr.Message)
 
 			switch httpErr.Code {
 			case http.StatusBadRequest:
@@ -50,34 +55,50 @@ func ErrorHandler() echo.HTTPErrorHandler {
 				code = common.CodeForbidden
 			case http.StatusNotFound:
 				code = common.CodeNotFound
+			case http.StatusRequestEntityTooLarge:
+				code = common.CodeRequestTooLarge
+			}
+			// 未开启 server.errorDetail 时服务端错误不向客户端暴露详情
+			if httpErr.Code >= http.StatusInternalServerError && !common.ErrorDetail() {
+				message = "服务器内部错误"
 			}
 
-			logger.Error("HTTPError occurred",
+			log.Error("HTTPError occurred",
 				zap.String("method", req.Method),
 				zap.String("path", req.URL.Path),
 				zap.Int("code", code),
-				zap.String("message", message),
+				zap.String("message", fmt.Sprint(httpErr.Message)),
+				zap.Error(httpErr.Internal),
 			)
 
 			_ = c.JSON(http.StatusOK, common.Response{
 				Code:    code,
 				Message: message,
 				Data:    nil,
+
+				RequestID: requestID,
 			})
 			return
 		}
 
 		// 其他未知错误
-		logger.Error("Unknown error occurred",
+		log.Error("Unknown error occurred",
 			zap.String("method", req.Method),
 			zap.String("path", req.URL.Path),
 			zap.Error(err),
 		)
 
+		// 详情已写入日志，只有开启 server.errorDetail 时才返回给客户端
+		message := "服务器内部错误"
+		if common.ErrorDetail() {
+			message += ": " + err.Error()
+		}
 		_ = c.JSON(http.StatusOK, common.Response{
 			Code:    common.CodeInternalError,
-			Message: "服务器内部错误",
+			Message: message,
 			Data:    nil,
+
+			RequestID: requestID,
 		})
 	}
 }
@@ -31,11 +31,12 @@ func ZapLoggerMiddleware() echo.MiddlewareFunc {
 				zap.String("user_agent", req.UserAgent()),
 			}
 
+			log := logger.NamedCtx(req.Context(), logger.ModuleHTTP)
 			if err != nil {
 				fields = append(fields, zap.Error(err))
-				logger.Error("request completed with error", fields...)
+				log.Error("request completed with error", fields...)
 			} else {
-				logger.Info("request completed", fields...)
+				log.Info("request completed", fields...)
 			}
 
 			return err
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"gosir/internal/metrics"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MetricsMiddleware HTTP 请求指标中间件
+// 需要注册在最外层：请求出错时先交给统一错误处理写入响应，才能统计到真实的响应状态码
+func MetricsMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			// 使用路由模板（如 /api/users/:id）作为标签，避免路径参数导致标签基数过高
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			method := c.Request().Method
+			status := strconv.Itoa(c.Response().Status)
+
+			metrics.HTTPRequestsTotal.WithLabelValues(method, route, status).Inc()
+			metrics.HTTPRequestDuration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+
+			return nil
+		}
+	}
+}
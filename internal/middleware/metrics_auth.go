@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"gosir/internal/common"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MetricsAuthMiddleware /metrics 的 HTTP Basic 认证（Prometheus 的 basic_auth 抓取配置）
+// 认证失败时返回 HTTP 401，抓取方不会把错误响应当作指标解析
+func MetricsAuthMiddleware(username, password string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, pass, ok := c.Request().BasicAuth()
+			userOK := subtle.ConstantTimeCompare([]byte(user), []byte(username)) == 1
+			passOK := subtle.ConstantTimeCompare([]byte(pass), []byte(password)) == 1
+			if !ok || !userOK || !passOK {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Basic realm="metrics"`)
+				return common.ErrorWithStatus(c, http.StatusUnauthorized, common.CodeUnauthorized, "指标接口需要认证")
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"gosir/internal/common"
+	"gosir/internal/logger"
+	"gosir/internal/metrics"
+	apikeymodel "gosir/internal/model/apikey"
+	"gosir/internal/ratelimit"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// 限流响应头
+const (
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// RateLimitMiddleware 限流中间件，group 为路由组名称（对应配置项 rateLimit.groups 中的 key）
+// 超过限制时返回 HTTP 429 和 Retry-After 响应头
+// 按用户限流时需在 AuthMiddleware 之后使用；存储异常时放行请求，记录警告日志并计入 gosir_ratelimit_store_errors_total
+func RateLimitMiddleware(group string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			rule, ok := ratelimit.RuleFor(group)
+			if !ok {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+			result, err := ratelimit.Take(ctx, rule, group+":"+rateLimitKey(c, rule.KeyBy))
+			if err != nil {
+				metrics.RateLimitStoreErrorsTotal.WithLabelValues(group).Inc()
+				logger.NamedCtx(ctx, logger.ModuleHTTP).Warn("Rate limiter unavailable, request allowed",
+					zap.String("group", group),
+					zap.Error(err),
+				)
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+			header.Set(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+			header.Set(HeaderRateLimitReset, strconv.FormatInt(result.Reset.Unix(), 10))
+
+			if !result.Allowed {
+				retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+				header.Set(echo.HeaderRetryAfter, strconv.Itoa(max(retryAfter, 1)))
+				logger.NamedCtx(ctx, logger.ModuleHTTP).Warn("Rate limit exceeded",
+					zap.String("group", group),
+					zap.String("key_by", rule.KeyBy),
+					zap.String("ip", c.RealIP()),
+					zap.String("path", c.Request().URL.Path),
+				)
+				return common.ErrorWithStatus(c, http.StatusTooManyRequests, common.CodeTooManyRequests, "请求过于频繁，请稍后再试")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// rateLimitKey 限流维度的值，无法取得用户或 API Key（由 AuthMiddleware 设置）时使用客户端 IP
+func rateLimitKey(c echo.Context, keyBy string) string {
+	switch keyBy {
+	case ratelimit.KeyByUser:
+		if userID, ok := c.Get("user_id").(string); ok && userID != "" {
+			return "user:" + userID
+		}
+	case ratelimit.KeyByAPIKey:
+		if key, ok := c.Get("api_key").(*apikeymodel.APIKey); ok {
+			return "apikey:" + key.ID
+		}
+	}
+	return "ip:" + c.RealIP()
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"gosir/internal/logger"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// maxRequestIDLength 客户端传入的请求 ID 最大长度
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware 请求 ID 中间件
+// 优先使用客户端传入的 X-Request-ID（格式不合法时重新生成），并写入响应头和请求 context
+func RequestIDMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			requestID := req.Header.Get(echo.HeaderXRequestID)
+			if !isValidRequestID(requestID) {
+				requestID = uuid.New().String()
+			}
+
+			c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+			c.Set("request_id", requestID)
+			c.SetRequest(req.WithContext(logger.WithRequestID(req.Context(), requestID)))
+
+			return next(c)
+		}
+	}
+}
+
+// isValidRequestID 检查请求 ID 是否合法（仅允许字母、数字和 -_.:，避免日志注入）
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// APIKey API Key 模型，用于批处理任务、服务间调用等机器访问
+// 完整的 key 为 gsk_{prefix}_{secret}，只保存 prefix 和 secret 的哈希
+type APIKey struct {
+	ID         string     `gorm:"primaryKey;type:varchar(36)" json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID     string     `gorm:"type:varchar(36);not null" json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"` // 所属用户
+	Name       string     `gorm:"type:varchar(100);not null" json:"name" example:"nightly-export"`                         // 名称
+	Prefix     string     `gorm:"type:varchar(16);not null" json:"prefix" example:"Ab3dEf9h"`                              // 公开前缀，用于查找和识别 key
+	SecretHash string     `gorm:"type:varchar(64);not null" json:"-"`                                                      // secret 的 SHA-256
+	Scopes     Scopes     `gorm:"type:text;not null" json:"scopes" swaggertype:"array,string" example:"users:read"`        // 权限范围
+	ExpiresAt  *time.Time `json:"expires_at" example:"2027-01-08T10:00:00Z"`                                               // 过期时间，为空表示永不过期
+	LastUsedAt *time.Time `json:"last_used_at" example:"2026-01-08T10:00:00Z"`                                             // 最近使用时间
+	LastUsedIP string     `gorm:"type:varchar(64)" json:"last_used_ip" example:"10.0.0.8"`                                 // 最近使用的客户端 IP
+	CreatedAt  time.Time  `json:"created_at" example:"2026-01-08T10:00:00Z"`
+	UpdatedAt  time.Time  `json:"updated_at" example:"2026-01-08T10:00:00Z"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// HasScope 是否具有指定权限范围
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// Expired 在 now 时是否已过期
+func (k *APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// Scopes 权限范围列表，数据库中以空格分隔保存
+type Scopes []string
+
+// Value 实现 driver.Valuer
+func (s Scopes) Value() (driver.Value, error) {
+	return strings.Join(s, " "), nil
+}
+
+// Scan 实现 sql.Scanner
+func (s *Scopes) Scan(value any) error {
+	switch v := value.(type) {
+	case string:
+		*s = strings.Fields(v)
+	case []byte:
+		*s = strings.Fields(string(v))
+	case nil:
+		*s = nil
+	default:
+		return fmt.Errorf("unsupported scopes value type %T", value)
+	}
+	return nil
+}
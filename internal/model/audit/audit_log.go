@@ -0,0 +1,65 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditLog 审计日志，记录安全相关操作和管理操作，写入后不可修改
+type AuditLog struct {
+	ID         string    `gorm:"primaryKey;type:varchar(36)" json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ActorID    string    `gorm:"type:varchar(36)" json:"actor_id" example:"550e8400-e29b-41d4-a716-446655440000"`    // 操作人，为空表示匿名（如登录失败）或命令行操作
+	Action     string    `gorm:"type:varchar(50);not null" json:"action" example:"user.update"`                      // 操作
+	TargetType string    `gorm:"type:varchar(50)" json:"target_type" example:"user"`                                 // 操作对象类型
+	TargetID   string    `gorm:"type:varchar(100)" json:"target_id" example:"550e8400-e29b-41d4-a716-446655440000"`  // 操作对象 ID（登录失败时为提交的账号）
+	Changes    Changes   `gorm:"type:text" json:"changes,omitempty" swaggertype:"object"`                            // 修改前后的字段值
+	Detail     string    `gorm:"type:varchar(255)" json:"detail,omitempty" example:"password"`                       // 补充说明，如登录方式、失败原因
+	IP         string    `gorm:"type:varchar(64)" json:"ip" example:"10.0.0.8"`                                      // 客户端 IP
+	UserAgent  string    `gorm:"type:varchar(255)" json:"user_agent" example:"Mozilla/5.0"`                          // 客户端 User-Agent
+	RequestID  string    `gorm:"type:varchar(128)" json:"request_id" example:"7f3c2a9e-1b4d-4e8a-9c2f-5d6e7f8a9b0c"` // 请求 ID
+	CreatedAt  time.Time `json:"created_at" example:"2026-01-08T10:00:00Z"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// Change 字段修改前后的值，新增时 Old 为空，删除时 New 为空
+type Change struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// Changes 字段名 -> 修改前后的值，数据库中以 JSON 保存
+type Changes map[string]Change
+
+// Value 实现 driver.Valuer
+func (c Changes) Value() (driver.Value, error) {
+	if len(c) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner
+func (c *Changes) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	case nil:
+		*c = nil
+		return nil
+	default:
+		return fmt.Errorf("unsupported changes value type %T", value)
+	}
+	return json.Unmarshal(data, c)
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// Identity 外部身份，将 OIDC 提供方的用户（provider + subject）关联到本地用户
+type Identity struct {
+	ID          string     `gorm:"primaryKey;type:varchar(36)" json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID      string     `gorm:"type:varchar(36);not null" json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"` // 本地用户
+	Provider    string     `gorm:"type:varchar(50);not null" json:"provider" example:"google"`                              // 提供方名称（配置中的名称）
+	Subject     string     `gorm:"type:varchar(255);not null" json:"subject" example:"110169484474386276334"`               // 提供方内的用户唯一标识（sub）
+	Email       string     `gorm:"type:varchar(255)" json:"email" example:"zhangsan@example.com"`                           // 最近一次登录时提供方返回的邮箱
+	LastLoginAt *time.Time `json:"last_login_at" example:"2026-01-08T10:00:00Z"`                                            // 最近一次通过该身份登录的时间
+	CreatedAt   time.Time  `json:"created_at" example:"2026-01-08T10:00:00Z"`
+	UpdatedAt   time.Time  `json:"updated_at" example:"2026-01-08T10:00:00Z"`
+}
+
+func (Identity) TableName() string {
+	return "identities"
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// 免密登录凭证类型
+const (
+	KindCode = "code" // 验证码，用户输入账号和验证码登录
+	KindLink = "link" // 登录链接，链接中带 token
+)
+
+// LoginCode 免密登录凭证（验证码或登录链接），只保存哈希，使用一次后失效
+type LoginCode struct {
+	ID         string     `gorm:"primaryKey;type:varchar(36)"`
+	UserID     string     `gorm:"type:varchar(36);not null"`
+	Kind       string     `gorm:"type:varchar(10);not null"` // code, link
+	Channel    string     `gorm:"type:varchar(10);not null"` // 发送渠道: email, sms
+	SecretHash string     `gorm:"type:varchar(64);not null"` // SHA-256(id:secret)
+	Attempts   int        `gorm:"not null;default:0"`        // 已尝试次数
+	ExpiresAt  time.Time  `gorm:"not null"`
+	UsedAt     *time.Time // 使用（或因尝试次数过多失效）的时间
+	CreatedAt  time.Time
+}
+
+func (LoginCode) TableName() string {
+	return "login_codes"
+}
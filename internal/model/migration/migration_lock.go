@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// SchemaMigrationLock 迁移锁记录模型
+// 多实例同时启动时，只有持有锁的实例才能执行迁移
+type SchemaMigrationLock struct {
+	Name       string    `gorm:"primaryKey;type:varchar(64)" json:"name"`   // 锁名称
+	Owner      string    `gorm:"type:varchar(255);not null" json:"owner"`   // 持有者标识（主机名-进程号-随机串）
+	AcquiredAt time.Time `gorm:"type:datetime;not null" json:"acquired_at"` // 获取时间
+	ExpiresAt  time.Time `gorm:"type:datetime;not null" json:"expires_at"`  // 过期时间，超过后视为失效锁
+}
+
+func (SchemaMigrationLock) TableName() string {
+	return "schema_migration_locks"
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Client OAuth 客户端（通过 gosir 登录的应用）
+type Client struct {
+	ID           string    `gorm:"primaryKey;type:varchar(64)" json:"client_id" example:"gsc_Ab3dEf9hIj2kLm4n"`                                   // client_id
+	Name         string    `gorm:"type:varchar(100);not null" json:"name" example:"工单系统"`                                                         // 名称，展示在授权页面
+	SecretHash   string    `gorm:"type:varchar(64);not null" json:"-"`                                                                            // client_secret 的 SHA-256，公开客户端为空
+	RedirectURIs List      `gorm:"type:text;not null" json:"redirect_uris" swaggertype:"array,string" example:"https://app.example.com/callback"` // 允许的回调地址（精确匹配）
+	GrantTypes   List      `gorm:"type:text;not null" json:"grant_types" swaggertype:"array,string" example:"authorization_code"`                 // 允许的授权类型
+	Scopes       List      `gorm:"type:text;not null" json:"scopes" swaggertype:"array,string" example:"openid"`                                  // 允许申请的 scope
+	SkipConsent  bool      `gorm:"not null;default:false" json:"skip_consent" example:"false"`                                                    // 第一方应用，已登录时跳过授权确认
+	CreatedAt    time.Time `json:"created_at" example:"2026-01-08T10:00:00Z"`
+	UpdatedAt    time.Time `json:"updated_at" example:"2026-01-08T10:00:00Z"`
+}
+
+func (Client) TableName() string {
+	return "oauth_clients"
+}
+
+// Public 是否为公开客户端（无法保存密钥的浏览器和移动应用，必须使用 PKCE）
+func (c *Client) Public() bool {
+	return c.SecretHash == ""
+}
+
+// AllowsGrant 是否允许使用指定授权类型
+func (c *Client) AllowsGrant(grantType string) bool {
+	return slices.Contains(c.GrantTypes, grantType)
+}
+
+// AllowsRedirectURI 回调地址是否已登记
+func (c *Client) AllowsRedirectURI(uri string) bool {
+	return slices.Contains(c.RedirectURIs, uri)
+}
+
+// List 字符串列表，数据库中以空格分隔保存（scope、授权类型和回调地址都不包含空格）
+type List []string
+
+// Value 实现 driver.Valuer
+func (l List) Value() (driver.Value, error) {
+	return strings.Join(l, " "), nil
+}
+
+// Scan 实现 sql.Scanner
+func (l *List) Scan(value any) error {
+	switch v := value.(type) {
+	case string:
+		*l = strings.Fields(v)
+	case []byte:
+		*l = strings.Fields(string(v))
+	case nil:
+		*l = nil
+	default:
+		return fmt.Errorf("unsupported list value type %T", value)
+	}
+	return nil
+}
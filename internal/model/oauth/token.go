@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// AuthorizationCode 授权码，只保存哈希
+// 换取 token 后标记为已使用，过期后才删除，以便发现重复使用并吊销用它签发的刷新令牌
+type AuthorizationCode struct {
+	CodeHash      string     `gorm:"primaryKey;type:varchar(64)"`
+	ClientID      string     `gorm:"type:varchar(64);not null"`
+	UserID        string     `gorm:"type:varchar(36);not null"`
+	RedirectURI   string     `gorm:"type:text;not null"`
+	Scopes        List       `gorm:"type:text;not null"`
+	Nonce         string     `gorm:"type:varchar(255)"`
+	CodeChallenge string     `gorm:"type:varchar(128);not null"` // PKCE S256 code_challenge
+	FamilyID      string     `gorm:"type:varchar(36);not null"`  // 用该授权码签发的刷新令牌 family
+	ExpiresAt     time.Time  `gorm:"not null"`
+	UsedAt        *time.Time // 换取 token 的时间，为空表示尚未使用
+	CreatedAt     time.Time
+}
+
+func (AuthorizationCode) TableName() string {
+	return "oauth_authorization_codes"
+}
+
+// RefreshToken 刷新令牌，只保存哈希
+// 每次刷新签发新令牌并吊销旧令牌，同一次授权产生的令牌属于同一个 family，已吊销的令牌再次使用时吊销整个 family
+type RefreshToken struct {
+	ID        string     `gorm:"primaryKey;type:varchar(36)"`
+	TokenHash string     `gorm:"type:varchar(64);not null"`
+	FamilyID  string     `gorm:"type:varchar(36);not null"`
+	ClientID  string     `gorm:"type:varchar(64);not null"`
+	UserID    string     `gorm:"type:varchar(36);not null"`
+	Scopes    List       `gorm:"type:text;not null"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	RevokedAt *time.Time // 吊销时间，为空表示有效
+	CreatedAt time.Time
+}
+
+func (RefreshToken) TableName() string {
+	return "oauth_refresh_tokens"
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// RateLimitCounter 限流计数（多实例共享）
+type RateLimitCounter struct {
+	Key       string    `gorm:"primaryKey;type:varchar(255)" json:"key"`  // 路由组:维度:值
+	Value     float64   `gorm:"not null" json:"value"`                    // 令牌桶剩余令牌数或滑动窗口当前窗口计数
+	Previous  float64   `gorm:"not null" json:"previous"`                 // 滑动窗口上一窗口计数
+	Timestamp time.Time `gorm:"type:datetime;not null" json:"timestamp"`  // 上次补充令牌的时间或当前窗口开始时间
+	ExpiresAt time.Time `gorm:"type:datetime;not null" json:"expires_at"` // 过期时间，超过后计数失效
+}
+
+func (RateLimitCounter) TableName() string {
+	return "rate_limit_counters"
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// TokenBlacklist token 黑名单模型
+type TokenBlacklist struct {
+	JTI           string    `gorm:"column:jti;primaryKey" json:"jti"` // JWT ID
+	ExpiredAt     time.Time `json:"expired_at"`                       // token 原始过期时间
+	BlacklistedAt time.Time `json:"blacklisted_at"`                   // 加入黑名单的时间
+}
+
+func (TokenBlacklist) TableName() string {
+	return "token_blacklist"
+}
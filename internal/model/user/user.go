@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -19,8 +20,21 @@ type User struct {
 	CreatedAt time.Time      `json:"created_at" example:"2026-01-08T10:00:00Z"`
 	UpdatedAt time.Time      `json:"updated_at" example:"2026-01-08T10:00:00Z"`
 	DeletedAt gorm.DeletedAt `json:"-"`
+
+	MustChangePassword bool `json:"must_change_password" example:"false"` // 登录后必须先修改密码
+
+	Version int64 `json:"version" gorm:"default:1" example:"1"` // 版本号（乐观锁），每次修改加 1
+
+	TokensRevokedAt *time.Time `json:"-"` // token 撤销时间，在此之前签发的 token 失效
+
+	IsAdmin bool `json:"is_admin" example:"false"` // 管理员，只在初始化管理员账号时设置，不能通过接口修改
 }
 
 func (User) TableName() string {
 	return "users"
 }
+
+// NormalizeEmail 规范化邮箱：去掉首尾空白并转为小写，存储和查询前都应规范化，邮箱唯一性不区分大小写
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
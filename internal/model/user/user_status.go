@@ -6,6 +6,7 @@ type UserStatus int
 const (
 	UserStatusNormal   UserStatus = 1 // 正常
 	UserStatusDisabled UserStatus = 2 // 禁用
+	UserStatusPending  UserStatus = 3 // 待验证（自助注册后尚未验证邮箱，不能登录）
 )
 
 func (s UserStatus) String() string {
@@ -14,6 +15,8 @@ func (s UserStatus) String() string {
 		return "正常"
 	case UserStatusDisabled:
 		return "禁用"
+	case UserStatusPending:
+		return "待验证"
 	default:
 		return "未知"
 	}
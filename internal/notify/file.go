@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gosir/internal/clock"
+)
+
+// FileNotifier 将通知以 JSON Lines 格式追加到文件（用于开发和测试）
+type FileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+// fileRecord 文件中的一条通知
+type fileRecord struct {
+	Time time.Time `json:"time"`
+	Message
+}
+
+// NewFileNotifier 创建文件通知发送方式
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+// Supports 支持所有渠道
+func (n *FileNotifier) Supports(string) bool {
+	return true
+}
+
+// Send 追加到文件
+func (n *FileNotifier) Send(_ context.Context, msg Message) error {
+	line, err := json.Marshal(fileRecord{Time: clock.Now(), Message: msg})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(n.path), 0o755); err != nil {
+		return fmt.Errorf("notify: create directory: %w", err)
+	}
+	f, err := os.OpenFile(n.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("notify: open file: %w", err)
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"context"
+
+	"gosir/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// LogNotifier 将通知写入日志（包含正文，仅用于开发环境）
+type LogNotifier struct{}
+
+// NewLogNotifier 创建日志通知发送方式
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+// Supports 支持所有渠道
+func (n *LogNotifier) Supports(string) bool {
+	return true
+}
+
+// Send 写入日志
+func (n *LogNotifier) Send(ctx context.Context, msg Message) error {
+	logger.NamedCtx(ctx, logger.ModuleNotify).Info("Notification",
+		zap.String("channel", msg.Channel),
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.Body),
+	)
+	return nil
+}
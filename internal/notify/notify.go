@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// 通知渠道
+const (
+	ChannelEmail = "email" // 邮件
+	ChannelSMS   = "sms"   // 短信
+)
+
+// ErrUnsupportedChannel 发送方式不支持该渠道（如 smtp 不能发送短信）
+var ErrUnsupportedChannel = errors.New("notify: unsupported channel")
+
+// Message 通知内容
+type Message struct {
+	Channel string `json:"channel"` // email, sms
+	To      string `json:"to"`      // 邮箱或手机号
+	Subject string `json:"subject"` // 标题（短信忽略）
+	Body    string `json:"body"`    // 正文（纯文本）
+}
+
+// Notifier 通知发送方式
+type Notifier interface {
+	// Supports 是否支持该渠道
+	Supports(channel string) bool
+	// Send 发送通知
+	Send(ctx context.Context, msg Message) error
+}
+
+// notifier 全局通知发送方式
+var notifier atomic.Pointer[Notifier]
+
+// Init 设置全局通知发送方式
+func Init(n Notifier) {
+	notifier.Store(&n)
+}
+
+// Supports 全局通知发送方式是否支持该渠道，未初始化时返回 false
+func Supports(channel string) bool {
+	n := notifier.Load()
+	return n != nil && (*n).Supports(channel)
+}
+
+// Send 使用全局通知发送方式发送通知
+func Send(ctx context.Context, msg Message) error {
+	n := notifier.Load()
+	if n == nil {
+		return errors.New("notify: notifier not initialized")
+	}
+	if !(*n).Supports(msg.Channel) {
+		return ErrUnsupportedChannel
+	}
+	return (*n).Send(ctx, msg)
+}
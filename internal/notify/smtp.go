@@ -0,0 +1,151 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"gosir/internal/clock"
+)
+
+// SMTP 加密方式
+const (
+	SMTPStartTLS = "starttls" // 明文连接后升级为 TLS（通常为 587 端口）
+	SMTPTLS      = "tls"      // 隐式 TLS（通常为 465 端口）
+	SMTPNone     = "none"     // 不加密（仅用于本地测试服务器）
+)
+
+// smtpTimeout 单次发送的超时时间
+const smtpTimeout = 30 * time.Second
+
+// SMTPOptions SMTP 发送选项
+type SMTPOptions struct {
+	Host     string
+	Port     int
+	Username string // 为空表示不认证
+	Password string
+	From     string
+	TLS      string // starttls, tls, none
+}
+
+// SMTPNotifier 通过 SMTP 发送邮件，不支持短信
+type SMTPNotifier struct {
+	opts SMTPOptions
+}
+
+// NewSMTPNotifier 创建 SMTP 通知发送方式
+func NewSMTPNotifier(opts SMTPOptions) (*SMTPNotifier, error) {
+	if _, err := mail.ParseAddress(opts.From); err != nil {
+		return nil, fmt.Errorf("notify: invalid from address %q: %w", opts.From, err)
+	}
+	return &SMTPNotifier{opts: opts}, nil
+}
+
+// Supports 只支持邮件
+func (n *SMTPNotifier) Supports(channel string) bool {
+	return channel == ChannelEmail
+}
+
+// Send 发送邮件
+func (n *SMTPNotifier) Send(ctx context.Context, msg Message) error {
+	from, _ := mail.ParseAddress(n.opts.From)
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("notify: invalid recipient %q: %w", msg.To, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	client, err := n.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if n.opts.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.opts.Username, n.opts.Password, n.opts.Host)); err != nil {
+			return fmt.Errorf("notify: smtp auth: %w", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("notify: smtp MAIL FROM: %w", err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return fmt.Errorf("notify: smtp RCPT TO: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("notify: smtp DATA: %w", err)
+	}
+	if _, err := w.Write(buildMail(from, to, msg)); err != nil {
+		return fmt.Errorf("notify: write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("notify: smtp DATA: %w", err)
+	}
+	return client.Quit()
+}
+
+// dial 连接 SMTP 服务器，按配置使用隐式 TLS 或 STARTTLS
+func (n *SMTPNotifier) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(n.opts.Host, strconv.Itoa(n.opts.Port))
+	tlsConfig := &tls.Config{ServerName: n.opts.Host, MinVersion: tls.VersionTLS12}
+
+	var conn net.Conn
+	var err error
+	if n.opts.TLS == SMTPTLS {
+		dialer := &tls.Dialer{Config: tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("notify: connect smtp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, n.opts.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("notify: smtp handshake: %w", err)
+	}
+	if n.opts.TLS == SMTPStartTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("notify: smtp STARTTLS: %w", err)
+		}
+	}
+	return client, nil
+}
+
+// buildMail 构建 UTF-8 纯文本邮件，正文使用 base64 编码
+func buildMail(from, to *mail.Address, msg Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", clock.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	body := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(body) > 76 {
+		buf.WriteString(body[:76] + "\r\n")
+		body = body[76:]
+	}
+	buf.WriteString(body + "\r\n")
+	return buf.Bytes()
+}
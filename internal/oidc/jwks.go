@@ -0,0 +1,176 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minRefreshInterval 遇到未知 kid 时重新获取 JWKS 的最小间隔，避免伪造的 token 触发大量请求
+const minRefreshInterval = time.Minute
+
+// jwk JSON Web Key（只解析签名校验用到的字段）
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwks JSON Web Key Set
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// keySet 提供方的签名公钥，提供方轮换密钥后遇到未知 kid 时重新获取
+type keySet struct {
+	uri   string
+	fetch func(ctx context.Context, url string, out any) error
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// newKeySet 创建公钥集合
+func newKeySet(uri string, fetch func(ctx context.Context, url string, out any) error) *keySet {
+	return &keySet{uri: uri, fetch: fetch}
+}
+
+// Key 获取 kid 对应的公钥，token 没有 kid 时只在提供方仅有一个公钥时使用该公钥
+func (s *keySet) Key(ctx context.Context, kid, alg string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.lookup(kid)
+	if !ok && time.Since(s.fetchedAt) >= minRefreshInterval {
+		if err := s.refresh(ctx); err != nil {
+			return nil, err
+		}
+		key, ok = s.lookup(kid)
+	}
+	if !ok {
+		return nil, fmt.Errorf("no signing key for kid %q", kid)
+	}
+
+	// 公钥类型必须与签名算法匹配
+	switch key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") && !strings.HasPrefix(alg, "PS") {
+			return nil, fmt.Errorf("key %q is RSA but token uses %s", kid, alg)
+		}
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return nil, fmt.Errorf("key %q is EC but token uses %s", kid, alg)
+		}
+	}
+	return key, nil
+}
+
+// lookup 查找已缓存的公钥
+func (s *keySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" {
+		if len(s.keys) != 1 {
+			return nil, false
+		}
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// refresh 重新获取 JWKS，跳过不支持的公钥
+func (s *keySet) refresh(ctx context.Context) error {
+	s.fetchedAt = time.Now()
+
+	var set jwks
+	if err := s.fetch(ctx, s.uri, &set); err != nil {
+		return fmt.Errorf("fetch jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	s.keys = keys
+	return nil
+}
+
+// publicKey 解析 RSA 或 EC 公钥
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("EC point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// decodeBigInt 解码 base64url 编码的大整数
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64url value: %w", err)
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("empty key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
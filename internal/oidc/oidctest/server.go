@@ -0,0 +1,270 @@
+// Package oidctest 用于测试的 OIDC 提供方，提供 discovery、授权、令牌和 JWKS 端点
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// ClientID 测试客户端 ID
+	ClientID = "gosir-test"
+	// ClientSecret 测试客户端密钥
+	ClientSecret = "gosir-test-secret"
+	// KeyID 签名公钥的 kid
+	KeyID = "test-key"
+)
+
+// authRequest 授权时保存的参数，换取令牌时校验
+type authRequest struct {
+	redirectURI string
+	challenge   string
+	nonce       string
+}
+
+// Server 模拟的 OIDC 提供方
+// 授权端点直接同意授权并跳转回 redirect_uri，令牌端点校验客户端认证、redirect_uri 和 PKCE 后签发 ID Token
+type Server struct {
+	*httptest.Server
+
+	// Key ID Token 签名私钥，公钥通过 JWKS 端点发布
+	Key *rsa.PrivateKey
+	// Claims 签发的 ID Token 声明，覆盖默认声明（iss、aud、sub、exp、iat、nonce、email 等），值为 nil 表示删除该声明
+	// 在换取令牌前设置
+	Claims map[string]any
+	// AuthMethods discovery 文档中的 token_endpoint_auth_methods_supported，为空时不返回
+	AuthMethods []string
+
+	mu    sync.Mutex
+	codes map[string]authRequest
+}
+
+// NewServer 启动模拟的提供方，测试结束时关闭
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	s := &Server{Key: key, codes: map[string]authRequest{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("GET /authorize", s.authorize)
+	mux.HandleFunc("POST /token", s.token)
+	mux.HandleFunc("GET /jwks", s.jwks)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Authorize 模拟用户在提供方登录并同意授权，返回回调中的 code 和 state
+func (s *Server) Authorize(authURL string) (code, state string, err error) {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		return "", "", fmt.Errorf("authorize: unexpected status %d", resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return "", "", err
+	}
+	q := location.Query()
+	return q.Get("code"), q.Get("state"), nil
+}
+
+// IDToken 使用 Key 签发 ID Token，声明为默认声明加上 Claims 和 extra（extra 优先）
+func (s *Server) IDToken(nonce string, extra map[string]any) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            s.URL,
+		"aud":            ClientID,
+		"sub":            "user-1",
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          nonce,
+		"email":          "alice@example.com",
+		"email_verified": true,
+		"name":           "Alice",
+	}
+	for _, overrides := range []map[string]any{s.Claims, extra} {
+		for k, v := range overrides {
+			if v == nil {
+				delete(claims, k)
+				continue
+			}
+			claims[k] = v
+		}
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = KeyID
+	return token.SignedString(s.Key)
+}
+
+// discovery 提供方元数据
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	metadata := map[string]any{
+		"issuer":                 s.URL,
+		"authorization_endpoint": s.URL + "/authorize",
+		"token_endpoint":         s.URL + "/token",
+		"jwks_uri":               s.URL + "/jwks",
+	}
+	if len(s.AuthMethods) > 0 {
+		metadata["token_endpoint_auth_methods_supported"] = s.AuthMethods
+	}
+	writeJSON(w, http.StatusOK, metadata)
+}
+
+// authorize 授权端点：校验参数后签发授权码并跳转回 redirect_uri
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	switch {
+	case q.Get("response_type") != "code":
+		http.Error(w, "unsupported response_type", http.StatusBadRequest)
+		return
+	case q.Get("client_id") != ClientID:
+		http.Error(w, "unknown client", http.StatusBadRequest)
+		return
+	case q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "":
+		http.Error(w, "PKCE S256 required", http.StatusBadRequest)
+		return
+	}
+
+	code := rand.Text()
+	s.mu.Lock()
+	s.codes[code] = authRequest{
+		redirectURI: q.Get("redirect_uri"),
+		challenge:   q.Get("code_challenge"),
+		nonce:       q.Get("nonce"),
+	}
+	s.mu.Unlock()
+
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+// token 令牌端点：授权码只能使用一次
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request", err.Error())
+		return
+	}
+	if err := authenticateClient(r); err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client", "error_description": err.Error()})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "unsupported_grant_type", "")
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	req, ok := s.codes[code]
+	delete(s.codes, code)
+	s.mu.Unlock()
+	switch {
+	case !ok:
+		tokenError(w, "invalid_grant", "unknown or used code")
+		return
+	case r.PostForm.Get("redirect_uri") != req.redirectURI:
+		tokenError(w, "invalid_grant", "redirect_uri mismatch")
+		return
+	case codeChallenge(r.PostForm.Get("code_verifier")) != req.challenge:
+		tokenError(w, "invalid_grant", "PKCE verification failed")
+		return
+	}
+
+	idToken, err := s.IDToken(req.nonce, nil)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": rand.Text(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// jwks 签名公钥
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := s.Key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": KeyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+// authenticateClient 校验客户端认证（client_secret_basic 或 client_secret_post）
+func authenticateClient(r *http.Request) error {
+	id, secret, ok := r.BasicAuth()
+	if ok {
+		var err error
+		if id, err = url.QueryUnescape(id); err != nil {
+			return err
+		}
+		if secret, err = url.QueryUnescape(secret); err != nil {
+			return err
+		}
+	} else {
+		id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if id != ClientID || secret != ClientSecret {
+		return errors.New("client authentication failed")
+	}
+	return nil
+}
+
+// codeChallenge 计算 PKCE S256 code_challenge
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func tokenError(w http.ResponseWriter, code, description string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code, "error_description": description})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,293 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gosir/internal/clock"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultScopes 未配置 scope 时请求的 scope
+var DefaultScopes = []string{"openid", "email", "profile"}
+
+const (
+	httpTimeout     = 10 * time.Second // 请求提供方的超时时间
+	maxResponseSize = 1 << 20          // 提供方响应的最大大小
+	clockSkew       = time.Minute      // 校验 ID Token 时间时允许的时钟偏差
+)
+
+// signingMethods ID Token 允许的签名算法（不允许 none 和对称签名）
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+var (
+	// ErrInvalidIDToken ID Token 签名、签发者、受众、有效期或 nonce 校验失败
+	ErrInvalidIDToken = errors.New("invalid id token")
+)
+
+// Config 提供方配置
+type Config struct {
+	Issuer       string   // 签发者地址，通过 {issuer}/.well-known/openid-configuration 获取端点
+	ClientID     string   // 客户端 ID
+	ClientSecret string   // 客户端密钥，公开客户端（只使用 PKCE）为空
+	RedirectURL  string   // 回调地址，需要在提供方登记
+	Scopes       []string // 请求的 scope，为空时使用 DefaultScopes
+
+	AutoCreate     bool     // 首次登录时自动创建用户
+	LinkByEmail    bool     // 首次登录时关联邮箱相同的已有用户（要求邮箱已验证）
+	AllowedDomains []string // 允许登录的邮箱域名，为空表示不限制
+}
+
+// Metadata 提供方元数据（discovery 文档中用到的字段）
+type Metadata struct {
+	Issuer                   string   `json:"issuer"`
+	AuthorizationEndpoint    string   `json:"authorization_endpoint"`
+	TokenEndpoint            string   `json:"token_endpoint"`
+	JWKSURI                  string   `json:"jwks_uri"`
+	TokenEndpointAuthMethods []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+// Claims ID Token 中用于识别和创建用户的声明
+type Claims struct {
+	Subject       string // 提供方内的用户唯一标识
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// idTokenClaims ID Token 声明
+type idTokenClaims struct {
+	Nonce           string `json:"nonce"`
+	AuthorizedParty string `json:"azp"`
+	Email           string `json:"email"`
+	EmailVerified   any    `json:"email_verified"` // 部分提供方返回字符串 "true"
+	Name            string `json:"name"`
+	Picture         string `json:"picture"`
+	jwt.RegisteredClaims
+}
+
+// tokenResponse 令牌端点响应
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Provider OIDC 提供方客户端，元数据在首次使用时获取并缓存
+type Provider struct {
+	name   string
+	cfg    Config
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata *Metadata
+	keys     *keySet
+}
+
+// NewProvider 创建提供方客户端
+func NewProvider(name string, cfg Config) *Provider {
+	cfg.Issuer = strings.TrimSuffix(cfg.Issuer, "/")
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = DefaultScopes
+	} else if !slices.Contains(cfg.Scopes, "openid") {
+		cfg.Scopes = append([]string{"openid"}, cfg.Scopes...)
+	}
+	return &Provider{
+		name:   name,
+		cfg:    cfg,
+		client: &http.Client{Timeout: httpTimeout},
+	}
+}
+
+// Name 提供方名称
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// Config 提供方配置
+func (p *Provider) Config() Config {
+	return p.cfg
+}
+
+// Metadata 获取提供方元数据，获取失败时下次调用重试
+func (p *Provider) Metadata(ctx context.Context) (*Metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var m Metadata
+	if err := p.getJSON(ctx, p.cfg.Issuer+"/.well-known/openid-configuration", &m); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	if strings.TrimSuffix(m.Issuer, "/") != p.cfg.Issuer {
+		return nil, fmt.Errorf("discovery: issuer mismatch, expected %q got %q", p.cfg.Issuer, m.Issuer)
+	}
+	if m.AuthorizationEndpoint == "" || m.TokenEndpoint == "" || m.JWKSURI == "" {
+		return nil, errors.New("discovery: missing authorization_endpoint, token_endpoint or jwks_uri")
+	}
+	p.metadata = &m
+	p.keys = newKeySet(m.JWKSURI, p.getJSON)
+	return p.metadata, nil
+}
+
+// AuthCodeURL 构建授权地址（authorization code + PKCE S256）
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	m, err := p.Metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(m.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization_endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", CodeChallenge(verifier))
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Exchange 使用授权码和 PKCE verifier 换取 ID Token
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (string, error) {
+	m, err := p.Metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	// 默认使用 HTTP Basic 传递客户端密钥，提供方声明不支持时放在表单中（client_secret_post）
+	useBasic := p.cfg.ClientSecret != ""
+	if len(m.TokenEndpointAuthMethods) > 0 && !slices.Contains(m.TokenEndpointAuthMethods, "client_secret_basic") {
+		useBasic = false
+	}
+	if !useBasic {
+		form.Set("client_id", p.cfg.ClientID)
+		if p.cfg.ClientSecret != "" {
+			form.Set("client_secret", p.cfg.ClientSecret)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if useBasic {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token tokenResponse
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return "", fmt.Errorf("token response: %w", err)
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("token response: status %d: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || token.Error != "" {
+		return "", fmt.Errorf("token request failed: status %d: %s %s", resp.StatusCode, token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return "", errors.New("token response has no id_token")
+	}
+	return token.IDToken, nil
+}
+
+// VerifyIDToken 校验 ID Token 的签名、签发者、受众、有效期和 nonce
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*Claims, error) {
+	m, err := p.Metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims idTokenClaims
+	_, err = jwt.ParseWithClaims(rawIDToken, &claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.keys.Key(ctx, kid, token.Method.Alg())
+	},
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(m.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+		jwt.WithTimeFunc(clock.Now),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	// 受众包含多个客户端时 azp 必须是当前客户端
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.cfg.ClientID {
+		return nil, fmt.Errorf("%w: azp %q does not match client", ErrInvalidIDToken, claims.AuthorizedParty)
+	}
+	if claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrInvalidIDToken)
+	}
+
+	return &Claims{
+		Subject:       claims.Subject,
+		Email:         strings.ToLower(strings.TrimSpace(claims.Email)),
+		EmailVerified: claims.EmailVerified == true || claims.EmailVerified == "true",
+		Name:          claims.Name,
+		Picture:       claims.Picture,
+	}, nil
+}
+
+// getJSON 请求提供方并解析 JSON 响应
+func (p *Provider) getJSON(ctx context.Context, rawURL string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", rawURL, resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(out); err != nil {
+		return fmt.Errorf("GET %s: %w", rawURL, err)
+	}
+	return nil
+}
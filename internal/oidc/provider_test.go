@@ -0,0 +1,230 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"gosir/internal/oidc/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testRedirectURL = "https://app.example.com/auth/oidc/mock/callback"
+
+// newTestProvider 创建连接到模拟提供方的客户端
+func newTestProvider(srv *oidctest.Server) *Provider {
+	return NewProvider("mock", Config{
+		Issuer:       srv.URL,
+		ClientID:     oidctest.ClientID,
+		ClientSecret: oidctest.ClientSecret,
+		RedirectURL:  testRedirectURL,
+	})
+}
+
+// login 完成授权：构建授权地址、模拟用户同意并换取 ID Token
+func login(t *testing.T, srv *oidctest.Server, p *Provider, nonce string) string {
+	t.Helper()
+	ctx := context.Background()
+	verifier, err := RandomString(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := p.AuthCodeURL(ctx, "state-1", nonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error: %v", err)
+	}
+	code, state, err := srv.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize() error: %v", err)
+	}
+	if state != "state-1" {
+		t.Fatalf("state = %q, want %q", state, "state-1")
+	}
+	idToken, err := p.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange() error: %v", err)
+	}
+	return idToken
+}
+
+func TestProviderLogin(t *testing.T) {
+	tests := []struct {
+		name        string
+		authMethods []string
+	}{
+		{name: "client_secret_basic"},
+		{name: "client_secret_post", authMethods: []string{"client_secret_post"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := oidctest.NewServer(t)
+			srv.AuthMethods = tt.authMethods
+			srv.Claims = map[string]any{"email": " Alice@Example.COM ", "email_verified": "true"}
+			p := newTestProvider(srv)
+
+			idToken := login(t, srv, p, "nonce-1")
+			claims, err := p.VerifyIDToken(context.Background(), idToken, "nonce-1")
+			if err != nil {
+				t.Fatalf("VerifyIDToken() error: %v", err)
+			}
+			want := Claims{Subject: "user-1", Email: "alice@example.com", EmailVerified: true, Name: "Alice"}
+			if *claims != want {
+				t.Errorf("claims = %+v, want %+v", *claims, want)
+			}
+		})
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	srv := oidctest.NewServer(t)
+	p := newTestProvider(srv)
+
+	authURL, err := p.AuthCodeURL(context.Background(), "s", "n", "verifier")
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error: %v", err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             oidctest.ClientID,
+		"redirect_uri":          testRedirectURL,
+		"scope":                 "openid email profile",
+		"state":                 "s",
+		"nonce":                 "n",
+		"code_challenge":        CodeChallenge("verifier"),
+		"code_challenge_method": "S256",
+	}
+	for k, v := range want {
+		if got := q.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestExchangeRejected(t *testing.T) {
+	ctx := context.Background()
+	srv := oidctest.NewServer(t)
+	p := newTestProvider(srv)
+
+	authURL, err := p.AuthCodeURL(ctx, "s", "n", "right-verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _, err := srv.Authorize(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Exchange(ctx, code, "wrong-verifier"); err == nil {
+		t.Error("Exchange() with wrong PKCE verifier succeeded")
+	}
+	// 授权码只能使用一次（包括失败的尝试）
+	if _, err := p.Exchange(ctx, code, "right-verifier"); err == nil {
+		t.Error("Exchange() with used code succeeded")
+	}
+
+	wrongSecret := NewProvider("mock", Config{
+		Issuer:       srv.URL,
+		ClientID:     oidctest.ClientID,
+		ClientSecret: "wrong",
+		RedirectURL:  testRedirectURL,
+	})
+	authURL, err = wrongSecret.AuthCodeURL(ctx, "s", "n", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _, err = srv.Authorize(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wrongSecret.Exchange(ctx, code, "verifier"); err == nil {
+		t.Error("Exchange() with wrong client secret succeeded")
+	}
+}
+
+func TestVerifyIDTokenRejected(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		nonce string                                     // 校验时期望的 nonce
+		extra map[string]any                             // 覆盖的声明
+		sign  func(claims jwt.MapClaims) (string, error) // 为空时由模拟提供方签发
+	}{
+		{name: "nonce 不匹配", nonce: "other-nonce"},
+		{name: "受众不是当前客户端", extra: map[string]any{"aud": "other-client"}},
+		{name: "多个受众且 azp 不是当前客户端", extra: map[string]any{"aud": []string{oidctest.ClientID, "other"}, "azp": "other"}},
+		{name: "签发者不匹配", extra: map[string]any{"iss": "https://evil.example.com"}},
+		{name: "已过期", extra: map[string]any{"exp": time.Now().Add(-time.Hour).Unix()}},
+		{name: "缺少 exp", extra: map[string]any{"exp": nil}},
+		{name: "缺少 sub", extra: map[string]any{"sub": nil}},
+		{
+			name: "其他密钥签名",
+			sign: func(claims jwt.MapClaims) (string, error) {
+				token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+				token.Header["kid"] = oidctest.KeyID
+				return token.SignedString(otherKey)
+			},
+		},
+		{
+			name: "对称签名",
+			sign: func(claims jwt.MapClaims) (string, error) {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+				token.Header["kid"] = oidctest.KeyID
+				return token.SignedString([]byte(oidctest.ClientSecret))
+			},
+		},
+		{
+			name: "未签名",
+			sign: func(claims jwt.MapClaims) (string, error) {
+				token := jwt.NewWithClaims(jwt.SigningMethodNone, claims)
+				return token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := oidctest.NewServer(t)
+			p := newTestProvider(srv)
+			// 先完成一次登录，确保元数据和 JWKS 已获取
+			if _, err := p.VerifyIDToken(context.Background(), login(t, srv, p, "nonce-1"), "nonce-1"); err != nil {
+				t.Fatalf("VerifyIDToken() valid token error: %v", err)
+			}
+
+			var idToken string
+			var err error
+			if tt.sign != nil {
+				idToken, err = tt.sign(jwt.MapClaims{
+					"iss": srv.URL, "aud": oidctest.ClientID, "sub": "user-1", "nonce": "nonce-1",
+					"iat": time.Now().Unix(), "exp": time.Now().Add(time.Minute).Unix(),
+				})
+			} else {
+				idToken, err = srv.IDToken("nonce-1", tt.extra)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			nonce := tt.nonce
+			if nonce == "" {
+				nonce = "nonce-1"
+			}
+			if _, err := p.VerifyIDToken(context.Background(), idToken, nonce); !errors.Is(err, ErrInvalidIDToken) {
+				t.Errorf("VerifyIDToken() error = %v, want %v", err, ErrInvalidIDToken)
+			}
+		})
+	}
+}
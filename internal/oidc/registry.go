@@ -0,0 +1,44 @@
+package oidc
+
+import (
+	"slices"
+	"sync"
+)
+
+// 全局提供方注册表
+var (
+	mu        sync.RWMutex
+	providers = map[string]*Provider{}
+)
+
+// Init 按配置注册提供方，替换已注册的提供方
+func Init(configs map[string]Config) {
+	next := make(map[string]*Provider, len(configs))
+	for name, cfg := range configs {
+		next[name] = NewProvider(name, cfg)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	providers = next
+}
+
+// Get 获取提供方
+func Get(name string) (*Provider, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	p, ok := providers[name]
+	return p, ok
+}
+
+// Names 已注册的提供方名称（按名称排序）
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"gosir/internal/clock"
+)
+
+// limiter 全局限流器
+var limiter struct {
+	store atomic.Pointer[Store]
+	rules atomic.Pointer[map[string]Rule]
+}
+
+// Init 初始化限流器，rules 为路由组名称 -> 限流规则
+func Init(store Store, rules map[string]Rule) {
+	limiter.store.Store(&store)
+	SetRules(rules)
+}
+
+// SetRules 替换限流规则，已有的计数保留
+func SetRules(rules map[string]Rule) {
+	copied := make(map[string]Rule, len(rules))
+	for group, rule := range rules {
+		copied[group] = rule
+	}
+	limiter.rules.Store(&copied)
+}
+
+// RuleFor 获取路由组的限流规则，未初始化、未配置或 Limit 为 0 时返回 false
+func RuleFor(group string) (Rule, bool) {
+	rules := limiter.rules.Load()
+	if rules == nil {
+		return Rule{}, false
+	}
+	rule, ok := (*rules)[group]
+	if !ok || rule.Limit <= 0 || rule.Period <= 0 {
+		return Rule{}, false
+	}
+	return rule, true
+}
+
+// Take 按规则消耗 key 的一次请求额度
+func Take(ctx context.Context, rule Rule, key string) (Result, error) {
+	store := limiter.store.Load()
+	if store == nil {
+		return Result{}, fmt.Errorf("rate limiter not initialized")
+	}
+
+	var result Result
+	err := (*store).Update(ctx, key, func(state *State) {
+		result = rule.take(state, clock.Now())
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return result, nil
+}
+
+// DeleteExpired 删除已过期的计数
+func DeleteExpired(ctx context.Context) (int64, error) {
+	store := limiter.store.Load()
+	if store == nil {
+		return 0, nil
+	}
+	return (*store).DeleteExpired(ctx)
+}
@@ -0,0 +1,49 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+
+	"gosir/internal/clock"
+)
+
+// MemoryStore 内存存储，只在当前实例内生效
+type MemoryStore struct {
+	mu     sync.Mutex
+	states map[string]State
+}
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: make(map[string]State)}
+}
+
+// Update 原子地更新 key 的状态
+func (s *MemoryStore) Update(_ context.Context, key string, fn func(state *State)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.states[key]
+	if ok && clock.Now().After(state.ExpiresAt) {
+		state = State{}
+	}
+	fn(&state)
+	s.states[key] = state
+	return nil
+}
+
+// DeleteExpired 删除已过期的状态
+func (s *MemoryStore) DeleteExpired(_ context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := clock.Now()
+	var deleted int64
+	for key, state := range s.states {
+		if now.After(state.ExpiresAt) {
+			delete(s.states, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
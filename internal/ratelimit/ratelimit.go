@@ -0,0 +1,151 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// 限流算法
+const (
+	AlgorithmTokenBucket   = "token_bucket"   // 令牌桶：按固定速率补充令牌，允许 Burst 大小的突发请求
+	AlgorithmSlidingWindow = "sliding_window" // 滑动窗口：按上一窗口和当前窗口的计数加权估算最近一个周期的请求数
+)
+
+// 路由组（配置项 rateLimit.groups 中的 key）
+const (
+	GroupAuth  = "auth"  // 公开的认证接口 /auth/*
+	GroupAPI   = "api"   // 受保护接口 /api/*
+	GroupAdmin = "admin" // 管理员接口 /api/admin/*
+)
+
+// 限流维度
+const (
+	KeyByIP     = "ip"     // 客户端 IP
+	KeyByUser   = "user"   // 用户 ID（未登录时使用 IP）
+	KeyByAPIKey = "apikey" // 已认证的 API Key（JWT 或未登录请求使用 IP）
+)
+
+// Rule 限流规则
+type Rule struct {
+	Algorithm string        // token_bucket, sliding_window
+	KeyBy     string        // ip, user, apikey
+	Limit     int           // 每个周期允许的请求数，0 表示不限流
+	Period    time.Duration // 周期
+	Burst     int           // 令牌桶容量，0 表示与 Limit 相同（仅 token_bucket）
+}
+
+// Result 限流结果
+type Result struct {
+	Allowed    bool
+	Limit      int           // 周期内允许的请求数（令牌桶为容量）
+	Remaining  int           // 剩余可用请求数
+	Reset      time.Time     // 额度完全恢复的时间
+	RetryAfter time.Duration // 被拒绝时距离下一次可以请求的时间
+}
+
+// State 单个限流 key 的计数状态，由 Store 持久化
+// token_bucket: Value 为剩余令牌数，Timestamp 为上次补充令牌的时间
+// sliding_window: Value 为当前窗口计数，Previous 为上一窗口计数，Timestamp 为当前窗口开始时间
+type State struct {
+	Value     float64
+	Previous  float64
+	Timestamp time.Time
+	ExpiresAt time.Time // 超过该时间后状态等同于初始状态，可以删除
+}
+
+// Store 限流计数存储
+type Store interface {
+	// Update 原子地读取、修改并保存 key 的状态，key 不存在或已过期时 fn 收到零值
+	Update(ctx context.Context, key string, fn func(state *State)) error
+	// DeleteExpired 删除已过期的状态
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// take 按规则消耗一次请求额度并更新状态
+func (r Rule) take(state *State, now time.Time) Result {
+	if r.Algorithm == AlgorithmSlidingWindow {
+		return r.takeSlidingWindow(state, now)
+	}
+	return r.takeTokenBucket(state, now)
+}
+
+// capacity 令牌桶容量
+func (r Rule) capacity() float64 {
+	if r.Burst > 0 {
+		return float64(r.Burst)
+	}
+	return float64(r.Limit)
+}
+
+// takeTokenBucket 令牌桶
+func (r Rule) takeTokenBucket(state *State, now time.Time) Result {
+	capacity := r.capacity()
+	rate := float64(r.Limit) / r.Period.Seconds() // 每秒补充的令牌数
+
+	tokens := capacity
+	if !state.Timestamp.IsZero() {
+		elapsed := now.Sub(state.Timestamp).Seconds()
+		tokens = math.Min(capacity, state.Value+math.Max(elapsed, 0)*rate)
+	}
+
+	result := Result{Limit: int(capacity)}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - tokens) / rate)
+	}
+	result.Remaining = int(tokens)
+	result.Reset = now.Add(secondsToDuration((capacity - tokens) / rate))
+
+	state.Value = tokens
+	state.Timestamp = now
+	state.ExpiresAt = result.Reset
+	return result
+}
+
+// takeSlidingWindow 滑动窗口（计数加权近似）
+func (r Rule) takeSlidingWindow(state *State, now time.Time) Result {
+	windowStart := now.Truncate(r.Period)
+	if !state.Timestamp.Equal(windowStart) {
+		if state.Timestamp.Equal(windowStart.Add(-r.Period)) {
+			state.Previous = state.Value
+		} else {
+			state.Previous = 0
+		}
+		state.Value = 0
+		state.Timestamp = windowStart
+	}
+
+	elapsed := now.Sub(windowStart)
+	weight := 1 - elapsed.Seconds()/r.Period.Seconds()
+	estimated := state.Previous*weight + state.Value
+	limit := float64(r.Limit)
+
+	result := Result{Limit: r.Limit, Reset: windowStart.Add(r.Period)}
+	if estimated+1 <= limit {
+		state.Value++
+		estimated++
+		result.Allowed = true
+	} else if state.Value+1 > limit {
+		// 当前窗口已满，等到下一个窗口
+		result.RetryAfter = windowStart.Add(r.Period).Sub(now)
+	} else {
+		// 等上一窗口的权重降到足够低
+		wait := r.Period.Seconds() * (1 - (limit-state.Value-1)/state.Previous)
+		result.RetryAfter = secondsToDuration(wait) - elapsed
+	}
+	result.Remaining = max(int(limit-estimated), 0)
+	if result.RetryAfter < 0 {
+		result.RetryAfter = 0
+	}
+
+	state.ExpiresAt = windowStart.Add(2 * r.Period)
+	return result
+}
+
+// secondsToDuration 将秒数转换为 time.Duration
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gosir/internal/database"
+	apikeymodel "gosir/internal/model/apikey"
+
+	"gorm.io/gorm"
+)
+
+// ErrAPIKeyNotFound API Key 不存在（或不属于该用户）
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKeyRepository API Key 仓储层
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository 创建 API Key 仓储实例
+func NewAPIKeyRepository() *APIKeyRepository {
+	return &APIKeyRepository{
+		db: database.DB,
+	}
+}
+
+// WithContext 返回绑定 context 的仓储实例
+func (r *APIKeyRepository) WithContext(ctx context.Context) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: r.db.WithContext(ctx),
+	}
+}
+
+// Create 创建 API Key
+func (r *APIKeyRepository) Create(key *apikeymodel.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+// FindByPrefix 通过前缀查找 API Key
+func (r *APIKeyRepository) FindByPrefix(prefix string) (*apikeymodel.APIKey, error) {
+	var key apikeymodel.APIKey
+	err := r.db.Where("prefix = ?", prefix).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// FindByIDForUser 查找用户的 API Key
+func (r *APIKeyRepository) FindByIDForUser(id, userID string) (*apikeymodel.APIKey, error) {
+	var key apikeymodel.APIKey
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListByUser 获取用户的所有 API Key（按创建时间倒序）
+func (r *APIKeyRepository) ListByUser(userID string) ([]*apikeymodel.APIKey, error) {
+	var keys []*apikeymodel.APIKey
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+// CountByUser 统计用户的 API Key 数量
+func (r *APIKeyRepository) CountByUser(userID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&apikeymodel.APIKey{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// Update 更新 API Key
+func (r *APIKeyRepository) Update(key *apikeymodel.APIKey) error {
+	return r.db.Save(key).Error
+}
+
+// DeleteForUser 删除用户的 API Key
+func (r *APIKeyRepository) DeleteForUser(id, userID string) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&apikeymodel.APIKey{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed 记录最近使用时间和客户端 IP
+func (r *APIKeyRepository) TouchLastUsed(id string, at time.Time, ip string) error {
+	return r.db.Model(&apikeymodel.APIKey{}).Where("id = ?", id).
+		UpdateColumns(map[string]any{"last_used_at": at, "last_used_ip": ip}).Error
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gosir/internal/database"
+	auditmodel "gosir/internal/model/audit"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter 审计日志查询条件，为空的条件不过滤
+type AuditLogFilter struct {
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	RequestID  string
+	Since      *time.Time // 包含
+	Until      *time.Time // 不包含
+}
+
+// AuditLogRepository 审计日志仓储层，只支持写入、查询和按保留期限删除
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository 创建审计日志仓储实例
+func NewAuditLogRepository() *AuditLogRepository {
+	return &AuditLogRepository{
+		db: database.DB,
+	}
+}
+
+// WithContext 返回绑定 context 的仓储实例
+func (r *AuditLogRepository) WithContext(ctx context.Context) *AuditLogRepository {
+	return &AuditLogRepository{
+		db: r.db.WithContext(ctx),
+	}
+}
+
+// Create 写入审计日志
+func (r *AuditLogRepository) Create(entry *auditmodel.AuditLog) error {
+	return r.db.Create(entry).Error
+}
+
+// List 按条件分页查询审计日志（按时间倒序），返回当前页和总数
+func (r *AuditLogRepository) List(filter AuditLogFilter, offset, limit int) ([]*auditmodel.AuditLog, int64, error) {
+	query := r.db.Model(&auditmodel.AuditLog{})
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("created_at < ?", *filter.Until)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []*auditmodel.AuditLog
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&logs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+// DeleteBefore 删除早于 before 的审计日志，返回删除数量
+func (r *AuditLogRepository) DeleteBefore(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", before).Delete(&auditmodel.AuditLog{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gosir/internal/database"
+	identitymodel "gosir/internal/model/identity"
+
+	"gorm.io/gorm"
+)
+
+// ErrIdentityNotFound 外部身份不存在
+var ErrIdentityNotFound = errors.New("identity not found")
+
+// IdentityRepository 外部身份仓储层
+type IdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewIdentityRepository 创建外部身份仓储实例
+func NewIdentityRepository() *IdentityRepository {
+	return &IdentityRepository{
+		db: database.DB,
+	}
+}
+
+// WithContext 返回绑定 context 的仓储实例
+func (r *IdentityRepository) WithContext(ctx context.Context) *IdentityRepository {
+	return &IdentityRepository{
+		db: r.db.WithContext(ctx),
+	}
+}
+
+// Create 创建外部身份
+func (r *IdentityRepository) Create(identity *identitymodel.Identity) error {
+	return r.db.Create(identity).Error
+}
+
+// FindByProviderSubject 通过提供方和 subject 查找外部身份
+func (r *IdentityRepository) FindByProviderSubject(provider, subject string) (*identitymodel.Identity, error) {
+	var identity identitymodel.Identity
+	err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrIdentityNotFound
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// Update 更新外部身份
+func (r *IdentityRepository) Update(identity *identitymodel.Identity) error {
+	return r.db.Save(identity).Error
+}
+
+// Delete 删除外部身份
+func (r *IdentityRepository) Delete(id string) error {
+	return r.db.Where("id = ?", id).Delete(&identitymodel.Identity{}).Error
+}
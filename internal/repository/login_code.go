@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gosir/internal/database"
+	logincodemodel "gosir/internal/model/logincode"
+
+	"gorm.io/gorm"
+)
+
+// ErrLoginCodeNotFound 免密登录凭证不存在、已使用、已过期或尝试次数已用完
+var ErrLoginCodeNotFound = errors.New("login code not found")
+
+// LoginCodeRepository 免密登录凭证仓储层
+type LoginCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginCodeRepository 创建免密登录凭证仓储实例
+func NewLoginCodeRepository() *LoginCodeRepository {
+	return &LoginCodeRepository{
+		db: database.DB,
+	}
+}
+
+// WithContext 返回绑定 context 的仓储实例
+func (r *LoginCodeRepository) WithContext(ctx context.Context) *LoginCodeRepository {
+	return &LoginCodeRepository{
+		db: r.db.WithContext(ctx),
+	}
+}
+
+// Replace 保存新凭证，同时删除该用户未使用的同类凭证（每个用户同时只有一个有效的验证码或登录链接）
+func (r *LoginCodeRepository) Replace(code *logincodemodel.LoginCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND kind = ? AND used_at IS NULL", code.UserID, code.Kind).
+			Delete(&logincodemodel.LoginCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(code).Error
+	})
+}
+
+// FindActive 查找用户当前有效的凭证（未使用、未过期且尝试次数未用完）
+func (r *LoginCodeRepository) FindActive(userID, kind string, maxAttempts int, now time.Time) (*logincodemodel.LoginCode, error) {
+	var code logincodemodel.LoginCode
+	err := r.db.Where("user_id = ? AND kind = ? AND used_at IS NULL AND expires_at > ? AND attempts < ?", userID, kind, now, maxAttempts).
+		Order("created_at DESC").
+		First(&code).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLoginCodeNotFound
+		}
+		return nil, err
+	}
+	return &code, nil
+}
+
+// FindByID 通过 ID 查找凭证
+func (r *LoginCodeRepository) FindByID(id string) (*logincodemodel.LoginCode, error) {
+	var code logincodemodel.LoginCode
+	err := r.db.Where("id = ?", id).First(&code).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLoginCodeNotFound
+		}
+		return nil, err
+	}
+	return &code, nil
+}
+
+// UseAttempt 占用一次尝试次数，凭证已使用或次数已用完时返回 ErrLoginCodeNotFound
+// 先占用再比较，并发猜测也不会超过次数限制
+func (r *LoginCodeRepository) UseAttempt(id string, maxAttempts int) error {
+	result := r.db.Model(&logincodemodel.LoginCode{}).
+		Where("id = ? AND used_at IS NULL AND attempts < ?", id, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLoginCodeNotFound
+	}
+	return nil
+}
+
+// MarkUsed 标记凭证已使用，凭证已被使用时返回 ErrLoginCodeNotFound
+func (r *LoginCodeRepository) MarkUsed(id string, at time.Time) error {
+	result := r.db.Model(&logincodemodel.LoginCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLoginCodeNotFound
+	}
+	return nil
+}
+
+// DeleteExpired 删除过期和已使用的凭证，返回删除数量
+func (r *LoginCodeRepository) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ? OR used_at IS NOT NULL", now).Delete(&logincodemodel.LoginCode{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gosir/internal/database"
+	migrationmodel "gosir/internal/model/migration"
+
+	"gorm.io/gorm"
+)
+
+// ErrLockHeld 锁已被其他实例持有
+var ErrLockHeld = errors.New("lock is held by another owner")
+
+// MigrationLockRepository 迁移锁仓储层
+type MigrationLockRepository struct {
+	db *gorm.DB
+}
+
+// NewMigrationLockRepository 创建迁移锁仓储实例
+func NewMigrationLockRepository() *MigrationLockRepository {
+	return &MigrationLockRepository{
+		db: database.DB,
+	}
+}
+
+// EnsureTable 创建锁表（使用 IF NOT EXISTS，多个实例并发执行也是安全的）
+func (r *MigrationLockRepository) EnsureTable() error {
+	return r.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migration_locks (
+		name VARCHAR(64) PRIMARY KEY,
+		owner VARCHAR(255) NOT NULL,
+		acquired_at DATETIME NOT NULL,
+		expires_at DATETIME NOT NULL
+	)`).Error
+}
+
+// TryAcquire 尝试获取锁
+// 锁不存在时直接插入；锁已过期时回收后重新插入，并返回被回收的旧锁；
+// 锁仍被其他实例持有时返回 ErrLockHeld
+func (r *MigrationLockRepository) TryAcquire(name, owner string, ttl time.Duration) (*migrationmodel.SchemaMigrationLock, error) {
+	var stale *migrationmodel.SchemaMigrationLock
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var current migrationmodel.SchemaMigrationLock
+		err := tx.Where("name = ?", name).First(&current).Error
+		switch {
+		case err == nil:
+			if time.Now().Before(current.ExpiresAt) {
+				return ErrLockHeld
+			}
+			// 只删除过期的那一条，避免误删刚被其他实例抢到的锁
+			result := tx.Where("name = ? AND owner = ?", name, current.Owner).
+				Delete(&migrationmodel.SchemaMigrationLock{})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrLockHeld
+			}
+			stale = &current
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		now := time.Now()
+		lock := &migrationmodel.SchemaMigrationLock{
+			Name:       name,
+			Owner:      owner,
+			AcquiredAt: now,
+			ExpiresAt:  now.Add(ttl),
+		}
+		if err := tx.Create(lock).Error; err != nil {
+			// 主键冲突说明其他实例抢先获取了锁
+			if errors.Is(err, gorm.ErrDuplicatedKey) || isUniqueConstraintError(err) {
+				return ErrLockHeld
+			}
+			return err
+		}
+		return nil
+	})
+
+	return stale, err
+}
+
+// Refresh 延长锁的过期时间（仅持有者可以续期）
+func (r *MigrationLockRepository) Refresh(name, owner string, ttl time.Duration) error {
+	result := r.db.Model(&migrationmodel.SchemaMigrationLock{}).
+		Where("name = ? AND owner = ?", name, owner).
+		Update("expires_at", time.Now().Add(ttl))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLockHeld
+	}
+	return nil
+}
+
+// Release 释放锁（仅持有者可以释放）
+func (r *MigrationLockRepository) Release(name, owner string) error {
+	return r.db.Where("name = ? AND owner = ?", name, owner).
+		Delete(&migrationmodel.SchemaMigrationLock{}).Error
+}
+
+// isUniqueConstraintError 检查是否是唯一约束冲突错误
+func isUniqueConstraintError(err error) bool {
+	if err == nil {
+		return false
+	}
+	errMsg := strings.ToLower(err.Error())
+	return strings.Contains(errMsg, "unique constraint") ||
+		strings.Contains(errMsg, "duplicate")
+}
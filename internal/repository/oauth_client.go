@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gosir/internal/database"
+	oauthmodel "gosir/internal/model/oauth"
+
+	"gorm.io/gorm"
+)
+
+// ErrOAuthClientNotFound OAuth 客户端不存在
+var ErrOAuthClientNotFound = errors.New("oauth client not found")
+
+// OAuthClientRepository OAuth 客户端仓储层
+type OAuthClientRepository struct {
+	db *gorm.DB
+}
+
+// NewOAuthClientRepository 创建 OAuth 客户端仓储实例
+func NewOAuthClientRepository() *OAuthClientRepository {
+	return &OAuthClientRepository{
+		db: database.DB,
+	}
+}
+
+// WithContext 返回绑定 context 的仓储实例
+func (r *OAuthClientRepository) WithContext(ctx context.Context) *OAuthClientRepository {
+	return &OAuthClientRepository{
+		db: r.db.WithContext(ctx),
+	}
+}
+
+// Create 创建客户端
+func (r *OAuthClientRepository) Create(client *oauthmodel.Client) error {
+	return r.db.Create(client).Error
+}
+
+// FindByID 通过 client_id 查找客户端
+func (r *OAuthClientRepository) FindByID(id string) (*oauthmodel.Client, error) {
+	var client oauthmodel.Client
+	err := r.db.Where("id = ?", id).First(&client).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOAuthClientNotFound
+		}
+		return nil, err
+	}
+	return &client, nil
+}
+
+// FindAll 获取所有客户端（按创建时间倒序）
+func (r *OAuthClientRepository) FindAll() ([]*oauthmodel.Client, error) {
+	var clients []*oauthmodel.Client
+	err := r.db.Order("created_at DESC").Find(&clients).Error
+	return clients, err
+}
+
+// Update 更新客户端
+func (r *OAuthClientRepository) Update(client *oauthmodel.Client) error {
+	return r.db.Save(client).Error
+}
+
+// Delete 删除客户端及其刷新令牌
+func (r *OAuthClientRepository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).Delete(&oauthmodel.Client{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrOAuthClientNotFound
+		}
+		return tx.Where("client_id = ?", id).Delete(&oauthmodel.RefreshToken{}).Error
+	})
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gosir/internal/database"
+	oauthmodel "gosir/internal/model/oauth"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrAuthorizationCodeNotFound 授权码不存在
+	ErrAuthorizationCodeNotFound = errors.New("authorization code not found")
+	// ErrAuthorizationCodeUsed 授权码已被使用（包括并发换取时被其他请求使用）
+	ErrAuthorizationCodeUsed = errors.New("authorization code already used")
+	// ErrRefreshTokenNotFound 刷新令牌不存在
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	// ErrRefreshTokenRevoked 刷新令牌已被吊销（包括并发刷新时被其他请求吊销）
+	ErrRefreshTokenRevoked = errors.New("refresh token revoked")
+)
+
+// OAuthTokenRepository 授权码和刷新令牌仓储层
+type OAuthTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewOAuthTokenRepository 创建授权码和刷新令牌仓储实例
+func NewOAuthTokenRepository() *OAuthTokenRepository {
+	return &OAuthTokenRepository{
+		db: database.DB,
+	}
+}
+
+// WithContext 返回绑定 context 的仓储实例
+func (r *OAuthTokenRepository) WithContext(ctx context.Context) *OAuthTokenRepository {
+	return &OAuthTokenRepository{
+		db: r.db.WithContext(ctx),
+	}
+}
+
+// CreateCode 保存授权码
+func (r *OAuthTokenRepository) CreateCode(code *oauthmodel.AuthorizationCode) error {
+	return r.db.Create(code).Error
+}
+
+// ConsumeCode 将授权码标记为已使用，保证授权码只能使用一次
+// 授权码已被使用时同时返回授权码和 ErrAuthorizationCodeUsed，调用方据此吊销已签发的令牌
+func (r *OAuthTokenRepository) ConsumeCode(codeHash string, at time.Time) (*oauthmodel.AuthorizationCode, error) {
+	var code oauthmodel.AuthorizationCode
+	if err := r.db.Where("code_hash = ?", codeHash).First(&code).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAuthorizationCodeNotFound
+		}
+		return nil, err
+	}
+	if code.UsedAt != nil {
+		return &code, ErrAuthorizationCodeUsed
+	}
+
+	result := r.db.Model(&oauthmodel.AuthorizationCode{}).
+		Where("code_hash = ? AND used_at IS NULL", codeHash).
+		Update("used_at", at)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &code, ErrAuthorizationCodeUsed
+	}
+	code.UsedAt = &at
+	return &code, nil
+}
+
+// CreateRefreshToken 保存刷新令牌
+func (r *OAuthTokenRepository) CreateRefreshToken(token *oauthmodel.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+// FindRefreshToken 通过哈希查找刷新令牌
+func (r *OAuthTokenRepository) FindRefreshToken(tokenHash string) (*oauthmodel.RefreshToken, error) {
+	var token oauthmodel.RefreshToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRefreshTokenNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeRefreshToken 吊销刷新令牌，令牌已被吊销时返回 ErrRefreshTokenRevoked
+func (r *OAuthTokenRepository) RevokeRefreshToken(id string, at time.Time) error {
+	result := r.db.Model(&oauthmodel.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRefreshTokenRevoked
+	}
+	return nil
+}
+
+// RevokeFamily 吊销同一次授权产生的所有刷新令牌
+func (r *OAuthTokenRepository) RevokeFamily(familyID string, at time.Time) error {
+	return r.db.Model(&oauthmodel.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", at).Error
+}
+
+// RevokeUser 吊销用户的所有刷新令牌
+func (r *OAuthTokenRepository) RevokeUser(userID string, at time.Time) error {
+	return r.db.Model(&oauthmodel.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error
+}
+
+// DeleteExpired 删除过期的授权码和刷新令牌，返回删除数量
+func (r *OAuthTokenRepository) DeleteExpired(now time.Time) (int64, error) {
+	codes := r.db.Where("expires_at < ?", now).Delete(&oauthmodel.AuthorizationCode{})
+	if codes.Error != nil {
+		return 0, codes.Error
+	}
+	tokens := r.db.Where("expires_at < ?", now).Delete(&oauthmodel.RefreshToken{})
+	if tokens.Error != nil {
+		return codes.RowsAffected, tokens.Error
+	}
+	return codes.RowsAffected + tokens.RowsAffected, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"gosir/internal/clock"
+	"gosir/internal/database"
+	ratelimitmodel "gosir/internal/model/ratelimit"
+	"gosir/internal/ratelimit"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RateLimitRepository 限流计数仓储层，实现 ratelimit.Store
+type RateLimitRepository struct {
+	db *gorm.DB
+}
+
+// NewRateLimitRepository 创建限流计数仓储实例
+func NewRateLimitRepository() *RateLimitRepository {
+	return &RateLimitRepository{
+		db: database.DB,
+	}
+}
+
+// Update 在事务中读取、修改并保存 key 的计数
+func (r *RateLimitRepository) Update(ctx context.Context, key string, fn func(state *ratelimit.State)) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var counter ratelimitmodel.RateLimitCounter
+		if err := tx.Where("key = ?", key).Limit(1).Find(&counter).Error; err != nil {
+			return err
+		}
+
+		var state ratelimit.State
+		if counter.Key != "" && !clock.Now().After(counter.ExpiresAt) {
+			state = ratelimit.State{
+				Value:     counter.Value,
+				Previous:  counter.Previous,
+				Timestamp: counter.Timestamp,
+				ExpiresAt: counter.ExpiresAt,
+			}
+		}
+		fn(&state)
+
+		counter = ratelimitmodel.RateLimitCounter{
+			Key:       key,
+			Value:     state.Value,
+			Previous:  state.Previous,
+			Timestamp: state.Timestamp,
+			ExpiresAt: state.ExpiresAt,
+		}
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&counter).Error
+	})
+}
+
+// DeleteExpired 删除已过期的计数
+func (r *RateLimitRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", clock.Now()).Delete(&ratelimitmodel.RateLimitCounter{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"time"
+
+	"gosir/internal/clock"
+	"gosir/internal/database"
+	tokenmodel "gosir/internal/model/token"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TokenBlacklistRepository token 黑名单仓储层，实现 common.BlacklistStore
+type TokenBlacklistRepository struct {
+	db *gorm.DB
+}
+
+// NewTokenBlacklistRepository 创建 token 黑名单仓储实例
+func NewTokenBlacklistRepository() *TokenBlacklistRepository {
+	return &TokenBlacklistRepository{
+		db: database.DB,
+	}
+}
+
+// Add 将 token 加入黑名单（重复加入时忽略）
+func (r *TokenBlacklistRepository) Add(jti string, expiredAt time.Time) error {
+	entry := &tokenmodel.TokenBlacklist{
+		JTI:           jti,
+		ExpiredAt:     expiredAt,
+		BlacklistedAt: clock.Now(),
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(entry).Error
+}
+
+// Exists 检查 token 是否在黑名单中且尚未过期
+func (r *TokenBlacklistRepository) Exists(jti string) (bool, time.Time, error) {
+	var entry tokenmodel.TokenBlacklist
+	err := r.db.Where("jti = ?", jti).Limit(1).Find(&entry).Error
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	if entry.JTI == "" || clock.Now().After(entry.ExpiredAt) {
+		return false, time.Time{}, nil
+	}
+	return true, entry.ExpiredAt, nil
+}
+
+// DeleteExpired 删除已过期的黑名单记录
+func (r *TokenBlacklistRepository) DeleteExpired() (int64, error) {
+	result := r.db.Where("expired_at < ?", clock.Now()).Delete(&tokenmodel.TokenBlacklist{})
+	return result.RowsAffected, result.Error
+}
@@ -1,13 +1,18 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"gosir/internal/database"
 	usermodel "gosir/internal/model/user"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// ErrUserVersionConflict 用户读取后已被其他请求修改
+var ErrUserVersionConflict = errors.New("user version conflict")
+
 type UserRepository struct {
 	db *gorm.DB
 }
@@ -18,6 +23,13 @@ func NewUserRepository() *UserRepository {
 	}
 }
 
+// WithContext 返回绑定 context 的仓储实例，SQL 日志会附加 context 中的请求 ID
+func (r *UserRepository) WithContext(ctx context.Context) *UserRepository {
+	return &UserRepository{
+		db: r.db.WithContext(ctx),
+	}
+}
+
 func (r *UserRepository) FindByID(id string) (*usermodel.User, error) {
 	var userModel usermodel.User
 	err := r.db.Where("id = ?", id).First(&userModel).Error
@@ -30,10 +42,23 @@ func (r *UserRepository) FindByID(id string) (*usermodel.User, error) {
 	return &userModel, nil
 }
 
-// FindByEmail 通过邮箱查找用户
+// FindByEmail 通过邮箱查找用户，不区分大小写
 func (r *UserRepository) FindByEmail(email string) (*usermodel.User, error) {
 	var userModel usermodel.User
-	err := r.db.Where("email = ?", email).First(&userModel).Error
+	err := r.db.Where("lower(email) = ?", usermodel.NormalizeEmail(email)).First(&userModel).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &UserNotFoundError{ID: email}
+		}
+		return nil, err
+	}
+	return &userModel, nil
+}
+
+// FindByEmailWithDeleted 通过邮箱查找用户，包括已删除的用户（邮箱有唯一约束，已删除用户的邮箱同样不能再使用）
+func (r *UserRepository) FindByEmailWithDeleted(email string) (*usermodel.User, error) {
+	var userModel usermodel.User
+	err := r.db.Unscoped().Where("lower(email) = ?", usermodel.NormalizeEmail(email)).First(&userModel).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, &UserNotFoundError{ID: email}
@@ -43,10 +68,10 @@ func (r *UserRepository) FindByEmail(email string) (*usermodel.User, error) {
 	return &userModel, nil
 }
 
-// FindByEmailOrPhone 通过邮箱或手机号查找用户
+// FindByEmailOrPhone 通过邮箱（不区分大小写）或手机号查找用户
 func (r *UserRepository) FindByEmailOrPhone(account string) (*usermodel.User, error) {
 	var userModel usermodel.User
-	err := r.db.Where("email = ? OR phone = ?", account, account).First(&userModel).Error
+	err := r.db.Where("lower(email) = ? OR phone = ?", usermodel.NormalizeEmail(account), account).First(&userModel).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, &UserNotFoundError{ID: account}
@@ -56,6 +81,34 @@ func (r *UserRepository) FindByEmailOrPhone(account string) (*usermodel.User, er
 	return &userModel, nil
 }
 
+// EmailTaken 邮箱是否已被其他用户使用，不区分大小写（包括已删除的用户，邮箱有唯一约束）
+func (r *UserRepository) EmailTaken(email, excludeID string) (bool, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&usermodel.User{}).
+		Where("lower(email) = ? AND id <> ?", usermodel.NormalizeEmail(email), excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// PhoneTaken 手机号是否已被其他用户使用，空手机号不检查
+func (r *UserRepository) PhoneTaken(phone, excludeID string) (bool, error) {
+	if phone == "" {
+		return false, nil
+	}
+	var count int64
+	err := r.db.Model(&usermodel.User{}).
+		Where("phone = ? AND id <> ?", phone, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// HasAdmin 是否已存在管理员
+func (r *UserRepository) HasAdmin() (bool, error) {
+	var count int64
+	err := r.db.Model(&usermodel.User{}).Where("is_admin = ?", true).Count(&count).Error
+	return count > 0, err
+}
+
 func (r *UserRepository) Create(userModel *usermodel.User) (*usermodel.User, error) {
 	err := r.db.Create(userModel).Error
 	if err != nil {
@@ -70,10 +123,23 @@ func (r *UserRepository) FindAll() ([]*usermodel.User, error) {
 	return users, err
 }
 
+// Update 保存用户的全部字段并将版本号加 1
+// 只有数据库中的版本号与 userModel.Version 相同时才会修改，否则返回 ErrUserVersionConflict（用户读取后已被修改或删除）
 func (r *UserRepository) Update(userModel *usermodel.User) (*usermodel.User, error) {
-	err := r.db.Save(userModel).Error
-	if err != nil {
-		return nil, err
+	expected := userModel.Version
+	userModel.Version = expected + 1
+	result := r.db.Model(userModel).
+		Where("version = ?", expected).
+		Select("*").
+		Omit("id", "created_at").
+		Updates(userModel)
+	if result.Error != nil {
+		userModel.Version = expected
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		userModel.Version = expected
+		return nil, ErrUserVersionConflict
 	}
 	return userModel, nil
 }
@@ -86,6 +152,26 @@ func (r *UserRepository) Delete(id string) error {
 	return nil
 }
 
+// Activate 将待验证的用户改为正常状态，用户不是待验证状态时返回 false
+func (r *UserRepository) Activate(id string, at time.Time) (bool, error) {
+	result := r.db.Model(&usermodel.User{}).
+		Where("id = ? AND status = ?", id, int(usermodel.UserStatusPending)).
+		Updates(map[string]interface{}{
+			"status":     int(usermodel.UserStatusNormal),
+			"updated_at": at,
+			"version":    gorm.Expr("version + 1"),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteUnverified 彻底删除注册时间早于 before 且仍未验证邮箱的用户（不保留软删除记录，邮箱可以重新注册），返回删除数量
+func (r *UserRepository) DeleteUnverified(before time.Time) (int64, error) {
+	result := r.db.Unscoped().
+		Where("status = ? AND created_at < ?", int(usermodel.UserStatusPending), before).
+		Delete(&usermodel.User{})
+	return result.RowsAffected, result.Error
+}
+
 type UserNotFoundError struct {
 	ID string
 }
@@ -0,0 +1,260 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"gosir/internal/clock"
+	apikeymodel "gosir/internal/model/apikey"
+	usermodel "gosir/internal/model/user"
+	"gosir/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// KeyPrefix 完整 API Key 的固定前缀，便于在日志和代码仓库中识别泄露的 key
+const KeyPrefix = "gsk_"
+
+const (
+	prefixLength   = 8  // 公开前缀长度
+	secretLength   = 32 // secret 长度（base62，约 190 位熵）
+	maxKeysPerUser = 50 // 每个用户最多可创建的 key 数量
+)
+
+// lastUsedInterval 最近使用时间的更新间隔，避免每个请求都写数据库
+const lastUsedInterval = time.Minute
+
+// 权限范围
+const (
+	ScopeUsersRead  = "users:read"  // 查询用户
+	ScopeUsersWrite = "users:write" // 创建、修改、删除用户
+	ScopeAdmin      = "admin"       // 管理员接口（用户本身仍需是管理员）
+)
+
+// Scopes 所有权限范围
+var Scopes = []string{ScopeUsersRead, ScopeUsersWrite, ScopeAdmin}
+
+var (
+	// ErrInvalidAPIKey key 格式错误、不存在或 secret 不匹配
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrAPIKeyExpired key 已过期
+	ErrAPIKeyExpired = errors.New("api key expired")
+	// ErrUserDisabled key 所属用户已被禁用
+	ErrUserDisabled = errors.New("user disabled")
+	// ErrInvalidScope 未知的权限范围
+	ErrInvalidScope = errors.New("invalid scope")
+	// ErrInvalidExpiry 过期时间早于当前时间
+	ErrInvalidExpiry = errors.New("expiry must be in the future")
+	// ErrTooManyKeys 超过每个用户可创建的 key 数量
+	ErrTooManyKeys = errors.New("too many api keys")
+	// ErrAPIKeyNotFound key 不存在或不属于该用户
+	ErrAPIKeyNotFound = repository.ErrAPIKeyNotFound
+)
+
+// APIKeyService API Key 服务
+type APIKeyService struct {
+	keyRepo  *repository.APIKeyRepository
+	userRepo *repository.UserRepository
+}
+
+// NewAPIKeyService 创建 API Key 服务
+func NewAPIKeyService() *APIKeyService {
+	return &APIKeyService{
+		keyRepo:  repository.NewAPIKeyRepository(),
+		userRepo: repository.NewUserRepository(),
+	}
+}
+
+// WithContext 返回绑定 context 的服务实例
+func (s *APIKeyService) WithContext(ctx context.Context) *APIKeyService {
+	return &APIKeyService{
+		keyRepo:  s.keyRepo.WithContext(ctx),
+		userRepo: s.userRepo.WithContext(ctx),
+	}
+}
+
+// KeyRequest 创建或修改 API Key 的参数
+type KeyRequest struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time // 为空表示永不过期
+}
+
+// Create 为用户创建 API Key，返回的完整 key 只在创建时可见
+func (s *APIKeyService) Create(userID string, req KeyRequest) (*apikeymodel.APIKey, string, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, "", err
+	}
+	count, err := s.keyRepo.CountByUser(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if count >= maxKeysPerUser {
+		return nil, "", ErrTooManyKeys
+	}
+
+	prefix, err := randomString(prefixLength)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := randomString(secretLength)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := clock.Now()
+	key := &apikeymodel.APIKey{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		Name:       req.Name,
+		Prefix:     prefix,
+		SecretHash: hashSecret(secret),
+		Scopes:     normalizeScopes(req.Scopes),
+		ExpiresAt:  req.ExpiresAt,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.keyRepo.Create(key); err != nil {
+		return nil, "", err
+	}
+	return key, KeyPrefix + prefix + "_" + secret, nil
+}
+
+// List 获取用户的所有 API Key
+func (s *APIKeyService) List(userID string) ([]*apikeymodel.APIKey, error) {
+	return s.keyRepo.ListByUser(userID)
+}
+
+// Get 获取用户的 API Key
+func (s *APIKeyService) Get(userID, id string) (*apikeymodel.APIKey, error) {
+	return s.keyRepo.FindByIDForUser(id, userID)
+}
+
+// Update 修改用户 API Key 的名称、权限范围和过期时间（secret 不变）
+func (s *APIKeyService) Update(userID, id string, req KeyRequest) (*apikeymodel.APIKey, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+	key, err := s.keyRepo.FindByIDForUser(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Name = req.Name
+	key.Scopes = normalizeScopes(req.Scopes)
+	key.ExpiresAt = req.ExpiresAt
+	key.UpdatedAt = clock.Now()
+	if err := s.keyRepo.Update(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Delete 删除（吊销）用户的 API Key，立即失效
+func (s *APIKeyService) Delete(userID, id string) error {
+	return s.keyRepo.DeleteForUser(id, userID)
+}
+
+// Authenticate 校验完整的 API Key，成功时记录最近使用时间和 IP
+func (s *APIKeyService) Authenticate(rawKey, ip string) (*apikeymodel.APIKey, *usermodel.User, error) {
+	prefix, secret, ok := parseKey(rawKey)
+	if !ok {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.keyRepo.FindByPrefix(prefix)
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, nil, ErrInvalidAPIKey
+		}
+		return nil, nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(key.SecretHash)) != 1 {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	now := clock.Now()
+	if key.Expired(now) {
+		return nil, nil, ErrAPIKeyExpired
+	}
+
+	user, err := s.userRepo.FindByID(key.UserID)
+	if err != nil {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if user.Status == int(usermodel.UserStatusDisabled) {
+		return nil, nil, ErrUserDisabled
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedInterval || key.LastUsedIP != ip {
+		if err := s.keyRepo.TouchLastUsed(key.ID, now, ip); err != nil {
+			return nil, nil, err
+		}
+		key.LastUsedAt = &now
+		key.LastUsedIP = ip
+	}
+	return key, user, nil
+}
+
+// validateRequest 校验权限范围和过期时间
+func validateRequest(req KeyRequest) error {
+	for _, scope := range req.Scopes {
+		if !slices.Contains(Scopes, scope) {
+			return fmt.Errorf("%w: %q", ErrInvalidScope, scope)
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(clock.Now()) {
+		return ErrInvalidExpiry
+	}
+	return nil
+}
+
+// normalizeScopes 去重并排序
+func normalizeScopes(scopes []string) apikeymodel.Scopes {
+	normalized := slices.Clone(scopes)
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+// parseKey 解析 gsk_{prefix}_{secret}
+func parseKey(rawKey string) (prefix, secret string, ok bool) {
+	rest, ok := strings.CutPrefix(rawKey, KeyPrefix)
+	if !ok {
+		return "", "", false
+	}
+	prefix, secret, ok = strings.Cut(rest, "_")
+	if !ok || len(prefix) != prefixLength || len(secret) != secretLength {
+		return "", "", false
+	}
+	return prefix, secret, true
+}
+
+// hashSecret secret 的 SHA-256（secret 为高熵随机串，不需要慢哈希）
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomString 生成 base62 随机串
+func randomString(length int) (string, error) {
+	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+	buf := make([]byte, length)
+	for i := range buf {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		if err != nil {
+			return "", err
+		}
+		buf[i] = charset[n.Int64()]
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,141 @@
+package audit
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"gosir/internal/clock"
+	"gosir/internal/logger"
+	model "gosir/internal/model/audit"
+	"gosir/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 操作
+const (
+	ActionLogin        = "auth.login"         // 登录成功
+	ActionLoginFailed  = "auth.login_failed"  // 登录失败
+	ActionLogout       = "auth.logout"        // 登出
+	ActionTokenRefresh = "auth.token_refresh" // 刷新 token
+	ActionUserCreate   = "user.create"        // 创建用户
+	ActionUserUpdate   = "user.update"        // 修改用户（包括状态和密码）
+	ActionUserDelete   = "user.delete"        // 删除用户
+)
+
+// 操作对象类型
+const (
+	TargetUser    = "user"    // 用户
+	TargetAccount = "account" // 登录失败时提交的账号（不一定存在）
+)
+
+// Entry 审计事件
+type Entry struct {
+	ActorID    string // 操作人，为空时使用 context 中的当前用户
+	Action     string
+	TargetType string
+	TargetID   string
+	Changes    model.Changes
+	Detail     string
+}
+
+// retention 审计日志保留时长，0 表示永久保留
+var retention atomic.Int64
+
+// Init 设置审计日志保留时长，0 表示永久保留
+func Init(keep time.Duration) {
+	retention.Store(int64(keep))
+}
+
+// Record 写入审计日志，客户端 IP、User-Agent 和请求 ID 从 context 中获取
+// 写入失败只记录错误日志，不影响业务操作
+func Record(ctx context.Context, entry Entry) {
+	actor := entry.ActorID
+	if actor == "" {
+		actor = ActorFromContext(ctx)
+	}
+	c := clientFromContext(ctx)
+
+	log := &model.AuditLog{
+		ID:         uuid.New().String(),
+		ActorID:    actor,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   truncate(entry.TargetID, 100),
+		Changes:    entry.Changes,
+		Detail:     truncate(entry.Detail, 255),
+		IP:         c.IP,
+		UserAgent:  truncate(c.UserAgent, 255),
+		RequestID:  logger.RequestIDFromContext(ctx),
+		CreatedAt:  clock.Now(),
+	}
+	// 请求已结束（如客户端断开）时仍然写入
+	if err := repository.NewAuditLogRepository().WithContext(context.WithoutCancel(ctx)).Create(log); err != nil {
+		logger.Ctx(ctx).Error("Failed to write audit log",
+			zap.String("action", entry.Action),
+			zap.String("target_id", entry.TargetID),
+			zap.Error(err),
+		)
+	}
+}
+
+// Diff 比较修改前后的字段值，只返回有变化的字段；before 为空表示新增，after 为空表示删除
+func Diff(before, after map[string]any) model.Changes {
+	changes := model.Changes{}
+	for key, old := range before {
+		if value, ok := after[key]; !ok || !reflect.DeepEqual(old, value) {
+			changes[key] = model.Change{Old: old, New: after[key]}
+		}
+	}
+	for key, value := range after {
+		if _, ok := before[key]; !ok {
+			changes[key] = model.Change{New: value}
+		}
+	}
+	return changes
+}
+
+// AuditService 审计日志查询服务
+type AuditService struct {
+	repo *repository.AuditLogRepository
+}
+
+// NewAuditService 创建审计日志查询服务
+func NewAuditService() *AuditService {
+	return &AuditService{
+		repo: repository.NewAuditLogRepository(),
+	}
+}
+
+// WithContext 返回绑定 context 的服务实例
+func (s *AuditService) WithContext(ctx context.Context) *AuditService {
+	return &AuditService{
+		repo: s.repo.WithContext(ctx),
+	}
+}
+
+// List 按条件分页查询审计日志，page 从 1 开始
+func (s *AuditService) List(filter repository.AuditLogFilter, page, pageSize int) ([]*model.AuditLog, int64, error) {
+	return s.repo.List(filter, (page-1)*pageSize, pageSize)
+}
+
+// DeleteExpired 删除超过保留时长的审计日志，永久保留时不删除
+func DeleteExpired(ctx context.Context) (int64, error) {
+	keep := time.Duration(retention.Load())
+	if keep <= 0 {
+		return 0, nil
+	}
+	return repository.NewAuditLogRepository().WithContext(ctx).DeleteBefore(clock.Now().Add(-keep))
+}
+
+// truncate 按字符截断，避免超出字段长度
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
@@ -0,0 +1,37 @@
+package audit
+
+import "context"
+
+// clientKey context 中保存客户端信息的 key
+type clientKey struct{}
+
+// actorKey context 中保存当前用户 ID 的 key
+type actorKey struct{}
+
+// client 发起请求的客户端
+type client struct {
+	IP        string
+	UserAgent string
+}
+
+// WithClient 将客户端 IP 和 User-Agent 存入 context
+func WithClient(ctx context.Context, ip, userAgent string) context.Context {
+	return context.WithValue(ctx, clientKey{}, client{IP: ip, UserAgent: userAgent})
+}
+
+// WithActor 将当前用户 ID 存入 context，审计日志默认以该用户为操作人
+func WithActor(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// ActorFromContext 从 context 中获取当前用户 ID
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// clientFromContext 从 context 中获取客户端信息
+func clientFromContext(ctx context.Context) client {
+	c, _ := ctx.Value(clientKey{}).(client)
+	return c
+}
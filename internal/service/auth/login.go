@@ -1,12 +1,19 @@
 package auth
 
 import (
+	"context"
+	"errors"
+	"gosir/internal/common"
 	"gosir/internal/model/user"
+	"gosir/internal/repository"
 	"gosir/internal/service/user"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrTokenRevoked token 签发后用户的 token 已被撤销（如用户被禁用）
+var ErrTokenRevoked = errors.New("token revoked")
+
 // LoginService 登录服务
 type LoginService struct {
 	userService *user.UserService
@@ -19,6 +26,13 @@ func NewLoginService() *LoginService {
 	}
 }
 
+// WithContext 返回绑定 context 的服务实例
+func (s *LoginService) WithContext(ctx context.Context) *LoginService {
+	return &LoginService{
+		userService: s.userService.WithContext(ctx),
+	}
+}
+
 // VerifyPassword 验证密码
 func VerifyPassword(hashedPassword, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
@@ -36,7 +50,7 @@ func (s *LoginService) LoginByPassword(email, password string) (*model.User, err
 		return nil, err
 	}
 
-	return userData, nil
+	return activeLoginUser(userData)
 }
 
 // LoginByAccount 通过账号（邮箱或手机号）密码登录
@@ -51,5 +65,31 @@ func (s *LoginService) LoginByAccount(account, password string) (*model.User, er
 		return nil, err
 	}
 
+	return activeLoginUser(userData)
+}
+
+// activeLoginUser 检查用户是否可以登录：禁用的用户返回 ErrUserDisabled，尚未验证邮箱的注册用户返回 ErrUserNotVerified
+func activeLoginUser(userData *model.User) (*model.User, error) {
+	switch model.UserStatus(userData.Status) {
+	case model.UserStatusDisabled:
+		return nil, ErrUserDisabled
+	case model.UserStatusPending:
+		return nil, ErrUserNotVerified
+	}
 	return userData, nil
 }
+
+// TokenUser 检查 token 所属用户是否仍然可以使用该 token
+// 用户不存在时返回 *repository.UserNotFoundError，token 签发于用户的 token 撤销时间之前（含同一秒）时返回 ErrTokenRevoked，
+// 用户被禁用或尚未验证邮箱时与登录相同
+func TokenUser(ctx context.Context, claims *common.JWTClaims) (*model.User, error) {
+	userData, err := repository.NewUserRepository().WithContext(ctx).FindByID(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if revokedAt := userData.TokensRevokedAt; revokedAt != nil &&
+		(claims.IssuedAt == nil || !claims.IssuedAt.After(*revokedAt)) {
+		return nil, ErrTokenRevoked
+	}
+	return activeLoginUser(userData)
+}
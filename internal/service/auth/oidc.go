@@ -0,0 +1,262 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"gosir/internal/clock"
+	identitymodel "gosir/internal/model/identity"
+	model "gosir/internal/model/user"
+	"gosir/internal/oidc"
+	"gosir/internal/repository"
+	"gosir/internal/service/user"
+
+	"github.com/google/uuid"
+)
+
+const (
+	randomBytes          = 32 // state、nonce 和 PKCE verifier 的随机字节数
+	jitPasswordLength    = 32 // 自动创建的用户使用的随机密码长度（用户需通过重置密码才能使用密码登录）
+	maxAvatarLength      = 500
+	maxExternalNameRunes = 100
+)
+
+var (
+	// ErrUnknownProvider 未配置的提供方
+	ErrUnknownProvider = errors.New("unknown oidc provider")
+	// ErrIdentityNotLinked 外部身份未关联本地用户且未开启自动创建
+	ErrIdentityNotLinked = errors.New("identity not linked to a user")
+	// ErrEmailRequired 提供方未返回邮箱，无法创建或关联用户
+	ErrEmailRequired = errors.New("email claim required")
+	// ErrEmailNotVerified 提供方返回的邮箱未验证
+	ErrEmailNotVerified = errors.New("email not verified")
+	// ErrEmailDomainNotAllowed 邮箱域名不在允许登录的列表中
+	ErrEmailDomainNotAllowed = errors.New("email domain not allowed")
+	// ErrEmailTaken 邮箱已被其他用户使用且未开启按邮箱关联
+	ErrEmailTaken = errors.New("email already used by another user")
+	// ErrUserDisabled 用户已被禁用
+	ErrUserDisabled = errors.New("user disabled")
+)
+
+// OIDCState 一次 OIDC 登录的临时参数，发起登录时生成，回调时用于校验 state 和 nonce 以及换取 token
+type OIDCState struct {
+	Provider string `json:"provider"`
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+}
+
+// OIDCLoginService OIDC 登录服务
+type OIDCLoginService struct {
+	ctx          context.Context
+	identityRepo *repository.IdentityRepository
+	userRepo     *repository.UserRepository
+	userService  *user.UserService
+}
+
+// NewOIDCLoginService 创建 OIDC 登录服务
+func NewOIDCLoginService() *OIDCLoginService {
+	return &OIDCLoginService{
+		ctx:          context.Background(),
+		identityRepo: repository.NewIdentityRepository(),
+		userRepo:     repository.NewUserRepository(),
+		userService:  user.NewUserService(),
+	}
+}
+
+// WithContext 返回绑定 context 的服务实例，请求提供方时同样使用该 context
+func (s *OIDCLoginService) WithContext(ctx context.Context) *OIDCLoginService {
+	return &OIDCLoginService{
+		ctx:          ctx,
+		identityRepo: s.identityRepo.WithContext(ctx),
+		userRepo:     s.userRepo.WithContext(ctx),
+		userService:  s.userService.WithContext(ctx),
+	}
+}
+
+// Begin 发起登录，返回提供方的授权地址和需要保存到回调时使用的临时参数
+func (s *OIDCLoginService) Begin(providerName string) (string, *OIDCState, error) {
+	provider, ok := oidc.Get(providerName)
+	if !ok {
+		return "", nil, ErrUnknownProvider
+	}
+
+	state := &OIDCState{Provider: providerName}
+	for _, field := range []*string{&state.State, &state.Nonce, &state.Verifier} {
+		value, err := oidc.RandomString(randomBytes)
+		if err != nil {
+			return "", nil, err
+		}
+		*field = value
+	}
+
+	authURL, err := provider.AuthCodeURL(s.ctx, state.State, state.Nonce, state.Verifier)
+	if err != nil {
+		return "", nil, err
+	}
+	return authURL, state, nil
+}
+
+// Complete 使用授权码完成登录，返回关联的本地用户
+// 未关联的外部身份按配置关联邮箱相同的用户或自动创建用户
+func (s *OIDCLoginService) Complete(state *OIDCState, code string) (*model.User, error) {
+	provider, ok := oidc.Get(state.Provider)
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	idToken, err := provider.Exchange(s.ctx, code, state.Verifier)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := provider.VerifyIDToken(s.ctx, idToken, state.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := provider.Config()
+	if err := checkEmailDomain(cfg, claims); err != nil {
+		return nil, err
+	}
+
+	userData, identity, err := s.findLinkedUser(state.Provider, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if userData != nil {
+		if _, err := activeLoginUser(userData); err != nil {
+			return nil, err
+		}
+	}
+
+	now := clock.Now()
+	if identity != nil {
+		identity.Email = claims.Email
+		identity.LastLoginAt = &now
+		identity.UpdatedAt = now
+		if err := s.identityRepo.Update(identity); err != nil {
+			return nil, err
+		}
+		return userData, nil
+	}
+
+	if userData, err = s.linkOrCreateUser(cfg, claims); err != nil {
+		return nil, err
+	}
+	if _, err := activeLoginUser(userData); err != nil {
+		return nil, err
+	}
+	identity = &identitymodel.Identity{
+		ID:          uuid.New().String(),
+		UserID:      userData.ID,
+		Provider:    state.Provider,
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		LastLoginAt: &now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.identityRepo.Create(identity); err != nil {
+		return nil, err
+	}
+	return userData, nil
+}
+
+// findLinkedUser 查找外部身份关联的用户，用户已被删除时删除该关联
+func (s *OIDCLoginService) findLinkedUser(provider, subject string) (*model.User, *identitymodel.Identity, error) {
+	identity, err := s.identityRepo.FindByProviderSubject(provider, subject)
+	if errors.Is(err, repository.ErrIdentityNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userData, err := s.userRepo.FindByID(identity.UserID)
+	var notFound *repository.UserNotFoundError
+	if errors.As(err, &notFound) {
+		return nil, nil, s.identityRepo.Delete(identity.ID)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return userData, identity, nil
+}
+
+// linkOrCreateUser 为首次登录的外部身份关联已有用户或创建用户
+func (s *OIDCLoginService) linkOrCreateUser(cfg oidc.Config, claims *oidc.Claims) (*model.User, error) {
+	if !cfg.LinkByEmail && !cfg.AutoCreate {
+		return nil, ErrIdentityNotLinked
+	}
+	if claims.Email == "" {
+		return nil, ErrEmailRequired
+	}
+	if !claims.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	existing, err := s.userRepo.FindByEmail(claims.Email)
+	var notFound *repository.UserNotFoundError
+	switch {
+	case err == nil:
+		if cfg.LinkByEmail {
+			return existing, nil
+		}
+		return nil, ErrEmailTaken
+	case !errors.As(err, &notFound):
+		return nil, err
+	}
+
+	if !cfg.AutoCreate {
+		return nil, ErrIdentityNotLinked
+	}
+
+	password, err := user.GenerateRandomPassword(jitPasswordLength)
+	if err != nil {
+		return nil, err
+	}
+	avatar := claims.Picture
+	if len(avatar) > maxAvatarLength {
+		avatar = ""
+	}
+	return s.userService.CreateUser(&user.CreateUserRequest{
+		Name:     externalName(claims),
+		Email:    claims.Email,
+		Password: password,
+		Avatar:   avatar,
+	})
+}
+
+// checkEmailDomain 校验邮箱域名是否允许登录，配置了域名时要求邮箱已验证
+func checkEmailDomain(cfg oidc.Config, claims *oidc.Claims) error {
+	if len(cfg.AllowedDomains) == 0 {
+		return nil
+	}
+	if claims.Email == "" {
+		return ErrEmailRequired
+	}
+	if !claims.EmailVerified {
+		return ErrEmailNotVerified
+	}
+	_, domain, _ := strings.Cut(claims.Email, "@")
+	if !slices.ContainsFunc(cfg.AllowedDomains, func(allowed string) bool {
+		return strings.EqualFold(allowed, domain)
+	}) {
+		return ErrEmailDomainNotAllowed
+	}
+	return nil
+}
+
+// externalName 自动创建用户时使用的名称，提供方未返回名称时使用邮箱前缀
+func externalName(claims *oidc.Claims) string {
+	name := strings.TrimSpace(claims.Name)
+	if name == "" {
+		name, _, _ = strings.Cut(claims.Email, "@")
+	}
+	if runes := []rune(name); len(runes) > maxExternalNameRunes {
+		name = string(runes[:maxExternalNameRunes])
+	}
+	return name
+}
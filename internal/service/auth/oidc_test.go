@@ -0,0 +1,193 @@
+package auth_test
+
+import (
+	"errors"
+	"testing"
+
+	"gosir/internal/oidc"
+	"gosir/internal/oidc/oidctest"
+	"gosir/internal/service/auth"
+	"gosir/internal/service/user"
+	"gosir/internal/testutil"
+)
+
+// setupOIDC 初始化数据库和模拟提供方，按 cfg 注册名为 mock 的提供方
+func setupOIDC(t *testing.T, cfg oidc.Config) *oidctest.Server {
+	t.Helper()
+	testutil.SetupDB(t)
+
+	srv := oidctest.NewServer(t)
+	cfg.Issuer = srv.URL
+	cfg.ClientID = oidctest.ClientID
+	cfg.ClientSecret = oidctest.ClientSecret
+	cfg.RedirectURL = "https://app.example.com/auth/oidc/mock/callback"
+	oidc.Init(map[string]oidc.Config{"mock": cfg})
+	t.Cleanup(func() { oidc.Init(nil) })
+	return srv
+}
+
+// oidcLogin 走完一次登录：发起登录、在提供方同意授权、回调时完成登录
+// tamper 在回调前修改保存的临时参数，用于模拟 nonce 被篡改等情况
+func oidcLogin(t *testing.T, srv *oidctest.Server, tamper func(*auth.OIDCState)) (string, error) {
+	t.Helper()
+	svc := auth.NewOIDCLoginService()
+	authURL, state, err := svc.Begin("mock")
+	if err != nil {
+		t.Fatalf("Begin() error: %v", err)
+	}
+	code, returnedState, err := srv.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize() error: %v", err)
+	}
+	if returnedState != state.State {
+		t.Fatalf("state = %q, want %q", returnedState, state.State)
+	}
+	if tamper != nil {
+		tamper(state)
+	}
+
+	u, err := svc.Complete(state, code)
+	if err != nil {
+		return "", err
+	}
+	return u.ID, nil
+}
+
+// createUser 创建本地用户
+func createUser(t *testing.T, email string) string {
+	t.Helper()
+	u, err := user.NewUserService().CreateUser(&user.CreateUserRequest{
+		Name:     "existing",
+		Email:    email,
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+	return u.ID
+}
+
+func TestOIDCLoginAutoCreate(t *testing.T) {
+	srv := setupOIDC(t, oidc.Config{AutoCreate: true})
+
+	first, err := oidcLogin(t, srv, nil)
+	if err != nil {
+		t.Fatalf("first login error: %v", err)
+	}
+	u, err := user.NewUserService().GetUserByID(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Email != "alice@example.com" || u.Name != "Alice" {
+		t.Errorf("created user = %s <%s>, want Alice <alice@example.com>", u.Name, u.Email)
+	}
+
+	// 再次登录使用已关联的用户，即使提供方返回的邮箱已变化
+	srv.Claims = map[string]any{"email": "alice@new.example.com"}
+	second, err := oidcLogin(t, srv, nil)
+	if err != nil {
+		t.Fatalf("second login error: %v", err)
+	}
+	if second != first {
+		t.Errorf("second login user = %s, want %s", second, first)
+	}
+}
+
+func TestOIDCLoginLinkByEmail(t *testing.T) {
+	srv := setupOIDC(t, oidc.Config{LinkByEmail: true})
+	existing := createUser(t, "alice@example.com")
+
+	// 未验证的邮箱不能关联已有用户
+	srv.Claims = map[string]any{"email_verified": false}
+	if _, err := oidcLogin(t, srv, nil); !errors.Is(err, auth.ErrEmailNotVerified) {
+		t.Fatalf("unverified email error = %v, want %v", err, auth.ErrEmailNotVerified)
+	}
+
+	srv.Claims = map[string]any{"email": "Alice@Example.com"}
+	linked, err := oidcLogin(t, srv, nil)
+	if err != nil {
+		t.Fatalf("verified email login error: %v", err)
+	}
+	if linked != existing {
+		t.Errorf("linked user = %s, want %s", linked, existing)
+	}
+}
+
+func TestOIDCLoginRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     oidc.Config
+		claims  map[string]any
+		tamper  func(*auth.OIDCState)
+		wantErr error
+	}{
+		{
+			name:    "nonce 被篡改",
+			cfg:     oidc.Config{AutoCreate: true},
+			tamper:  func(s *auth.OIDCState) { s.Nonce = "forged" },
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name:    "受众不是当前客户端",
+			cfg:     oidc.Config{AutoCreate: true},
+			claims:  map[string]any{"aud": "other-client"},
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name:    "自动创建要求邮箱已验证",
+			cfg:     oidc.Config{AutoCreate: true},
+			claims:  map[string]any{"email_verified": false},
+			wantErr: auth.ErrEmailNotVerified,
+		},
+		{
+			name:    "缺少邮箱",
+			cfg:     oidc.Config{AutoCreate: true},
+			claims:  map[string]any{"email": nil},
+			wantErr: auth.ErrEmailRequired,
+		},
+		{
+			name:    "邮箱域名不在允许列表中",
+			cfg:     oidc.Config{AutoCreate: true, AllowedDomains: []string{"corp.example.com"}},
+			wantErr: auth.ErrEmailDomainNotAllowed,
+		},
+		{
+			name:    "允许的域名要求邮箱已验证",
+			cfg:     oidc.Config{AutoCreate: true, AllowedDomains: []string{"example.com"}},
+			claims:  map[string]any{"email_verified": false},
+			wantErr: auth.ErrEmailNotVerified,
+		},
+		{
+			name:    "未开启关联和自动创建",
+			cfg:     oidc.Config{},
+			wantErr: auth.ErrIdentityNotLinked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := setupOIDC(t, tt.cfg)
+			srv.Claims = tt.claims
+			if _, err := oidcLogin(t, srv, tt.tamper); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Complete() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOIDCLoginAllowedDomain(t *testing.T) {
+	srv := setupOIDC(t, oidc.Config{AutoCreate: true, AllowedDomains: []string{"Corp.Example.com"}})
+	srv.Claims = map[string]any{"email": "bob@corp.example.com"}
+	if _, err := oidcLogin(t, srv, nil); err != nil {
+		t.Errorf("login from allowed domain error: %v", err)
+	}
+}
+
+func TestOIDCLoginEmailTaken(t *testing.T) {
+	srv := setupOIDC(t, oidc.Config{AutoCreate: true})
+	createUser(t, "alice@example.com")
+
+	// 只开启自动创建时不关联邮箱相同的已有用户
+	if _, err := oidcLogin(t, srv, nil); !errors.Is(err, auth.ErrEmailTaken) {
+		t.Errorf("Complete() error = %v, want %v", err, auth.ErrEmailTaken)
+	}
+}
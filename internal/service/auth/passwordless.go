@@ -0,0 +1,312 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gosir/internal/clock"
+	"gosir/internal/logger"
+	logincodemodel "gosir/internal/model/logincode"
+	model "gosir/internal/model/user"
+	"gosir/internal/notify"
+	"gosir/internal/oidc"
+	"gosir/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 免密登录方式
+const (
+	PasswordlessCode = logincodemodel.KindCode // 验证码
+	PasswordlessLink = logincodemodel.KindLink // 登录链接
+)
+
+var (
+	// ErrLinkNotConfigured 未配置登录链接地址，只能使用验证码
+	ErrLinkNotConfigured = errors.New("magic link not configured")
+	// ErrChannelUnsupported 通知发送方式不支持该账号类型（如 smtp 不能发送短信）
+	ErrChannelUnsupported = errors.New("notify channel unsupported")
+	// ErrTooManyCodeRequests 账号请求验证码过于频繁
+	ErrTooManyCodeRequests = errors.New("too many login code requests")
+	// ErrInvalidLoginCode 验证码或登录链接无效、已使用或已过期
+	ErrInvalidLoginCode = errors.New("invalid or expired login code")
+)
+
+// PasswordlessOptions 免密登录选项
+type PasswordlessOptions struct {
+	CodeTTL     time.Duration // 验证码和登录链接有效期
+	CodeLength  int           // 验证码位数
+	MaxAttempts int           // 每个验证码允许输错的次数
+	SendLimit   int           // 每个账号每个周期最多发送次数，0 表示不限制
+	SendPeriod  time.Duration // 发送次数限制周期
+	LinkURL     string        // 登录链接指向的前端页面，为空时不支持登录链接
+}
+
+// passwordlessOptions 当前免密登录选项，未开启时为空
+var passwordlessOptions atomic.Pointer[PasswordlessOptions]
+
+// InitPasswordless 开启免密登录
+func InitPasswordless(opts PasswordlessOptions) {
+	passwordlessOptions.Store(&opts)
+}
+
+// PasswordlessEnabled 是否已开启免密登录
+func PasswordlessEnabled() bool {
+	return passwordlessOptions.Load() != nil
+}
+
+// PasswordlessService 免密登录服务
+type PasswordlessService struct {
+	ctx      context.Context
+	codeRepo *repository.LoginCodeRepository
+	userRepo *repository.UserRepository
+}
+
+// NewPasswordlessService 创建免密登录服务
+func NewPasswordlessService() *PasswordlessService {
+	return &PasswordlessService{
+		ctx:      context.Background(),
+		codeRepo: repository.NewLoginCodeRepository(),
+		userRepo: repository.NewUserRepository(),
+	}
+}
+
+// WithContext 返回绑定 context 的服务实例
+func (s *PasswordlessService) WithContext(ctx context.Context) *PasswordlessService {
+	return &PasswordlessService{
+		ctx:      ctx,
+		codeRepo: s.codeRepo.WithContext(ctx),
+		userRepo: s.userRepo.WithContext(ctx),
+	}
+}
+
+// Start 向账号（邮箱或手机号）发送验证码或登录链接
+// 账号不存在或已禁用时同样返回成功，不暴露账号是否存在；通知在后台发送，失败只记录日志
+func (s *PasswordlessService) Start(account, method string) error {
+	opts, err := currentPasswordlessOptions()
+	if err != nil {
+		return err
+	}
+	account = strings.TrimSpace(account)
+	if method == PasswordlessLink && opts.LinkURL == "" {
+		return ErrLinkNotConfigured
+	}
+	channel := accountChannel(account)
+	if !notify.Supports(channel) {
+		return ErrChannelUnsupported
+	}
+
+	// 按账号限流（无论账号是否存在），防止轰炸用户邮箱和短信
+	allowed, err := takeSendQuota(s.ctx, "passwordless:"+strings.ToLower(account), opts.SendLimit, opts.SendPeriod)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrTooManyCodeRequests
+	}
+
+	log := logger.NamedCtx(s.ctx, logger.ModuleAuth).With(zap.String("method", method))
+	userData, err := s.userRepo.FindByEmailOrPhone(account)
+	var notFound *repository.UserNotFoundError
+	if errors.As(err, &notFound) {
+		log.Info("Passwordless login requested for unknown account", zap.String("account", account))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if userData.Status != int(model.UserStatusNormal) {
+		log.Info("Passwordless login requested for inactive user", zap.String("user_id", userData.ID), zap.Int("status", userData.Status))
+		return nil
+	}
+
+	id := uuid.New().String()
+	var secret string
+	if method == PasswordlessCode {
+		secret, err = randomDigits(opts.CodeLength)
+	} else {
+		secret, err = oidc.RandomString(randomBytes)
+	}
+	if err != nil {
+		return err
+	}
+
+	now := clock.Now()
+	if err := s.codeRepo.Replace(&logincodemodel.LoginCode{
+		ID:         id,
+		UserID:     userData.ID,
+		Kind:       method,
+		Channel:    channel,
+		SecretHash: hashLoginSecret(id, secret),
+		ExpiresAt:  now.Add(opts.CodeTTL),
+		CreatedAt:  now,
+	}); err != nil {
+		return err
+	}
+
+	sendInBackground(s.ctx, passwordlessMessage(opts, channel, account, method, id, secret), userData.ID)
+	log.Info("Passwordless login message issued", zap.String("user_id", userData.ID), zap.String("channel", channel))
+	return nil
+}
+
+// VerifyCode 校验账号的验证码，成功后验证码失效
+func (s *PasswordlessService) VerifyCode(account, code string) (*model.User, error) {
+	opts, err := currentPasswordlessOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	userData, err := s.userRepo.FindByEmailOrPhone(strings.TrimSpace(account))
+	var notFound *repository.UserNotFoundError
+	if errors.As(err, &notFound) {
+		return nil, ErrInvalidLoginCode
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := s.codeRepo.FindActive(userData.ID, PasswordlessCode, opts.MaxAttempts, clock.Now())
+	if errors.Is(err, repository.ErrLoginCodeNotFound) {
+		return nil, ErrInvalidLoginCode
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.consume(opts, record, strings.TrimSpace(code)); err != nil {
+		return nil, err
+	}
+	return activeLoginUser(userData)
+}
+
+// VerifyLink 校验登录链接中的 token（{id}.{secret}），成功后链接失效
+func (s *PasswordlessService) VerifyLink(token string) (*model.User, error) {
+	opts, err := currentPasswordlessOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	id, secret, ok := strings.Cut(token, ".")
+	if !ok || id == "" || secret == "" {
+		return nil, ErrInvalidLoginCode
+	}
+	record, err := s.codeRepo.FindByID(id)
+	if errors.Is(err, repository.ErrLoginCodeNotFound) {
+		return nil, ErrInvalidLoginCode
+	}
+	if err != nil {
+		return nil, err
+	}
+	if record.Kind != PasswordlessLink || !clock.Now().Before(record.ExpiresAt) {
+		return nil, ErrInvalidLoginCode
+	}
+	if err := s.consume(opts, record, secret); err != nil {
+		return nil, err
+	}
+
+	userData, err := s.userRepo.FindByID(record.UserID)
+	if err != nil {
+		return nil, ErrInvalidLoginCode
+	}
+	return activeLoginUser(userData)
+}
+
+// consume 占用一次尝试次数后比较凭证，匹配时标记已使用
+func (s *PasswordlessService) consume(opts *PasswordlessOptions, record *logincodemodel.LoginCode, secret string) error {
+	err := s.codeRepo.UseAttempt(record.ID, opts.MaxAttempts)
+	if errors.Is(err, repository.ErrLoginCodeNotFound) {
+		return ErrInvalidLoginCode
+	}
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashLoginSecret(record.ID, secret)), []byte(record.SecretHash)) != 1 {
+		return ErrInvalidLoginCode
+	}
+
+	err = s.codeRepo.MarkUsed(record.ID, clock.Now())
+	if errors.Is(err, repository.ErrLoginCodeNotFound) {
+		return ErrInvalidLoginCode
+	}
+	return err
+}
+
+// currentPasswordlessOptions 获取免密登录选项
+func currentPasswordlessOptions() (*PasswordlessOptions, error) {
+	opts := passwordlessOptions.Load()
+	if opts == nil {
+		return nil, errors.New("passwordless login not enabled")
+	}
+	return opts, nil
+}
+
+// accountChannel 根据账号格式选择通知渠道：包含 @ 的为邮箱，否则为手机号
+func accountChannel(account string) string {
+	if strings.Contains(account, "@") {
+		return notify.ChannelEmail
+	}
+	return notify.ChannelSMS
+}
+
+// passwordlessMessage 构建验证码或登录链接通知
+func passwordlessMessage(opts *PasswordlessOptions, channel, to, method, id, secret string) notify.Message {
+	ttl := formatTTL(opts.CodeTTL)
+	if method == PasswordlessCode {
+		return notify.Message{
+			Channel: channel,
+			To:      to,
+			Subject: "gosir 登录验证码",
+			Body:    fmt.Sprintf("你的登录验证码是 %s，%s内有效。如果不是你本人操作，请忽略。", secret, ttl),
+		}
+	}
+
+	link, _ := url.Parse(opts.LinkURL)
+	query := link.Query()
+	query.Set("token", id+"."+secret)
+	link.RawQuery = query.Encode()
+	return notify.Message{
+		Channel: channel,
+		To:      to,
+		Subject: "gosir 登录链接",
+		Body:    fmt.Sprintf("点击以下链接登录 gosir（%s内有效，只能使用一次）：\n%s\n如果不是你本人操作，请忽略。", ttl, link.String()),
+	}
+}
+
+// formatTTL 有效期的中文描述
+func formatTTL(ttl time.Duration) string {
+	if ttl >= time.Minute && ttl%time.Minute == 0 {
+		return fmt.Sprintf("%d 分钟", int(ttl/time.Minute))
+	}
+	return fmt.Sprintf("%d 秒", int(ttl/time.Second))
+}
+
+// randomDigits 生成 n 位随机数字验证码（n 不超过 18）
+func randomDigits(n int) (string, error) {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+	v, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", n, v.Int64()), nil
+}
+
+// hashLoginSecret 计算凭证哈希，加入 ID 使相同的验证码在不同记录中哈希不同
+func hashLoginSecret(id, secret string) string {
+	sum := sha256.Sum256([]byte(id + ":" + secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// DeleteExpiredLoginCodes 清理过期和已使用的免密登录凭证，返回删除数量
+func DeleteExpiredLoginCodes(ctx context.Context) (int64, error) {
+	return repository.NewLoginCodeRepository().WithContext(ctx).DeleteExpired(clock.Now())
+}
@@ -0,0 +1,321 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gosir/internal/clock"
+	"gosir/internal/common"
+	"gosir/internal/logger"
+	model "gosir/internal/model/user"
+	"gosir/internal/notify"
+	"gosir/internal/ratelimit"
+	"gosir/internal/repository"
+	"gosir/internal/service/audit"
+	"gosir/internal/service/user"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrUserNotVerified 用户注册后尚未验证邮箱
+	ErrUserNotVerified = errors.New("user email not verified")
+	// ErrInvalidVerifyToken 邮箱验证链接无效或已过期
+	ErrInvalidVerifyToken = errors.New("invalid or expired verification token")
+	// ErrTooManyVerifyRequests 重新发送验证邮件过于频繁
+	ErrTooManyVerifyRequests = errors.New("too many verification email requests")
+)
+
+// RegisterOptions 自助注册选项
+type RegisterOptions struct {
+	VerifyURL  string        // 邮箱验证链接地址
+	VerifyTTL  time.Duration // 注册后完成验证的期限（重新发送不延长）
+	SendLimit  int           // 每个邮箱每个周期最多重新发送次数，0 表示不限制
+	SendPeriod time.Duration // 重新发送次数限制周期
+}
+
+// registerOptions 当前自助注册选项，未开启时为空
+var registerOptions atomic.Pointer[RegisterOptions]
+
+// InitRegister 开启自助注册
+func InitRegister(opts RegisterOptions) {
+	registerOptions.Store(&opts)
+}
+
+// RegisterEnabled 是否已开启自助注册
+func RegisterEnabled() bool {
+	return registerOptions.Load() != nil
+}
+
+// RegisterService 自助注册服务
+type RegisterService struct {
+	ctx         context.Context
+	userRepo    *repository.UserRepository
+	userService *user.UserService
+}
+
+// NewRegisterService 创建自助注册服务
+func NewRegisterService() *RegisterService {
+	return &RegisterService{
+		ctx:         context.Background(),
+		userRepo:    repository.NewUserRepository(),
+		userService: user.NewUserService(),
+	}
+}
+
+// WithContext 返回绑定 context 的服务实例
+func (s *RegisterService) WithContext(ctx context.Context) *RegisterService {
+	return &RegisterService{
+		ctx:         ctx,
+		userRepo:    s.userRepo.WithContext(ctx),
+		userService: s.userService.WithContext(ctx),
+	}
+}
+
+// Register 注册用户并发送邮箱验证链接，用户验证邮箱前不能登录
+// 为避免暴露邮箱是否已注册，邮箱已被使用时同样返回成功，改为通知邮箱所有人（未验证的注册重新发送验证链接）
+// 超过验证期限仍未验证的注册会被删除，邮箱可以重新注册
+func (s *RegisterService) Register(name, email, password string) error {
+	opts, err := currentRegisterOptions()
+	if err != nil {
+		return err
+	}
+	email = model.NormalizeEmail(email)
+
+	existing, err := s.userRepo.FindByEmailWithDeleted(email)
+	var notFound *repository.UserNotFoundError
+	switch {
+	case errors.As(err, &notFound):
+	case err != nil:
+		return err
+	case existing.Status == int(model.UserStatusPending) && !existing.DeletedAt.Valid &&
+		clock.Now().After(verifyDeadline(opts, existing)):
+		if _, err := s.userRepo.DeleteUnverified(clock.Now().Add(-opts.VerifyTTL)); err != nil {
+			return err
+		}
+	default:
+		return s.notifyExisting(opts, existing, password)
+	}
+
+	status := int(model.UserStatusPending)
+	userData, err := s.userService.CreateUser(&user.CreateUserRequest{
+		Name:     strings.TrimSpace(name),
+		Email:    email,
+		Password: password,
+		Status:   &status,
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.sendVerification(opts, userData); err != nil {
+		return err
+	}
+
+	logger.NamedCtx(s.ctx, logger.ModuleAuth).Info("User registered", zap.String("user_id", userData.ID))
+	return nil
+}
+
+// notifyExisting 邮箱已被使用时通知邮箱所有人，发送次数与重新发送验证邮件共用限制，超过限制时不发送
+// 同样计算一次密码哈希，使响应时间与新注册接近
+func (s *RegisterService) notifyExisting(opts *RegisterOptions, existing *model.User, password string) error {
+	if _, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost); err != nil {
+		return err
+	}
+	log := logger.NamedCtx(s.ctx, logger.ModuleAuth)
+
+	// 已删除用户的邮箱不能重新注册，也不再通知
+	if existing.DeletedAt.Valid {
+		log.Info("Registration with email of a deleted user ignored", zap.String("user_id", existing.ID))
+		return nil
+	}
+
+	allowed, err := takeSendQuota(s.ctx, "register:"+model.NormalizeEmail(existing.Email), opts.SendLimit, opts.SendPeriod)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		log.Info("Registration notice for existing email skipped, send limit reached", zap.String("user_id", existing.ID))
+		return nil
+	}
+
+	if existing.Status == int(model.UserStatusPending) {
+		log.Info("Registration with unverified email, verification resent", zap.String("user_id", existing.ID))
+		return s.sendVerification(opts, existing)
+	}
+	log.Info("Registration with existing email, owner notified", zap.String("user_id", existing.ID))
+	sendInBackground(s.ctx, notify.Message{
+		Channel: notify.ChannelEmail,
+		To:      existing.Email,
+		Subject: "有人使用你的邮箱注册 gosir 账号",
+		Body: fmt.Sprintf("%s，你好：\n\n有人使用你的邮箱注册 gosir 账号，但该邮箱已经有账号，注册未完成。你可以直接使用该邮箱登录。\n\n如果不是你本人操作，请忽略此邮件。",
+			existing.Name),
+	}, existing.ID)
+	return nil
+}
+
+// Verify 校验邮箱验证链接中的 token 并激活用户，已激活的用户再次验证直接返回成功
+func (s *RegisterService) Verify(token string) (*model.User, error) {
+	claims, err := common.GetJWTManager().ValidateVerificationToken(token)
+	if err != nil {
+		return nil, ErrInvalidVerifyToken
+	}
+
+	userData, err := s.userRepo.FindByID(claims.UserID)
+	var notFound *repository.UserNotFoundError
+	if errors.As(err, &notFound) {
+		return nil, ErrInvalidVerifyToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	// 邮箱修改后旧链接失效
+	if userData.Email != claims.Email {
+		return nil, ErrInvalidVerifyToken
+	}
+
+	switch model.UserStatus(userData.Status) {
+	case model.UserStatusNormal:
+		return userData, nil
+	case model.UserStatusPending:
+		now := clock.Now()
+		activated, err := s.userRepo.Activate(userData.ID, now)
+		if err != nil {
+			return nil, err
+		}
+		if activated {
+			userData.Status = int(model.UserStatusNormal)
+			userData.UpdatedAt = now
+			userData.Version++
+			audit.Record(s.ctx, audit.Entry{
+				ActorID:    userData.ID,
+				Action:     audit.ActionUserUpdate,
+				TargetType: audit.TargetUser,
+				TargetID:   userData.ID,
+				Changes:    audit.Diff(map[string]any{"status": int(model.UserStatusPending)}, map[string]any{"status": userData.Status}),
+				Detail:     "email verified",
+			})
+			logger.NamedCtx(s.ctx, logger.ModuleAuth).Info("User email verified", zap.String("user_id", userData.ID))
+			return userData, nil
+		}
+		// 并发验证时状态已被修改，重新读取
+		return s.Verify(token)
+	default:
+		return nil, ErrUserDisabled
+	}
+}
+
+// Resend 重新发送邮箱验证链接，链接的有效期不会延长
+// 邮箱不存在、已验证或已过验证期限时同样返回成功，不暴露账号是否存在
+func (s *RegisterService) Resend(email string) error {
+	opts, err := currentRegisterOptions()
+	if err != nil {
+		return err
+	}
+	email = model.NormalizeEmail(email)
+
+	allowed, err := takeSendQuota(s.ctx, "register:"+email, opts.SendLimit, opts.SendPeriod)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrTooManyVerifyRequests
+	}
+
+	userData, err := s.userRepo.FindByEmail(email)
+	var notFound *repository.UserNotFoundError
+	if errors.As(err, &notFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if userData.Status != int(model.UserStatusPending) || clock.Now().After(verifyDeadline(opts, userData)) {
+		return nil
+	}
+	return s.sendVerification(opts, userData)
+}
+
+// sendVerification 签发验证链接并在后台发送邮件
+func (s *RegisterService) sendVerification(opts *RegisterOptions, userData *model.User) error {
+	deadline := verifyDeadline(opts, userData)
+	token, err := common.GenerateVerificationToken(userData.ID, userData.Email, deadline)
+	if err != nil {
+		return err
+	}
+
+	link, err := url.Parse(opts.VerifyURL)
+	if err != nil {
+		return err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	sendInBackground(s.ctx, notify.Message{
+		Channel: notify.ChannelEmail,
+		To:      userData.Email,
+		Subject: "验证你的 gosir 账号邮箱",
+		Body: fmt.Sprintf("%s，你好：\n\n请在 %s 前点击以下链接完成邮箱验证：\n%s\n\n如果不是你本人注册，请忽略此邮件，未验证的账号会被自动删除。",
+			userData.Name, deadline.Format("2006-01-02 15:04 MST"), link.String()),
+	}, userData.ID)
+	return nil
+}
+
+// verifyDeadline 用户完成邮箱验证的期限
+func verifyDeadline(opts *RegisterOptions, userData *model.User) time.Time {
+	return userData.CreatedAt.Add(opts.VerifyTTL)
+}
+
+// currentRegisterOptions 获取自助注册选项
+func currentRegisterOptions() (*RegisterOptions, error) {
+	opts := registerOptions.Load()
+	if opts == nil {
+		return nil, errors.New("registration not enabled")
+	}
+	return opts, nil
+}
+
+// takeSendQuota 按 key 限制通知发送次数，limit 为 0 表示不限制
+func takeSendQuota(ctx context.Context, key string, limit int, period time.Duration) (bool, error) {
+	if limit <= 0 {
+		return true, nil
+	}
+	result, err := ratelimit.Take(ctx, ratelimit.Rule{
+		Algorithm: ratelimit.AlgorithmSlidingWindow,
+		Limit:     limit,
+		Period:    period,
+	}, key)
+	if err != nil {
+		return false, err
+	}
+	return result.Allowed, nil
+}
+
+// sendInBackground 在后台发送通知（不受请求结束影响），失败只记录日志
+func sendInBackground(ctx context.Context, msg notify.Message, userID string) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if err := notify.Send(ctx, msg); err != nil {
+			logger.NamedCtx(ctx, logger.ModuleAuth).Error("Failed to send notification",
+				zap.String("user_id", userID),
+				zap.String("subject", msg.Subject),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// DeleteUnverifiedUsers 删除超过验证期限仍未验证邮箱的注册用户，未开启自助注册时不删除
+func DeleteUnverifiedUsers(ctx context.Context) (int64, error) {
+	opts := registerOptions.Load()
+	if opts == nil {
+		return 0, nil
+	}
+	return repository.NewUserRepository().WithContext(ctx).DeleteUnverified(clock.Now().Add(-opts.VerifyTTL))
+}
@@ -0,0 +1,190 @@
+package oauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+
+	"gosir/internal/clock"
+	oauthmodel "gosir/internal/model/oauth"
+	"gosir/internal/repository"
+)
+
+// clientIDPrefix client_id 前缀
+const clientIDPrefix = "gsc_"
+
+var (
+	// ErrClientNotFound 客户端不存在
+	ErrClientNotFound = repository.ErrOAuthClientNotFound
+	// ErrInvalidRedirectURI 回调地址不是不含 fragment 的绝对地址
+	ErrInvalidRedirectURI = errors.New("invalid redirect uri")
+	// ErrRedirectURIRequired 允许授权码模式的客户端必须登记回调地址
+	ErrRedirectURIRequired = errors.New("redirect uri required for authorization_code")
+	// ErrInvalidGrantType 未知的授权类型
+	ErrInvalidGrantType = errors.New("invalid grant type")
+	// ErrPublicClientCredentials 公开客户端不能使用客户端凭证模式
+	ErrPublicClientCredentials = errors.New("public clients cannot use client_credentials")
+	// ErrInvalidScope 未知的 scope
+	ErrInvalidScope = errors.New("invalid scope")
+)
+
+// ClientService OAuth 客户端管理服务
+type ClientService struct {
+	clientRepo *repository.OAuthClientRepository
+}
+
+// NewClientService 创建 OAuth 客户端管理服务
+func NewClientService() *ClientService {
+	return &ClientService{
+		clientRepo: repository.NewOAuthClientRepository(),
+	}
+}
+
+// WithContext 返回绑定 context 的服务实例
+func (s *ClientService) WithContext(ctx context.Context) *ClientService {
+	return &ClientService{
+		clientRepo: s.clientRepo.WithContext(ctx),
+	}
+}
+
+// ClientRequest 创建或修改客户端的参数
+type ClientRequest struct {
+	Name         string
+	RedirectURIs []string
+	GrantTypes   []string
+	Scopes       []string
+	SkipConsent  bool
+}
+
+// Create 创建客户端，public 为 true 时创建公开客户端（没有密钥），否则返回只在创建时可见的 client_secret
+func (s *ClientService) Create(req ClientRequest, public bool) (*oauthmodel.Client, string, error) {
+	if err := validateClient(req, public); err != nil {
+		return nil, "", err
+	}
+
+	id, err := randomToken()
+	if err != nil {
+		return nil, "", err
+	}
+	var secret, secretHash string
+	if !public {
+		if secret, err = randomToken(); err != nil {
+			return nil, "", err
+		}
+		secretHash = hashToken(secret)
+	}
+
+	now := clock.Now()
+	client := &oauthmodel.Client{
+		ID:           clientIDPrefix + id[:20],
+		Name:         req.Name,
+		SecretHash:   secretHash,
+		RedirectURIs: req.RedirectURIs,
+		GrantTypes:   normalizeList(req.GrantTypes),
+		Scopes:       normalizeList(req.Scopes),
+		SkipConsent:  req.SkipConsent,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.clientRepo.Create(client); err != nil {
+		return nil, "", err
+	}
+	return client, secret, nil
+}
+
+// List 获取所有客户端
+func (s *ClientService) List() ([]*oauthmodel.Client, error) {
+	return s.clientRepo.FindAll()
+}
+
+// Get 获取客户端
+func (s *ClientService) Get(id string) (*oauthmodel.Client, error) {
+	return s.clientRepo.FindByID(id)
+}
+
+// Update 修改客户端（不能在公开客户端和机密客户端之间转换）
+func (s *ClientService) Update(id string, req ClientRequest) (*oauthmodel.Client, error) {
+	client, err := s.clientRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateClient(req, client.Public()); err != nil {
+		return nil, err
+	}
+
+	client.Name = req.Name
+	client.RedirectURIs = req.RedirectURIs
+	client.GrantTypes = normalizeList(req.GrantTypes)
+	client.Scopes = normalizeList(req.Scopes)
+	client.SkipConsent = req.SkipConsent
+	client.UpdatedAt = clock.Now()
+	if err := s.clientRepo.Update(client); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// RotateSecret 重新生成机密客户端的 client_secret，旧密钥立即失效
+func (s *ClientService) RotateSecret(id string) (*oauthmodel.Client, string, error) {
+	client, err := s.clientRepo.FindByID(id)
+	if err != nil {
+		return nil, "", err
+	}
+	if client.Public() {
+		return nil, "", ErrPublicClientCredentials
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return nil, "", err
+	}
+	client.SecretHash = hashToken(secret)
+	client.UpdatedAt = clock.Now()
+	if err := s.clientRepo.Update(client); err != nil {
+		return nil, "", err
+	}
+	return client, secret, nil
+}
+
+// Delete 删除客户端，已签发的刷新令牌同时失效
+func (s *ClientService) Delete(id string) error {
+	return s.clientRepo.Delete(id)
+}
+
+// verifySecret 校验 client_secret
+func verifySecret(client *oauthmodel.Client, secret string) bool {
+	if client.Public() || secret == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(client.SecretHash), []byte(hashToken(secret))) == 1
+}
+
+// validateClient 校验客户端参数
+func validateClient(req ClientRequest, public bool) error {
+	for _, grant := range req.GrantTypes {
+		if !slices.Contains(GrantTypes, grant) {
+			return ErrInvalidGrantType
+		}
+	}
+	if public && slices.Contains(req.GrantTypes, GrantClientCredentials) {
+		return ErrPublicClientCredentials
+	}
+	if slices.Contains(req.GrantTypes, GrantAuthorizationCode) && len(req.RedirectURIs) == 0 {
+		return ErrRedirectURIRequired
+	}
+	for _, uri := range req.RedirectURIs {
+		u, err := url.Parse(uri)
+		if err != nil || !u.IsAbs() || u.Host == "" || u.Fragment != "" || strings.ContainsAny(uri, " \t\r\n") {
+			return ErrInvalidRedirectURI
+		}
+	}
+	for _, scope := range req.Scopes {
+		if !slices.Contains(Scopes, scope) {
+			return ErrInvalidScope
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gosir/internal/service/apikey"
+)
+
+// 授权类型
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantClientCredentials = "client_credentials"
+	GrantRefreshToken      = "refresh_token"
+)
+
+// GrantTypes 支持的授权类型
+var GrantTypes = []string{GrantAuthorizationCode, GrantClientCredentials, GrantRefreshToken}
+
+// OIDC 身份 scope，只能用于代表用户的授权（授权码模式）
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// identityScopes OIDC 身份 scope
+var identityScopes = []string{ScopeOpenID, ScopeProfile, ScopeEmail}
+
+// Scopes 支持的 scope：OIDC 身份 scope 和与 API Key 相同的接口权限范围
+var Scopes = append(slices.Clone(identityScopes), apikey.Scopes...)
+
+// ScopeDescriptions 授权页面展示的 scope 说明
+var ScopeDescriptions = map[string]string{
+	ScopeOpenID:            "确认你的身份",
+	ScopeProfile:           "读取你的名称和头像",
+	ScopeEmail:             "读取你的邮箱",
+	apikey.ScopeUsersRead:  "查询用户信息",
+	apikey.ScopeUsersWrite: "创建、修改和删除用户",
+	apikey.ScopeAdmin:      "访问管理员接口",
+}
+
+// Options 授权服务选项
+type Options struct {
+	Issuer          string        // 对外地址（签发者）
+	SigningKey      *SigningKey   // ID Token 签名密钥
+	CodeTTL         time.Duration // 授权码有效期
+	AccessTokenTTL  time.Duration // 访问令牌有效期
+	RefreshTokenTTL time.Duration // 刷新令牌有效期
+	IDTokenTTL      time.Duration // ID Token 有效期
+	SessionTTL      time.Duration // 授权页面登录会话有效期
+}
+
+// options 当前授权服务选项，未开启授权服务时为空
+var options atomic.Pointer[Options]
+
+// Init 开启授权服务
+func Init(opts Options) {
+	opts.Issuer = strings.TrimSuffix(opts.Issuer, "/")
+	options.Store(&opts)
+}
+
+// Enabled 是否已开启授权服务
+func Enabled() bool {
+	return options.Load() != nil
+}
+
+// currentOptions 获取授权服务选项
+func currentOptions() (*Options, error) {
+	opts := options.Load()
+	if opts == nil {
+		return nil, fmt.Errorf("oauth server not initialized")
+	}
+	return opts, nil
+}
+
+// randomToken 生成 32 字节随机数的 base64url 编码，用于授权码、刷新令牌和客户端密钥
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken 计算授权码、刷新令牌和客户端密钥的 SHA-256（只保存哈希）
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeList 去重并排序（scope 和授权类型）
+func normalizeList(scopes []string) []string {
+	result := slices.Clone(scopes)
+	slices.Sort(result)
+	return slices.Compact(result)
+}
+
+// requiresUser scope 是否只能用于代表用户的授权：OIDC 身份 scope，以及以用户身份操作的接口权限范围
+// 客户端凭证模式签发的令牌不代表任何用户，不能申请这些 scope
+func requiresUser(scope string) bool {
+	return slices.Contains(identityScopes, scope) || slices.Contains(apikey.Scopes, scope)
+}
+
+// Metadata 授权服务元数据（OIDC Discovery 和 RFC 8414）
+func Metadata() (map[string]any, error) {
+	opts, err := currentOptions()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"issuer":                                opts.Issuer,
+		"authorization_endpoint":                opts.Issuer + "/oauth/authorize",
+		"token_endpoint":                        opts.Issuer + "/oauth/token",
+		"userinfo_endpoint":                     opts.Issuer + "/oauth/userinfo",
+		"jwks_uri":                              opts.Issuer + "/oauth/jwks",
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 GrantTypes,
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{opts.SigningKey.Algorithm()},
+		"scopes_supported":                      Scopes,
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"claims_supported":                      []string{"sub", "iss", "aud", "exp", "iat", "nonce", "name", "picture", "email"},
+		"prompt_values_supported":               []string{PromptNone, PromptLogin, PromptConsent},
+	}, nil
+}
+
+// JWKS ID Token 签名公钥集合
+func JWKS() (map[string]any, error) {
+	opts, err := currentOptions()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"keys": []map[string]string{opts.SigningKey.JWK()}}, nil
+}
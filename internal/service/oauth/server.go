@@ -0,0 +1,546 @@
+package oauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gosir/internal/clock"
+	"gosir/internal/common"
+	oauthmodel "gosir/internal/model/oauth"
+	usermodel "gosir/internal/model/user"
+	"gosir/internal/oidc"
+	"gosir/internal/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// OAuth 协议错误码（RFC 6749 4.1.2.1、5.2，OIDC Core 3.1.2.6，RFC 6750 3.1）
+const (
+	ErrCodeInvalidRequest          = "invalid_request"
+	ErrCodeInvalidClient           = "invalid_client"
+	ErrCodeInvalidGrant            = "invalid_grant"
+	ErrCodeUnauthorizedClient      = "unauthorized_client"
+	ErrCodeUnsupportedGrantType    = "unsupported_grant_type"
+	ErrCodeUnsupportedResponseType = "unsupported_response_type"
+	ErrCodeInvalidScope            = "invalid_scope"
+	ErrCodeAccessDenied            = "access_denied"
+	ErrCodeLoginRequired           = "login_required"
+	ErrCodeConsentRequired         = "consent_required"
+	ErrCodeInvalidToken            = "invalid_token"
+	ErrCodeInsufficientScope       = "insufficient_scope"
+)
+
+// Error OAuth 协议错误，按协议格式返回给客户端
+type Error struct {
+	Code        string // 错误码
+	Description string // 错误说明
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// newError 创建 OAuth 协议错误
+func newError(code, description string) *Error {
+	return &Error{Code: code, Description: description}
+}
+
+// 授权请求的 prompt 参数
+const (
+	PromptNone    = "none"
+	PromptLogin   = "login"
+	PromptConsent = "consent"
+)
+
+// AuthorizeRequest 授权请求参数
+type AuthorizeRequest struct {
+	ClientID            string
+	RedirectURI         string
+	ResponseType        string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Prompt              string
+}
+
+// Authorization 校验通过的授权请求
+type Authorization struct {
+	Client        *oauthmodel.Client
+	RedirectURI   string
+	Scopes        []string
+	State         string
+	Nonce         string
+	CodeChallenge string
+	Prompt        string
+}
+
+// TokenRequest 令牌请求参数
+type TokenRequest struct {
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scope        string
+}
+
+// TokenResponse 令牌响应（RFC 6749 5.1）
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// idTokenClaims ID Token 声明
+type idTokenClaims struct {
+	Nonce   string `json:"nonce,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Email   string `json:"email,omitempty"`
+	Picture string `json:"picture,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Server 授权服务
+type Server struct {
+	clientRepo *repository.OAuthClientRepository
+	tokenRepo  *repository.OAuthTokenRepository
+	userRepo   *repository.UserRepository
+}
+
+// NewServer 创建授权服务
+func NewServer() *Server {
+	return &Server{
+		clientRepo: repository.NewOAuthClientRepository(),
+		tokenRepo:  repository.NewOAuthTokenRepository(),
+		userRepo:   repository.NewUserRepository(),
+	}
+}
+
+// WithContext 返回绑定 context 的服务实例
+func (s *Server) WithContext(ctx context.Context) *Server {
+	return &Server{
+		clientRepo: s.clientRepo.WithContext(ctx),
+		tokenRepo:  s.tokenRepo.WithContext(ctx),
+		userRepo:   s.userRepo.WithContext(ctx),
+	}
+}
+
+// ValidateAuthorize 校验授权请求
+// client_id 或 redirect_uri 无效时返回的 Authorization 为空，错误只能展示给用户；其他错误可以重定向回客户端
+func (s *Server) ValidateAuthorize(req AuthorizeRequest) (*Authorization, error) {
+	client, err := s.clientRepo.FindByID(req.ClientID)
+	if errors.Is(err, repository.ErrOAuthClientNotFound) {
+		return nil, newError(ErrCodeInvalidClient, "unknown client_id")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	redirectURI := req.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	}
+	if !client.AllowsRedirectURI(redirectURI) {
+		return nil, newError(ErrCodeInvalidRequest, "redirect_uri is not registered for this client")
+	}
+
+	auth := &Authorization{
+		Client:        client,
+		RedirectURI:   redirectURI,
+		State:         req.State,
+		Nonce:         req.Nonce,
+		CodeChallenge: req.CodeChallenge,
+		Prompt:        req.Prompt,
+	}
+	if req.ResponseType != "code" {
+		return auth, newError(ErrCodeUnsupportedResponseType, "only response_type=code is supported")
+	}
+	if !client.AllowsGrant(GrantAuthorizationCode) {
+		return auth, newError(ErrCodeUnauthorizedClient, "client is not allowed to use authorization_code")
+	}
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != "S256" {
+		return auth, newError(ErrCodeInvalidRequest, "PKCE with code_challenge_method=S256 is required")
+	}
+	if !slices.Contains([]string{"", PromptNone, PromptLogin, PromptConsent}, req.Prompt) {
+		return auth, newError(ErrCodeInvalidRequest, "unsupported prompt")
+	}
+
+	scopes := strings.Fields(req.Scope)
+	if len(scopes) == 0 {
+		return auth, newError(ErrCodeInvalidScope, "scope is required")
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(client.Scopes, scope) {
+			return auth, newError(ErrCodeInvalidScope, fmt.Sprintf("scope %q is not allowed for this client", scope))
+		}
+	}
+	auth.Scopes = normalizeList(scopes)
+	return auth, nil
+}
+
+// IssueCode 用户同意授权后签发授权码
+func (s *Server) IssueCode(auth *Authorization, userID string) (string, error) {
+	opts, err := currentOptions()
+	if err != nil {
+		return "", err
+	}
+	code, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := clock.Now()
+	if err := s.tokenRepo.CreateCode(&oauthmodel.AuthorizationCode{
+		CodeHash:      hashToken(code),
+		ClientID:      auth.Client.ID,
+		UserID:        userID,
+		RedirectURI:   auth.RedirectURI,
+		Scopes:        auth.Scopes,
+		Nonce:         auth.Nonce,
+		CodeChallenge: auth.CodeChallenge,
+		FamilyID:      uuid.New().String(),
+		ExpiresAt:     now.Add(opts.CodeTTL),
+		CreatedAt:     now,
+	}); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// CreateSession 用户在授权页面登录后签发登录会话 token，返回 token 和有效期
+// 会话 token 只能用于授权页面，不能访问 /api 接口
+func (s *Server) CreateSession(userID string) (string, time.Duration, error) {
+	opts, err := currentOptions()
+	if err != nil {
+		return "", 0, err
+	}
+	token, err := common.GenerateOAuthSessionToken(userID, opts.SessionTTL)
+	if err != nil {
+		return "", 0, err
+	}
+	return token, opts.SessionTTL, nil
+}
+
+// SessionUser 校验授权页面的登录会话，返回当前用户
+// 用户被禁用、必须修改密码或会话签发后 token 被撤销时会话失效
+func (s *Server) SessionUser(token string) (*usermodel.User, error) {
+	claims, err := common.GetJWTManager().ValidateOAuthSessionToken(token)
+	if err != nil {
+		return nil, err
+	}
+	userData, err := s.activeUser(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if userData.MustChangePassword {
+		return nil, errors.New("password change required")
+	}
+	if userData.TokensRevokedAt != nil && !claims.IssuedAt.After(*userData.TokensRevokedAt) {
+		return nil, errors.New("session revoked")
+	}
+	return userData, nil
+}
+
+// Token 令牌端点，校验客户端后按授权类型签发令牌
+func (s *Server) Token(req TokenRequest) (*TokenResponse, error) {
+	opts, err := currentOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.authenticateClient(req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(GrantTypes, req.GrantType) {
+		return nil, newError(ErrCodeUnsupportedGrantType, "unsupported grant_type")
+	}
+	if !client.AllowsGrant(req.GrantType) {
+		return nil, newError(ErrCodeUnauthorizedClient, "client is not allowed to use "+req.GrantType)
+	}
+
+	switch req.GrantType {
+	case GrantAuthorizationCode:
+		return s.exchangeCode(opts, client, req)
+	case GrantRefreshToken:
+		return s.refresh(opts, client, req)
+	default:
+		return s.clientCredentials(opts, client, req)
+	}
+}
+
+// authenticateClient 校验客户端身份，机密客户端必须提供正确的 client_secret，公开客户端不能提供
+func (s *Server) authenticateClient(clientID, secret string) (*oauthmodel.Client, error) {
+	if clientID == "" {
+		return nil, newError(ErrCodeInvalidClient, "client authentication required")
+	}
+	client, err := s.clientRepo.FindByID(clientID)
+	if errors.Is(err, repository.ErrOAuthClientNotFound) {
+		return nil, newError(ErrCodeInvalidClient, "client authentication failed")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if client.Public() {
+		if secret != "" {
+			return nil, newError(ErrCodeInvalidClient, "public clients must not send client_secret")
+		}
+		return client, nil
+	}
+	if !verifySecret(client, secret) {
+		return nil, newError(ErrCodeInvalidClient, "client authentication failed")
+	}
+	return client, nil
+}
+
+// exchangeCode 授权码模式：校验授权码和 PKCE verifier
+// 授权码被重复使用时吊销用它签发的所有刷新令牌（RFC 6749 4.1.2）
+func (s *Server) exchangeCode(opts *Options, client *oauthmodel.Client, req TokenRequest) (*TokenResponse, error) {
+	if req.Code == "" || req.CodeVerifier == "" {
+		return nil, newError(ErrCodeInvalidRequest, "code and code_verifier are required")
+	}
+
+	now := clock.Now()
+	code, err := s.tokenRepo.ConsumeCode(hashToken(req.Code), now)
+	if errors.Is(err, repository.ErrAuthorizationCodeNotFound) {
+		return nil, newError(ErrCodeInvalidGrant, "invalid code")
+	}
+	if errors.Is(err, repository.ErrAuthorizationCodeUsed) {
+		// 授权码可能已泄露，吊销用它签发的令牌
+		if err := s.tokenRepo.RevokeFamily(code.FamilyID, now); err != nil {
+			return nil, err
+		}
+		return nil, newError(ErrCodeInvalidGrant, "code has already been used")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !now.Before(code.ExpiresAt) {
+		return nil, newError(ErrCodeInvalidGrant, "code expired")
+	}
+	if code.ClientID != client.ID {
+		return nil, newError(ErrCodeInvalidGrant, "code was issued to another client")
+	}
+	if req.RedirectURI != code.RedirectURI {
+		return nil, newError(ErrCodeInvalidGrant, "redirect_uri does not match the authorization request")
+	}
+	if subtle.ConstantTimeCompare([]byte(codeChallenge(req.CodeVerifier)), []byte(code.CodeChallenge)) != 1 {
+		return nil, newError(ErrCodeInvalidGrant, "code_verifier does not match code_challenge")
+	}
+
+	userData, err := s.activeUser(code.UserID)
+	if err != nil {
+		return nil, newError(ErrCodeInvalidGrant, "user is no longer active")
+	}
+	return s.issueTokens(opts, client, userData, code.Scopes, code.Nonce, code.FamilyID)
+}
+
+// refresh 刷新令牌模式：吊销旧令牌并签发新令牌，已吊销的令牌再次使用时吊销整个 family
+func (s *Server) refresh(opts *Options, client *oauthmodel.Client, req TokenRequest) (*TokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, newError(ErrCodeInvalidRequest, "refresh_token is required")
+	}
+
+	token, err := s.tokenRepo.FindRefreshToken(hashToken(req.RefreshToken))
+	if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+		return nil, newError(ErrCodeInvalidGrant, "invalid refresh_token")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if token.ClientID != client.ID {
+		return nil, newError(ErrCodeInvalidGrant, "refresh_token was issued to another client")
+	}
+
+	now := clock.Now()
+	if token.RevokedAt == nil {
+		err = s.tokenRepo.RevokeRefreshToken(token.ID, now)
+	} else {
+		err = repository.ErrRefreshTokenRevoked
+	}
+	if errors.Is(err, repository.ErrRefreshTokenRevoked) {
+		// 令牌可能已泄露，吊销同一次授权的所有令牌
+		if err := s.tokenRepo.RevokeFamily(token.FamilyID, now); err != nil {
+			return nil, err
+		}
+		return nil, newError(ErrCodeInvalidGrant, "refresh_token has been revoked")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !now.Before(token.ExpiresAt) {
+		return nil, newError(ErrCodeInvalidGrant, "refresh_token expired")
+	}
+
+	// 可以申请原授权范围的子集
+	scopes := []string(token.Scopes)
+	if req.Scope != "" {
+		scopes = normalizeList(strings.Fields(req.Scope))
+		for _, scope := range scopes {
+			if !slices.Contains(token.Scopes, scope) {
+				return nil, newError(ErrCodeInvalidScope, fmt.Sprintf("scope %q exceeds the original grant", scope))
+			}
+		}
+	}
+
+	userData, err := s.activeUser(token.UserID)
+	if err != nil {
+		return nil, newError(ErrCodeInvalidGrant, "user is no longer active")
+	}
+	return s.issueTokens(opts, client, userData, scopes, "", token.FamilyID)
+}
+
+// clientCredentials 客户端凭证模式：签发不代表任何用户的访问令牌，不能申请需要用户的 scope
+func (s *Server) clientCredentials(opts *Options, client *oauthmodel.Client, req TokenRequest) (*TokenResponse, error) {
+	var scopes []string
+	if req.Scope == "" {
+		for _, scope := range client.Scopes {
+			if !requiresUser(scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	} else {
+		scopes = normalizeList(strings.Fields(req.Scope))
+		for _, scope := range scopes {
+			if requiresUser(scope) || !slices.Contains(client.Scopes, scope) {
+				return nil, newError(ErrCodeInvalidScope, fmt.Sprintf("scope %q is not allowed for client_credentials", scope))
+			}
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, newError(ErrCodeInvalidScope, "no scope available for client_credentials")
+	}
+
+	accessToken, err := common.GenerateAccessToken("", client.ID, scopes, opts.AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(opts.AccessTokenTTL / time.Second),
+		Scope:       strings.Join(scopes, " "),
+	}, nil
+}
+
+// issueTokens 为用户签发访问令牌，客户端允许刷新时签发刷新令牌，申请了 openid 时签发 ID Token
+func (s *Server) issueTokens(opts *Options, client *oauthmodel.Client, userData *usermodel.User, scopes []string, nonce, familyID string) (*TokenResponse, error) {
+	accessToken, err := common.GenerateAccessToken(userData.ID, client.ID, scopes, opts.AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	resp := &TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(opts.AccessTokenTTL / time.Second),
+		Scope:       strings.Join(scopes, " "),
+	}
+
+	now := clock.Now()
+	if client.AllowsGrant(GrantRefreshToken) {
+		refreshToken, err := randomToken()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.tokenRepo.CreateRefreshToken(&oauthmodel.RefreshToken{
+			ID:        uuid.New().String(),
+			TokenHash: hashToken(refreshToken),
+			FamilyID:  familyID,
+			ClientID:  client.ID,
+			UserID:    userData.ID,
+			Scopes:    scopes,
+			ExpiresAt: now.Add(opts.RefreshTokenTTL),
+			CreatedAt: now,
+		}); err != nil {
+			return nil, err
+		}
+		resp.RefreshToken = refreshToken
+	}
+
+	if slices.Contains(scopes, ScopeOpenID) {
+		claims := idTokenClaims{
+			Nonce: nonce,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    opts.Issuer,
+				Subject:   userData.ID,
+				Audience:  jwt.ClaimStrings{client.ID},
+				ExpiresAt: jwt.NewNumericDate(now.Add(opts.IDTokenTTL)),
+				IssuedAt:  jwt.NewNumericDate(now),
+			},
+		}
+		if slices.Contains(scopes, ScopeProfile) {
+			claims.Name, claims.Picture = userData.Name, userData.Avatar
+		}
+		if slices.Contains(scopes, ScopeEmail) {
+			claims.Email = userData.Email
+		}
+		if resp.IDToken, err = opts.SigningKey.Sign(claims); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// UserInfo 返回访问令牌对应用户的声明（OIDC UserInfo），按授权的 scope 返回 profile 和 email 信息
+func (s *Server) UserInfo(accessToken string) (map[string]any, error) {
+	claims, err := common.GetJWTManager().ValidateToken(accessToken)
+	if err != nil || !claims.IsOAuth() || claims.UserID == "" {
+		return nil, newError(ErrCodeInvalidToken, "invalid access token")
+	}
+	scopes := claims.Scopes()
+	if !slices.Contains(scopes, ScopeOpenID) {
+		return nil, newError(ErrCodeInsufficientScope, "openid scope required")
+	}
+
+	userData, err := s.activeUser(claims.UserID)
+	if err != nil {
+		return nil, newError(ErrCodeInvalidToken, "user is no longer active")
+	}
+
+	info := map[string]any{"sub": userData.ID}
+	if slices.Contains(scopes, ScopeProfile) {
+		info["name"] = userData.Name
+		info["picture"] = userData.Avatar
+		info["updated_at"] = userData.UpdatedAt.Unix()
+	}
+	if slices.Contains(scopes, ScopeEmail) {
+		info["email"] = userData.Email
+	}
+	return info, nil
+}
+
+// activeUser 获取未被删除和禁用的用户
+func (s *Server) activeUser(userID string) (*usermodel.User, error) {
+	userData, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if userData.Status != int(usermodel.UserStatusNormal) {
+		return nil, errors.New("user disabled or not verified")
+	}
+	return userData, nil
+}
+
+// DeleteExpired 删除过期的授权码和刷新令牌
+func DeleteExpired(ctx context.Context) (int64, error) {
+	return repository.NewOAuthTokenRepository().WithContext(ctx).DeleteExpired(clock.Now())
+}
+
+// codeChallenge 计算 PKCE S256 code_challenge
+func codeChallenge(verifier string) string {
+	return oidc.CodeChallenge(verifier)
+}
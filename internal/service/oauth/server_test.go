@@ -0,0 +1,357 @@
+package oauth_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gosir/internal/clock"
+	"gosir/internal/oidc"
+	"gosir/internal/service/apikey"
+	"gosir/internal/service/oauth"
+	"gosir/internal/service/user"
+	"gosir/internal/testutil"
+)
+
+const (
+	testRedirectURI = "https://app.example.com/callback"
+	testVerifier    = "test-verifier-0123456789-0123456789-0123456789"
+)
+
+// testClient 测试用的客户端和密钥（公开客户端密钥为空）
+type testClient struct {
+	id     string
+	secret string
+}
+
+// setupServer 初始化数据库和授权服务，创建一个用户，返回授权服务和用户 ID
+func setupServer(t *testing.T) (*oauth.Server, string) {
+	t.Helper()
+	testutil.SetupOAuth(t, "https://auth.example.com")
+
+	u, err := user.NewUserService().CreateUser(&user.CreateUserRequest{
+		Name:     "alice",
+		Email:    "alice@example.com",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("CreateUser() error: %v", err)
+	}
+	return oauth.NewServer(), u.ID
+}
+
+// createClient 创建允许授权码和刷新令牌模式的客户端
+func createClient(t *testing.T, public bool, redirectURIs ...string) testClient {
+	t.Helper()
+	if len(redirectURIs) == 0 {
+		redirectURIs = []string{testRedirectURI}
+	}
+	client, secret, err := oauth.NewClientService().Create(oauth.ClientRequest{
+		Name:         "test",
+		RedirectURIs: redirectURIs,
+		GrantTypes:   []string{oauth.GrantAuthorizationCode, oauth.GrantRefreshToken},
+		Scopes:       []string{oauth.ScopeOpenID, oauth.ScopeEmail, apikey.ScopeUsersRead},
+	}, public)
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	return testClient{id: client.ID, secret: secret}
+}
+
+// issueCode 校验授权请求并以 userID 的身份同意授权，PKCE verifier 为 testVerifier
+func issueCode(t *testing.T, s *oauth.Server, client testClient, userID, scope string) string {
+	t.Helper()
+	auth, err := s.ValidateAuthorize(oauth.AuthorizeRequest{
+		ClientID:            client.id,
+		RedirectURI:         testRedirectURI,
+		ResponseType:        "code",
+		Scope:               scope,
+		CodeChallenge:       oidc.CodeChallenge(testVerifier),
+		CodeChallengeMethod: "S256",
+	})
+	if err != nil {
+		t.Fatalf("ValidateAuthorize() error: %v", err)
+	}
+	code, err := s.IssueCode(auth, userID)
+	if err != nil {
+		t.Fatalf("IssueCode() error: %v", err)
+	}
+	return code
+}
+
+// codeRequest 使用授权码换取令牌的请求
+func codeRequest(client testClient, code string) oauth.TokenRequest {
+	return oauth.TokenRequest{
+		GrantType:    oauth.GrantAuthorizationCode,
+		ClientID:     client.id,
+		ClientSecret: client.secret,
+		Code:         code,
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: testVerifier,
+	}
+}
+
+// refreshRequest 使用刷新令牌换取令牌的请求
+func refreshRequest(client testClient, refreshToken string) oauth.TokenRequest {
+	return oauth.TokenRequest{
+		GrantType:    oauth.GrantRefreshToken,
+		ClientID:     client.id,
+		ClientSecret: client.secret,
+		RefreshToken: refreshToken,
+	}
+}
+
+// exchange 换取令牌，失败时终止测试
+func exchange(t *testing.T, s *oauth.Server, req oauth.TokenRequest) *oauth.TokenResponse {
+	t.Helper()
+	resp, err := s.Token(req)
+	if err != nil {
+		t.Fatalf("Token(%s) error: %v", req.GrantType, err)
+	}
+	return resp
+}
+
+// assertOAuthError 断言返回了指定错误码的 OAuth 协议错误
+func assertOAuthError(t *testing.T, err error, code string) {
+	t.Helper()
+	var oauthErr *oauth.Error
+	if !errors.As(err, &oauthErr) {
+		t.Fatalf("error = %v, want OAuth error %s", err, code)
+	}
+	if oauthErr.Code != code {
+		t.Errorf("error code = %s (%s), want %s", oauthErr.Code, oauthErr.Description, code)
+	}
+}
+
+func TestValidateAuthorizeRedirectURI(t *testing.T) {
+	s, _ := setupServer(t)
+	single := createClient(t, true)
+	multiple := createClient(t, true, testRedirectURI, "https://app.example.com/other")
+
+	tests := []struct {
+		name        string
+		client      testClient
+		redirectURI string
+		want        string // 为空表示拒绝
+	}{
+		{name: "完全一致", client: single, redirectURI: testRedirectURI, want: testRedirectURI},
+		{name: "只登记了一个时可省略", client: single, want: testRedirectURI},
+		{name: "登记了多个时不能省略", client: multiple},
+		{name: "末尾多了斜杠", client: single, redirectURI: testRedirectURI + "/"},
+		{name: "多了查询参数", client: single, redirectURI: testRedirectURI + "?next=/admin"},
+		{name: "子路径", client: single, redirectURI: testRedirectURI + "/evil"},
+		{name: "主机大小写不同", client: single, redirectURI: "https://APP.example.com/callback"},
+		{name: "协议不同", client: single, redirectURI: "http://app.example.com/callback"},
+		{name: "其他主机", client: single, redirectURI: "https://evil.example.com/callback"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, err := s.ValidateAuthorize(oauth.AuthorizeRequest{
+				ClientID:            tt.client.id,
+				RedirectURI:         tt.redirectURI,
+				ResponseType:        "code",
+				Scope:               oauth.ScopeOpenID,
+				CodeChallenge:       oidc.CodeChallenge(testVerifier),
+				CodeChallengeMethod: "S256",
+			})
+			if tt.want == "" {
+				// 回调地址无效时不能重定向回客户端
+				if auth != nil {
+					t.Errorf("ValidateAuthorize() returned authorization for redirect_uri %q", tt.redirectURI)
+				}
+				assertOAuthError(t, err, oauth.ErrCodeInvalidRequest)
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateAuthorize() error: %v", err)
+			}
+			if auth.RedirectURI != tt.want {
+				t.Errorf("RedirectURI = %q, want %q", auth.RedirectURI, tt.want)
+			}
+		})
+	}
+}
+
+func TestExchangeCode(t *testing.T) {
+	s, userID := setupServer(t)
+	client := createClient(t, true)
+
+	resp := exchange(t, s, codeRequest(client, issueCode(t, s, client, userID, "openid users:read")))
+	if resp.AccessToken == "" || resp.RefreshToken == "" || resp.IDToken == "" {
+		t.Fatalf("response = %+v, want access, refresh and ID tokens", resp)
+	}
+	if resp.Scope != "openid users:read" {
+		t.Errorf("scope = %q, want %q", resp.Scope, "openid users:read")
+	}
+}
+
+func TestExchangeCodeRejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(req *oauth.TokenRequest, other testClient, fake *clock.Fake)
+		want   string
+	}{
+		{
+			name:   "code_verifier 不匹配",
+			modify: func(req *oauth.TokenRequest, _ testClient, _ *clock.Fake) { req.CodeVerifier = "wrong-verifier" },
+			want:   oauth.ErrCodeInvalidGrant,
+		},
+		{
+			name:   "缺少 code_verifier",
+			modify: func(req *oauth.TokenRequest, _ testClient, _ *clock.Fake) { req.CodeVerifier = "" },
+			want:   oauth.ErrCodeInvalidRequest,
+		},
+		{
+			name: "redirect_uri 与授权请求不一致",
+			modify: func(req *oauth.TokenRequest, _ testClient, _ *clock.Fake) {
+				req.RedirectURI = "https://app.example.com/other"
+			},
+			want: oauth.ErrCodeInvalidGrant,
+		},
+		{
+			name:   "redirect_uri 末尾多了斜杠",
+			modify: func(req *oauth.TokenRequest, _ testClient, _ *clock.Fake) { req.RedirectURI += "/" },
+			want:   oauth.ErrCodeInvalidGrant,
+		},
+		{
+			name: "授权码签发给其他客户端",
+			modify: func(req *oauth.TokenRequest, other testClient, _ *clock.Fake) {
+				req.ClientID, req.ClientSecret = other.id, other.secret
+			},
+			want: oauth.ErrCodeInvalidGrant,
+		},
+		{
+			name:   "授权码已过期",
+			modify: func(_ *oauth.TokenRequest, _ testClient, fake *clock.Fake) { fake.Advance(2 * time.Minute) },
+			want:   oauth.ErrCodeInvalidGrant,
+		},
+		{
+			name:   "未知的授权码",
+			modify: func(req *oauth.TokenRequest, _ testClient, _ *clock.Fake) { req.Code = "unknown" },
+			want:   oauth.ErrCodeInvalidGrant,
+		},
+		{
+			name:   "公开客户端提供了 client_secret",
+			modify: func(req *oauth.TokenRequest, _ testClient, _ *clock.Fake) { req.ClientSecret = "secret" },
+			want:   oauth.ErrCodeInvalidClient,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, userID := setupServer(t)
+			fake := clock.NewRunningFake(time.Now())
+			clock.Set(fake)
+			t.Cleanup(clock.Reset)
+
+			client := createClient(t, true, testRedirectURI, "https://app.example.com/other")
+			other := createClient(t, true, testRedirectURI, "https://app.example.com/other")
+			req := codeRequest(client, issueCode(t, s, client, userID, oauth.ScopeOpenID))
+			tt.modify(&req, other, fake)
+
+			_, err := s.Token(req)
+			assertOAuthError(t, err, tt.want)
+		})
+	}
+}
+
+func TestCodeReplayRevokesFamily(t *testing.T) {
+	s, userID := setupServer(t)
+	client := createClient(t, false)
+	req := codeRequest(client, issueCode(t, s, client, userID, oauth.ScopeOpenID))
+
+	first := exchange(t, s, req)
+	rotated := exchange(t, s, refreshRequest(client, first.RefreshToken))
+
+	// 重复使用授权码被拒绝，并吊销用它签发的所有刷新令牌（包括轮换后的令牌）
+	_, err := s.Token(req)
+	assertOAuthError(t, err, oauth.ErrCodeInvalidGrant)
+	_, err = s.Token(refreshRequest(client, rotated.RefreshToken))
+	assertOAuthError(t, err, oauth.ErrCodeInvalidGrant)
+
+	// 同一用户的其他授权不受影响
+	other := exchange(t, s, codeRequest(client, issueCode(t, s, client, userID, oauth.ScopeOpenID)))
+	exchange(t, s, refreshRequest(client, other.RefreshToken))
+}
+
+func TestRefreshRotation(t *testing.T) {
+	s, userID := setupServer(t)
+	client := createClient(t, false)
+	initial := exchange(t, s, codeRequest(client, issueCode(t, s, client, userID, "email openid users:read")))
+
+	rotated := exchange(t, s, refreshRequest(client, initial.RefreshToken))
+	if rotated.RefreshToken == "" || rotated.RefreshToken == initial.RefreshToken {
+		t.Fatalf("refresh_token was not rotated: %q", rotated.RefreshToken)
+	}
+	if rotated.Scope != initial.Scope {
+		t.Errorf("scope = %q, want %q", rotated.Scope, initial.Scope)
+	}
+
+	// 可以申请原授权范围的子集，不能扩大
+	narrowReq := refreshRequest(client, rotated.RefreshToken)
+	narrowReq.Scope = apikey.ScopeUsersRead
+	narrowed := exchange(t, s, narrowReq)
+	if narrowed.Scope != apikey.ScopeUsersRead || narrowed.IDToken != "" {
+		t.Errorf("narrowed response scope = %q, id_token = %t", narrowed.Scope, narrowed.IDToken != "")
+	}
+	widenReq := refreshRequest(client, narrowed.RefreshToken)
+	widenReq.Scope = apikey.ScopeUsersWrite
+	_, err := s.Token(widenReq)
+	assertOAuthError(t, err, oauth.ErrCodeInvalidScope)
+
+	// 其他客户端不能使用该刷新令牌
+	other := createClient(t, false)
+	_, err = s.Token(refreshRequest(other, narrowed.RefreshToken))
+	assertOAuthError(t, err, oauth.ErrCodeInvalidGrant)
+
+	// 已轮换的令牌再次使用视为泄露，同一次授权的所有令牌被吊销
+	_, err = s.Token(refreshRequest(client, initial.RefreshToken))
+	assertOAuthError(t, err, oauth.ErrCodeInvalidGrant)
+	_, err = s.Token(refreshRequest(client, narrowed.RefreshToken))
+	assertOAuthError(t, err, oauth.ErrCodeInvalidGrant)
+}
+
+func TestClientCredentialsRejectsUserScopes(t *testing.T) {
+	s, _ := setupServer(t)
+	client, secret, err := oauth.NewClientService().Create(oauth.ClientRequest{
+		Name:       "service",
+		GrantTypes: []string{oauth.GrantClientCredentials},
+		Scopes:     []string{oauth.ScopeOpenID, apikey.ScopeUsersRead, apikey.ScopeAdmin},
+	}, false)
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		scope string
+	}{
+		{name: "身份 scope", scope: oauth.ScopeOpenID},
+		{name: "接口权限范围", scope: apikey.ScopeUsersRead},
+		{name: "管理员权限范围", scope: apikey.ScopeAdmin},
+		{name: "未登记的 scope", scope: apikey.ScopeUsersWrite},
+		{name: "默认 scope 全部需要用户", scope: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Token(oauth.TokenRequest{
+				GrantType:    oauth.GrantClientCredentials,
+				ClientID:     client.ID,
+				ClientSecret: secret,
+				Scope:        tt.scope,
+			})
+			assertOAuthError(t, err, oauth.ErrCodeInvalidScope)
+		})
+	}
+
+	// 不允许客户端凭证模式的客户端
+	userClient := createClient(t, false)
+	_, err = s.Token(oauth.TokenRequest{
+		GrantType:    oauth.GrantClientCredentials,
+		ClientID:     userClient.id,
+		ClientSecret: userClient.secret,
+	})
+	assertOAuthError(t, err, oauth.ErrCodeUnauthorizedClient)
+}
@@ -0,0 +1,129 @@
+package oauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey ID Token 签名密钥，公钥通过 JWKS 发布
+type SigningKey struct {
+	key    crypto.Signer
+	method jwt.SigningMethod
+	kid    string
+}
+
+// LoadSigningKey 从 PEM 文件加载签名私钥（PKCS#1、PKCS#8 或 SEC 1，RSA 至少 2048 位，EC 支持 P-256/P-384/P-521）
+func LoadSigningKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("signing key: no PEM block found")
+	}
+
+	var key any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse signing key: %w", err)
+	}
+	return newSigningKey(key)
+}
+
+// GenerateSigningKey 生成临时 RSA 签名密钥（重启后失效，仅用于开发环境）
+func GenerateSigningKey() (*SigningKey, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return newSigningKey(key)
+}
+
+// newSigningKey 根据私钥类型选择签名算法并计算 kid
+func newSigningKey(key any) (*SigningKey, error) {
+	k := &SigningKey{}
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("signing key: RSA key must be at least 2048 bits, got %d", key.N.BitLen())
+		}
+		k.key, k.method = key, jwt.SigningMethodRS256
+	case *ecdsa.PrivateKey:
+		switch key.Curve {
+		case elliptic.P256():
+			k.method = jwt.SigningMethodES256
+		case elliptic.P384():
+			k.method = jwt.SigningMethodES384
+		case elliptic.P521():
+			k.method = jwt.SigningMethodES512
+		default:
+			return nil, errors.New("signing key: unsupported EC curve")
+		}
+		k.key = key
+	default:
+		return nil, fmt.Errorf("signing key: unsupported key type %T", key)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(k.key.Public())
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+	k.kid = base64.RawURLEncoding.EncodeToString(sum[:12])
+	return k, nil
+}
+
+// Algorithm 签名算法
+func (k *SigningKey) Algorithm() string {
+	return k.method.Alg()
+}
+
+// Sign 签发 JWT
+func (k *SigningKey) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["kid"] = k.kid
+	return token.SignedString(k.key)
+}
+
+// JWK 公钥的 JSON Web Key
+func (k *SigningKey) JWK() map[string]string {
+	jwk := map[string]string{
+		"kid": k.kid,
+		"use": "sig",
+		"alg": k.method.Alg(),
+	}
+	switch pub := k.key.Public().(type) {
+	case *rsa.PublicKey:
+		jwk["kty"] = "RSA"
+		jwk["n"] = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk["e"] = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk["kty"] = "EC"
+		jwk["crv"] = pub.Curve.Params().Name
+		jwk["x"] = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size)))
+		jwk["y"] = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size)))
+	}
+	return jwk
+}
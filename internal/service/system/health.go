@@ -0,0 +1,95 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"gosir/internal/common"
+	"gosir/internal/cron"
+	"gosir/internal/database"
+	"gosir/internal/health"
+	"gosir/internal/repository"
+)
+
+// RegisterHealthChecks 注册就绪检查项
+func RegisterHealthChecks(migrationsFolder string) {
+	health.Register("database", checkDatabase)
+	health.Register("migrations", func(ctx context.Context) error {
+		return checkMigrations(ctx, migrationsFolder)
+	})
+	health.Register("cron", checkCron)
+	health.Register("jwt", checkJWT)
+}
+
+// checkDatabase 检查数据库连接
+func checkDatabase(ctx context.Context) error {
+	if database.DB == nil {
+		return errors.New("database not initialized")
+	}
+	sqlDB, err := database.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// checkMigrations 检查迁移目录中的 SQL 脚本是否都已执行
+func checkMigrations(ctx context.Context, folderPath string) error {
+	if database.DB == nil {
+		return errors.New("database not initialized")
+	}
+
+	var versions []string
+	err := filepath.WalkDir(folderPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".sql") {
+			versions = append(versions, filepath.Base(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	migrationRepo := repository.NewMigrationRepository()
+	var pending []string
+	for _, version := range versions {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		executed, err := migrationRepo.IsExecuted(version)
+		if err != nil {
+			return err
+		}
+		if !executed {
+			pending = append(pending, version)
+		}
+	}
+
+	if len(pending) > 0 {
+		return fmt.Errorf("pending migrations: %s", strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// checkCron 检查定时任务调度器是否在运行
+func checkCron(ctx context.Context) error {
+	if !cron.IsRunning() {
+		return errors.New("cron scheduler is not running")
+	}
+	return nil
+}
+
+// checkJWT 检查 JWT 管理器是否已初始化
+func checkJWT(ctx context.Context) error {
+	if common.GetJWTManager() == nil {
+		return errors.New("JWT manager not initialized")
+	}
+	return nil
+}
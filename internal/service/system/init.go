@@ -1,29 +1,154 @@
 package system
 
 import (
-	"gosir/internal/database"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+
+	"gosir/internal/logger"
 	usermodel "gosir/internal/model/user"
+	"gosir/internal/repository"
 	"gosir/internal/service/user"
 )
 
+const (
+	// LegacyDefaultAdminPassword 早期版本硬编码的管理员默认密码，release 模式下禁止继续使用
+	LegacyDefaultAdminPassword = "admin123"
+
+	// generatedAdminPasswordLength 自动生成的管理员密码长度
+	generatedAdminPasswordLength = 20
+)
+
+// ErrDefaultAdminPassword 管理员仍在使用已知的默认密码
+var ErrDefaultAdminPassword = errors.New("admin account is still using the well-known default password")
+
+// AdminOptions 初始管理员账号选项
+type AdminOptions struct {
+	Name     string
+	Email    string
+	Phone    string
+	Password string // 为空时自动生成随机密码
+}
+
+// AdminInitResult 管理员初始化结果
+type AdminInitResult struct {
+	Created           bool   // 是否新建了管理员账号
+	Email             string // 管理员邮箱
+	GeneratedPassword string // 自动生成的密码（仅新建且未配置密码时有值，只应展示一次）
+}
+
 // InitAdminUser 初始化管理员账号（如果不存在）
-func InitAdminUser() error {
-	var count int64
-	database.DB.Model(&usermodel.User{}).Where("email = ?", "admin@gosir.com").Count(&count)
+// 新建的管理员账号首次登录后必须修改密码
+// 管理员身份保存在 is_admin 标记中：已有账号只在还没有任何管理员时（从按邮箱判断管理员的版本升级）标记为管理员，
+// 避免其他用户把邮箱改为 admin.email 后在重启时获得管理员权限
+func InitAdminUser(opts AdminOptions) (*AdminInitResult, error) {
+	if opts.Email == "" {
+		return nil, errors.New("admin email is required")
+	}
+	if opts.Name == "" {
+		opts.Name = "管理员"
+	}
 
-	if count > 0 {
-		// 管理员已存在
-		return nil
+	result := &AdminInitResult{Email: opts.Email}
+
+	userRepo := repository.NewUserRepository()
+	existing, err := userRepo.FindByEmail(opts.Email)
+	if err == nil {
+		if !existing.IsAdmin {
+			if err := flagExistingAdmin(userRepo, existing); err != nil {
+				return nil, err
+			}
+		}
+		// 管理员已存在：旧版本创建的账号若仍使用默认密码，强制其下次登录时修改
+		if !existing.MustChangePassword && isLegacyDefaultPassword(existing) {
+			logger.Warn("Admin account is using the default password, forcing password change on next login",
+				zap.String("email", opts.Email),
+			)
+			existing.MustChangePassword = true
+			if _, err := userRepo.Update(existing); err != nil {
+				return nil, fmt.Errorf("failed to flag admin password change: %w", err)
+			}
+		}
+		return result, nil
+	}
+	var notFound *repository.UserNotFoundError
+	if !errors.As(err, &notFound) {
+		return nil, err
+	}
+
+	password := opts.Password
+	if password == "" {
+		password, err = user.GenerateRandomPassword(generatedAdminPasswordLength)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate admin password: %w", err)
+		}
+		result.GeneratedPassword = password
 	}
 
 	userService := user.NewUserService()
 	createReq := &user.CreateUserRequest{
-		Name:     "管理员",
-		Email:    "admin@gosir.com",
-		Password: "admin123",
-		Phone:    "15578007781",
-		Status:   nil, // 使用默认状态
-	}
-	_, err := userService.CreateUser(createReq)
-	return err
+		Name:               opts.Name,
+		Email:              opts.Email,
+		Password:           password,
+		Phone:              opts.Phone,
+		Status:             nil, // 使用默认状态
+		MustChangePassword: true,
+		IsAdmin:            true,
+	}
+	if _, err := userService.CreateUser(createReq); err != nil {
+		return nil, err
+	}
+
+	result.Created = true
+	logger.Info("Admin account created",
+		zap.String("email", opts.Email),
+		zap.Bool("generated_password", result.GeneratedPassword != ""),
+	)
+	return result, nil
+}
+
+// flagExistingAdmin 将 admin.email 对应的已有账号标记为管理员，已存在管理员时不修改
+func flagExistingAdmin(userRepo *repository.UserRepository, existing *usermodel.User) error {
+	hasAdmin, err := userRepo.HasAdmin()
+	if err != nil {
+		return fmt.Errorf("failed to check admin accounts: %w", err)
+	}
+	if hasAdmin {
+		logger.Warn("Account with the configured admin email is not an admin, leaving it unchanged",
+			zap.String("email", existing.Email),
+		)
+		return nil
+	}
+
+	existing.IsAdmin = true
+	if _, err := userRepo.Update(existing); err != nil {
+		return fmt.Errorf("failed to flag admin account: %w", err)
+	}
+	logger.Info("Existing account flagged as admin", zap.String("email", existing.Email))
+	return nil
+}
+
+// CheckDefaultAdminPassword 检查管理员是否仍在使用已知的默认密码
+// release 模式启动时调用，命中时拒绝启动
+func CheckDefaultAdminPassword(email string) error {
+	existing, err := repository.NewUserRepository().FindByEmail(email)
+	if err != nil {
+		var notFound *repository.UserNotFoundError
+		if errors.As(err, &notFound) {
+			return nil
+		}
+		return err
+	}
+
+	if isLegacyDefaultPassword(existing) {
+		return ErrDefaultAdminPassword
+	}
+	return nil
+}
+
+// isLegacyDefaultPassword 检查用户密码是否为早期版本的默认密码
+func isLegacyDefaultPassword(u *usermodel.User) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(LegacyDefaultAdminPassword)) == nil
 }
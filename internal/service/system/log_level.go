@@ -0,0 +1,214 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"gosir/internal/database"
+	"gosir/internal/logger"
+)
+
+// 日志级别调整对象
+const (
+	LogLevelTargetRoot = "root" // 全局日志级别
+	LogLevelTargetGORM = "gorm" // GORM SQL 日志级别
+)
+
+// LogLevelInherit 模块级别取该值时恢复跟随全局级别
+const LogLevelInherit = "inherit"
+
+// ErrInvalidLogLevel 日志级别或模块名称无效
+var ErrInvalidLogLevel = errors.New("invalid log level")
+
+// LogLevelStatus 单个对象的日志级别
+type LogLevelStatus struct {
+	Level     string     `json:"level" example:"info"`                               // 当前生效的级别
+	Inherited bool       `json:"inherited,omitempty" example:"false"`                // 模块是否跟随全局级别
+	RevertAt  *time.Time `json:"revert_at,omitempty" example:"2026-01-08T10:00:00Z"` // 临时调整的恢复时间
+}
+
+// LogLevels 当前日志级别
+type LogLevels struct {
+	Root    LogLevelStatus            `json:"root"`    // 全局级别
+	Modules map[string]LogLevelStatus `json:"modules"` // 模块级别（http, sql, cron, auth, notify）
+	GORM    LogLevelStatus            `json:"gorm"`    // GORM SQL 日志级别（silent, error, warn, info）
+}
+
+// LogLevelChange 日志级别调整（为空的字段不修改）
+type LogLevelChange struct {
+	Root    string            // debug, info, warn, error
+	Modules map[string]string // 模块名 -> 级别，inherit 表示跟随全局级别
+	GORM    string            // silent, error, warn, info
+	TTL     time.Duration     // 大于 0 时到期自动恢复为调整前的级别
+}
+
+// pendingRevert 待恢复的临时调整
+type pendingRevert struct {
+	timer    *time.Timer
+	revertAt time.Time
+	restore  func()
+}
+
+// levelReverts 各对象的待恢复调整，key 为 root、gorm 或 module:<name>
+var levelReverts = struct {
+	sync.Mutex
+	pending map[string]*pendingRevert
+}{pending: make(map[string]*pendingRevert)}
+
+// GetLogLevels 获取当前日志级别
+func GetLogLevels() LogLevels {
+	levelReverts.Lock()
+	defer levelReverts.Unlock()
+
+	levels := LogLevels{
+		Root:    LogLevelStatus{Level: logger.GetLevel().String(), RevertAt: revertAt(LogLevelTargetRoot)},
+		Modules: make(map[string]LogLevelStatus),
+		GORM:    LogLevelStatus{Level: database.LogLevel(), RevertAt: revertAt(LogLevelTargetGORM)},
+	}
+	for _, name := range logger.ModuleNames() {
+		level, inherited := logger.ModuleLevel(name)
+		levels.Modules[name] = LogLevelStatus{
+			Level:     level.String(),
+			Inherited: inherited,
+			RevertAt:  revertAt(moduleTarget(name)),
+		}
+	}
+	return levels
+}
+
+// SetLogLevels 运行时调整日志级别，先校验全部参数再统一生效
+func SetLogLevels(change LogLevelChange) (LogLevels, error) {
+	if change.Root == "" && change.GORM == "" && len(change.Modules) == 0 {
+		return LogLevels{}, fmt.Errorf("%w: nothing to change", ErrInvalidLogLevel)
+	}
+	if change.TTL < 0 {
+		return LogLevels{}, fmt.Errorf("%w: ttl must not be negative", ErrInvalidLogLevel)
+	}
+
+	// 校验
+	var rootLevel zapcore.Level
+	if change.Root != "" {
+		level, err := zapcore.ParseLevel(change.Root)
+		if err != nil {
+			return LogLevels{}, fmt.Errorf("%w: %q", ErrInvalidLogLevel, change.Root)
+		}
+		rootLevel = level
+	}
+	moduleLevels := make(map[string]*zapcore.Level, len(change.Modules))
+	knownModules := logger.ModuleNames()
+	for name, value := range change.Modules {
+		if !slices.Contains(knownModules, name) {
+			return LogLevels{}, fmt.Errorf("%w: unknown module %q", ErrInvalidLogLevel, name)
+		}
+		if value == LogLevelInherit {
+			moduleLevels[name] = nil
+			continue
+		}
+		level, err := zapcore.ParseLevel(value)
+		if err != nil {
+			return LogLevels{}, fmt.Errorf("%w: %q for module %s", ErrInvalidLogLevel, value, name)
+		}
+		moduleLevels[name] = &level
+	}
+	if change.GORM != "" && !slices.Contains([]string{"silent", "error", "warn", "info"}, change.GORM) {
+		return LogLevels{}, fmt.Errorf("%w: %q for gorm", ErrInvalidLogLevel, change.GORM)
+	}
+
+	// 生效
+	levelReverts.Lock()
+	if change.Root != "" {
+		previous := logger.GetLevel()
+		applyLevel(LogLevelTargetRoot, rootLevel.String(), change.TTL, func() {
+			logger.SetLevel(previous)
+		})
+		logger.SetLevel(rootLevel)
+	}
+	for name, level := range moduleLevels {
+		previous, inherited := logger.ModuleLevel(name)
+		value := LogLevelInherit
+		if level != nil {
+			value = level.String()
+		}
+		applyLevel(moduleTarget(name), value, change.TTL, func() {
+			if inherited {
+				_ = logger.SetModuleLevel(name, nil)
+			} else {
+				_ = logger.SetModuleLevel(name, &previous)
+			}
+		})
+		if err := logger.SetModuleLevel(name, level); err != nil {
+			levelReverts.Unlock()
+			return LogLevels{}, err
+		}
+	}
+	if change.GORM != "" {
+		previous := database.LogLevel()
+		applyLevel(LogLevelTargetGORM, change.GORM, change.TTL, func() {
+			_ = database.SetLogLevel(previous)
+		})
+		if err := database.SetLogLevel(change.GORM); err != nil {
+			levelReverts.Unlock()
+			return LogLevels{}, err
+		}
+	}
+	levelReverts.Unlock()
+
+	return GetLogLevels(), nil
+}
+
+// applyLevel 记录调整，ttl 大于 0 时安排到期恢复（调用方持有 levelReverts 锁）
+// 已有未到期的临时调整时沿用其恢复逻辑，保证最终恢复到第一次临时调整前的级别
+func applyLevel(target, level string, ttl time.Duration, restore func()) {
+	pending, ok := levelReverts.pending[target]
+	if ok {
+		pending.timer.Stop()
+		delete(levelReverts.pending, target)
+		restore = pending.restore
+	}
+
+	if ttl <= 0 {
+		logger.Warn("Log level changed", zap.String("target", target), zap.String("level", level))
+		return
+	}
+
+	revert := &pendingRevert{revertAt: time.Now().Add(ttl), restore: restore}
+	revert.timer = time.AfterFunc(ttl, func() {
+		levelReverts.Lock()
+		defer levelReverts.Unlock()
+
+		// 已被后续调整取代
+		if levelReverts.pending[target] != revert {
+			return
+		}
+		delete(levelReverts.pending, target)
+		revert.restore()
+		logger.Warn("Log level reverted", zap.String("target", target))
+	})
+	levelReverts.pending[target] = revert
+
+	logger.Warn("Log level changed",
+		zap.String("target", target),
+		zap.String("level", level),
+		zap.Duration("ttl", ttl),
+	)
+}
+
+// revertAt 获取临时调整的恢复时间（调用方持有 levelReverts 锁）
+func revertAt(target string) *time.Time {
+	if pending, ok := levelReverts.pending[target]; ok {
+		at := pending.revertAt
+		return &at
+	}
+	return nil
+}
+
+// moduleTarget 模块对应的调整对象名称
+func moduleTarget(name string) string {
+	return "module:" + name
+}
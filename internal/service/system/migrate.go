@@ -1,6 +1,8 @@
 package system
 
 import (
+	"fmt"
+
 	"go.uber.org/zap"
 
 	"gosir/internal/database"
@@ -23,3 +25,17 @@ func AutoMigrate() error {
 	logger.Info("AutoMigrate completed successfully")
 	return nil
 }
+
+// RunMigrations 依次执行 AutoMigrate 和 SQL 脚本
+// 多实例部署时应在 WithMigrationLock 中调用
+func RunMigrations(folderPath string) error {
+	if err := AutoMigrate(); err != nil {
+		return fmt.Errorf("failed to migrate database schema: %w", err)
+	}
+
+	if err := ExecuteSQLScripts(folderPath); err != nil {
+		return fmt.Errorf("failed to execute SQL scripts: %w", err)
+	}
+
+	return nil
+}
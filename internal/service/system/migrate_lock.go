@@ -0,0 +1,208 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"gosir/internal/database"
+	"gosir/internal/logger"
+	"gosir/internal/repository"
+)
+
+const (
+	// migrationLockName 迁移锁名称
+	migrationLockName = "schema_migrations"
+
+	// DefaultMigrationLockTimeout 等待迁移锁的默认超时时间
+	DefaultMigrationLockTimeout = 60 * time.Second
+
+	// DefaultMigrationLockTTL 迁移锁的默认有效期，持有者崩溃后超过该时间即可被回收
+	DefaultMigrationLockTTL = 5 * time.Minute
+
+	// migrationLockRetryInterval 获取锁失败后的重试间隔
+	migrationLockRetryInterval = 500 * time.Millisecond
+)
+
+// ErrMigrationLockTimeout 等待迁移锁超时
+var ErrMigrationLockTimeout = errors.New("timed out waiting for migration lock")
+
+// MigrationLockOptions 迁移锁选项
+type MigrationLockOptions struct {
+	Timeout time.Duration // 等待锁的超时时间
+	TTL     time.Duration // 锁的有效期
+}
+
+// MigrationLocker 迁移锁
+// SQLite 使用锁表实现；接入其他数据库驱动后可以使用原生的 advisory lock
+// （如 MySQL 的 GET_LOCK、PostgreSQL 的 pg_advisory_lock）
+type MigrationLocker interface {
+	// Acquire 获取锁，超时返回 ErrMigrationLockTimeout
+	Acquire() error
+	// Release 释放锁
+	Release() error
+}
+
+// NewMigrationLocker 根据当前数据库驱动创建迁移锁
+func NewMigrationLocker(opts MigrationLockOptions) MigrationLocker {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultMigrationLockTimeout
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultMigrationLockTTL
+	}
+
+	switch database.DB.Dialector.Name() {
+	case "sqlite":
+		return newTableMigrationLocker(opts)
+	default:
+		// 暂无其他驱动，统一退回锁表实现
+		return newTableMigrationLocker(opts)
+	}
+}
+
+// WithMigrationLock 在迁移锁保护下执行 fn
+// 多个实例同时启动时，只有一个实例执行 fn，其余实例等待锁释放后再执行（已执行的脚本会被跳过）
+func WithMigrationLock(opts MigrationLockOptions, fn func() error) (err error) {
+	locker := NewMigrationLocker(opts)
+	if err := locker.Acquire(); err != nil {
+		return err
+	}
+	defer func() {
+		if releaseErr := locker.Release(); releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+	}()
+
+	return fn()
+}
+
+// tableMigrationLocker 基于锁表的迁移锁
+type tableMigrationLocker struct {
+	repo  *repository.MigrationLockRepository
+	opts  MigrationLockOptions
+	owner string
+
+	stopRefresh chan struct{}
+	wg          sync.WaitGroup
+}
+
+func newTableMigrationLocker(opts MigrationLockOptions) *tableMigrationLocker {
+	return &tableMigrationLocker{
+		repo:  repository.NewMigrationLockRepository(),
+		opts:  opts,
+		owner: lockOwner(),
+	}
+}
+
+// Acquire 获取锁，锁被占用时按固定间隔重试直到超时
+func (l *tableMigrationLocker) Acquire() error {
+	if err := l.repo.EnsureTable(); err != nil {
+		return fmt.Errorf("failed to create migration lock table: %w", err)
+	}
+
+	deadline := time.Now().Add(l.opts.Timeout)
+	waitLogged := false
+	for {
+		stale, err := l.repo.TryAcquire(migrationLockName, l.owner, l.opts.TTL)
+		if err == nil {
+			if stale != nil {
+				logger.Warn("Recovered stale migration lock",
+					zap.String("previous_owner", stale.Owner),
+					zap.Time("expired_at", stale.ExpiresAt),
+				)
+			}
+			logger.Info("Migration lock acquired",
+				zap.String("owner", l.owner),
+				zap.Duration("ttl", l.opts.TTL),
+			)
+			l.startRefresh()
+			return nil
+		}
+
+		// SQLite 在并发写入时可能返回 database is locked，同样视为锁被占用
+		if !errors.Is(err, repository.ErrLockHeld) && !isDatabaseBusyError(err) {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+
+		if !waitLogged {
+			logger.Info("Migration lock is held by another instance, waiting",
+				zap.Duration("timeout", l.opts.Timeout),
+			)
+			waitLogged = true
+		}
+
+		if time.Now().After(deadline) {
+			return ErrMigrationLockTimeout
+		}
+		time.Sleep(migrationLockRetryInterval)
+	}
+}
+
+// Release 释放锁并停止续期
+func (l *tableMigrationLocker) Release() error {
+	if l.stopRefresh != nil {
+		close(l.stopRefresh)
+		l.wg.Wait()
+		l.stopRefresh = nil
+	}
+
+	if err := l.repo.Release(migrationLockName, l.owner); err != nil {
+		return fmt.Errorf("failed to release migration lock: %w", err)
+	}
+
+	logger.Info("Migration lock released", zap.String("owner", l.owner))
+	return nil
+}
+
+// startRefresh 在持有锁期间定期续期，避免长时间迁移时被其他实例当作失效锁回收
+func (l *tableMigrationLocker) startRefresh() {
+	l.stopRefresh = make(chan struct{})
+	l.wg.Add(1)
+
+	go func() {
+		defer l.wg.Done()
+
+		ticker := time.NewTicker(l.opts.TTL / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-l.stopRefresh:
+				return
+			case <-ticker.C:
+				if err := l.repo.Refresh(migrationLockName, l.owner, l.opts.TTL); err != nil {
+					logger.Error("Failed to refresh migration lock",
+						zap.String("owner", l.owner),
+						zap.Error(err),
+					)
+				}
+			}
+		}
+	}()
+}
+
+// lockOwner 生成锁持有者标识
+func lockOwner() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.New().String()[:8])
+}
+
+// isDatabaseBusyError 检查是否是 SQLite 数据库忙错误
+func isDatabaseBusyError(err error) bool {
+	if err == nil {
+		return false
+	}
+	errMsg := strings.ToLower(err.Error())
+	return strings.Contains(errMsg, "database is locked") ||
+		strings.Contains(errMsg, "database is busy")
+}
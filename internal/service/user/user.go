@@ -1,24 +1,57 @@
 package user
 
 import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"gosir/internal/clock"
+	"gosir/internal/common"
+	auditmodel "gosir/internal/model/audit"
 	usermodel "gosir/internal/model/user"
 	"gosir/internal/repository"
-	"time"
+	"gosir/internal/service/audit"
+	"math/big"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	// ErrPasswordMismatch 旧密码错误
+	ErrPasswordMismatch = errors.New("password mismatch")
+	// ErrPasswordUnchanged 新密码与旧密码相同
+	ErrPasswordUnchanged = errors.New("new password must differ from the old one")
+	// ErrVersionConflict 用户已被修改，请求中的版本号不是最新版本
+	ErrVersionConflict = repository.ErrUserVersionConflict
+	// ErrEmailTaken 邮箱已被其他用户使用
+	ErrEmailTaken = errors.New("email already used by another user")
+	// ErrPhoneTaken 手机号已被其他用户使用
+	ErrPhoneTaken = errors.New("phone already used by another user")
+	// ErrPrivilegedField 用户修改自己的信息时修改了邮箱或状态
+	ErrPrivilegedField = errors.New("email and status can only be changed by an admin")
+)
+
+// UserService 用户服务，创建、修改和删除用户时写入审计日志
 type UserService struct {
+	ctx      context.Context
 	userRepo *repository.UserRepository
 }
 
 func NewUserService() *UserService {
 	return &UserService{
+		ctx:      context.Background(),
 		userRepo: repository.NewUserRepository(),
 	}
 }
 
+// WithContext 返回绑定 context 的服务实例
+func (s *UserService) WithContext(ctx context.Context) *UserService {
+	return &UserService{
+		ctx:      ctx,
+		userRepo: s.userRepo.WithContext(ctx),
+	}
+}
+
 func (s *UserService) GetUserByID(id string) (*usermodel.User, error) {
 	return s.userRepo.FindByID(id)
 }
@@ -40,10 +73,23 @@ type CreateUserRequest struct {
 	Password string `validate:"required,min=6"`
 	Phone    string `validate:"omitempty,max=20"`
 	Avatar   string `validate:"omitempty,max=500"`
-	Status   *int   `validate:"omitempty,oneof=1 2"`
+	Status   *int   `validate:"omitempty,oneof=1 2 3"`
+
+	MustChangePassword bool // 首次登录后必须修改密码
+	IsAdmin            bool // 管理员（只在初始化管理员账号时设置）
 }
 
+// CreateUser 创建用户，邮箱已被其他用户使用时返回 ErrEmailTaken
 func (s *UserService) CreateUser(req *CreateUserRequest) (*usermodel.User, error) {
+	email := usermodel.NormalizeEmail(req.Email)
+	taken, err := s.userRepo.EmailTaken(email, "")
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrEmailTaken
+	}
+
 	// 密码加密
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -59,37 +105,215 @@ func (s *UserService) CreateUser(req *CreateUserRequest) (*usermodel.User, error
 	newUser := &usermodel.User{
 		ID:        uuid.New().String(),
 		Name:      req.Name,
-		Email:     req.Email,
+		Email:     email,
 		Password:  string(hashedPassword),
 		Phone:     req.Phone,
 		Avatar:    req.Avatar,
 		Status:    status,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: clock.Now(),
+		UpdatedAt: clock.Now(),
+
+		MustChangePassword: req.MustChangePassword,
+		IsAdmin:            req.IsAdmin,
+	}
+	created, err := s.userRepo.Create(newUser)
+	if err != nil {
+		return nil, err
 	}
-	return s.userRepo.Create(newUser)
+	s.audit(audit.ActionUserCreate, created.ID, audit.Diff(nil, auditSnapshot(created)), "")
+	return created, nil
 }
 
 func (s *UserService) GetAllUsers() ([]*usermodel.User, error) {
 	return s.userRepo.FindAll()
 }
 
-func (s *UserService) UpdateUser(id, name, email, phone, avatar string, status *int) (*usermodel.User, error) {
+// UpdateUserRequest 修改用户请求，所有字段整体替换
+type UpdateUserRequest struct {
+	Name   string
+	Email  string
+	Phone  string
+	Avatar string
+	Status *int // 为空时不修改
+
+	Self bool // 用户修改自己的信息：邮箱和状态只能由管理员修改，与当前值不同时返回 ErrPrivilegedField
+}
+
+// UpdateUser 修改用户信息，version 为调用方读取到的版本号，与当前版本不一致时返回 ErrVersionConflict
+// version 为 common.AnyVersion 时不检查版本（If-Match: *），但仍以读取到的版本保存，并发修改时返回 ErrVersionConflict
+// 邮箱或手机号已被其他用户使用时返回 ErrEmailTaken / ErrPhoneTaken，用户修改自己的邮箱或状态时返回 ErrPrivilegedField
+func (s *UserService) UpdateUser(id string, version int64, req *UpdateUserRequest) (*usermodel.User, error) {
 	userModel, err := s.userRepo.FindByID(id)
 	if err != nil {
 		return nil, err
 	}
-	userModel.Name = name
+	if version != common.AnyVersion && userModel.Version != version {
+		return nil, ErrVersionConflict
+	}
+
+	email := usermodel.NormalizeEmail(req.Email)
+	if req.Self && (email != userModel.Email || (req.Status != nil && *req.Status != userModel.Status)) {
+		return nil, ErrPrivilegedField
+	}
+	if email != userModel.Email {
+		taken, err := s.userRepo.EmailTaken(email, id)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, ErrEmailTaken
+		}
+	}
+	if req.Phone != userModel.Phone {
+		taken, err := s.userRepo.PhoneTaken(req.Phone, id)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, ErrPhoneTaken
+		}
+	}
+
+	before := auditSnapshot(userModel)
+	userModel.Name = req.Name
 	userModel.Email = email
-	userModel.Phone = phone
-	userModel.Avatar = avatar
-	if status != nil {
-		userModel.Status = *status
+	userModel.Phone = req.Phone
+	userModel.Avatar = req.Avatar
+	if req.Status != nil {
+		userModel.Status = *req.Status
 	}
-	userModel.UpdatedAt = time.Now()
-	return s.userRepo.Update(userModel)
+	userModel.UpdatedAt = clock.Now()
+	return s.update(userModel, before, "")
 }
 
 func (s *UserService) DeleteUser(id string) error {
-	return s.userRepo.Delete(id)
+	userModel, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.Delete(id); err != nil {
+		return err
+	}
+	s.audit(audit.ActionUserDelete, id, audit.Diff(auditSnapshot(userModel), nil), "")
+	return nil
+}
+
+// DisableUser 禁用用户
+func (s *UserService) DisableUser(id string) (*usermodel.User, error) {
+	userModel, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	before := auditSnapshot(userModel)
+	userModel.Status = int(usermodel.UserStatusDisabled)
+	userModel.UpdatedAt = clock.Now()
+	return s.update(userModel, before, "")
+}
+
+// ResetPassword 重置用户密码，重置后用户下次登录必须修改密码
+func (s *UserService) ResetPassword(id, password string) (*usermodel.User, error) {
+	userModel, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	before := auditSnapshot(userModel)
+	userModel.Password = string(hashedPassword)
+	userModel.MustChangePassword = true
+	userModel.UpdatedAt = clock.Now()
+	return s.update(userModel, before, "password reset")
+}
+
+// ChangePassword 用户修改自己的密码（需验证旧密码）
+func (s *UserService) ChangePassword(id, oldPassword, newPassword string) (*usermodel.User, error) {
+	userModel, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(userModel.Password), []byte(oldPassword)); err != nil {
+		return nil, ErrPasswordMismatch
+	}
+	if oldPassword == newPassword {
+		return nil, ErrPasswordUnchanged
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	before := auditSnapshot(userModel)
+	userModel.Password = string(hashedPassword)
+	userModel.MustChangePassword = false
+	userModel.UpdatedAt = clock.Now()
+	return s.update(userModel, before, "password changed")
+}
+
+// update 保存修改后的用户并写入审计日志，没有字段变化且 detail 为空时不写入
+// 用户被禁用时撤销已签发的 token 和 OAuth 刷新令牌，之后重新启用也不能继续使用
+func (s *UserService) update(userModel *usermodel.User, before map[string]any, detail string) (*usermodel.User, error) {
+	disabled := userModel.Status == int(usermodel.UserStatusDisabled) && before["status"] != userModel.Status
+	if disabled {
+		now := userModel.UpdatedAt
+		userModel.TokensRevokedAt = &now
+	}
+	updated, err := s.userRepo.Update(userModel)
+	if err != nil {
+		return nil, err
+	}
+	if disabled {
+		if err := repository.NewOAuthTokenRepository().WithContext(s.ctx).RevokeUser(updated.ID, updated.UpdatedAt); err != nil {
+			return nil, err
+		}
+	}
+	changes := audit.Diff(before, auditSnapshot(updated))
+	if len(changes) > 0 || detail != "" {
+		s.audit(audit.ActionUserUpdate, updated.ID, changes, detail)
+	}
+	return updated, nil
+}
+
+// audit 写入用户操作审计日志
+func (s *UserService) audit(action, userID string, changes auditmodel.Changes, detail string) {
+	audit.Record(s.ctx, audit.Entry{
+		Action:     action,
+		TargetType: audit.TargetUser,
+		TargetID:   userID,
+		Changes:    changes,
+		Detail:     detail,
+	})
+}
+
+// auditSnapshot 审计日志记录的用户字段（不包括密码）
+func auditSnapshot(u *usermodel.User) map[string]any {
+	return map[string]any{
+		"name":                 u.Name,
+		"email":                u.Email,
+		"phone":                u.Phone,
+		"avatar":               u.Avatar,
+		"status":               u.Status,
+		"must_change_password": u.MustChangePassword,
+		"is_admin":             u.IsAdmin,
+	}
+}
+
+// GenerateRandomPassword 生成随机密码
+func GenerateRandomPassword(length int) (string, error) {
+	const charset = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnpqrstuvwxyz23456789"
+
+	buf := make([]byte, length)
+	for i := range buf {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		if err != nil {
+			return "", err
+		}
+		buf[i] = charset[n.Int64()]
+	}
+	return string(buf), nil
 }
@@ -0,0 +1,74 @@
+// Package testutil 测试辅助函数：初始化日志和执行过迁移的独立内存数据库
+package testutil
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gosir/internal/common"
+	"gosir/internal/database"
+	"gosir/internal/logger"
+	"gosir/internal/service/oauth"
+	"gosir/internal/service/system"
+)
+
+// dbSeq 内存数据库序号，每个测试使用独立的数据库
+var dbSeq atomic.Int64
+
+// SetupDB 初始化日志（只输出错误到标准错误）和独立的内存数据库并执行全部迁移，测试结束时关闭数据库
+// 数据库是全局的，使用 SetupDB 的测试不能并行执行
+func SetupDB(t testing.TB) {
+	t.Helper()
+
+	if err := logger.InitWithConfig(&logger.LogConfig{
+		Level:   "error",
+		Outputs: []logger.OutputConfig{{Type: logger.OutputStderr}},
+	}); err != nil {
+		t.Fatalf("init logger: %v", err)
+	}
+
+	dsn := fmt.Sprintf("file:gosir-test-%d?mode=memory&cache=shared", dbSeq.Add(1))
+	if err := database.InitDB(database.Options{DSN: dsn, LogLevel: "silent", Logger: logger.Log}); err != nil {
+		t.Fatalf("init database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := database.DB.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	if err := system.RunMigrations(migrationsDir()); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+}
+
+// SetupOAuth 在 SetupDB 的基础上初始化 JWT 管理器并以 issuer 为对外地址开启授权服务，ID Token 使用临时生成的签名密钥
+func SetupOAuth(t testing.TB, issuer string) {
+	t.Helper()
+	SetupDB(t)
+
+	common.InitJWT("gosir-test-jwt-secret-0123456789abcdef", 1)
+	key, err := oauth.GenerateSigningKey()
+	if err != nil {
+		t.Fatalf("generate signing key: %v", err)
+	}
+	oauth.Init(oauth.Options{
+		Issuer:          issuer,
+		SigningKey:      key,
+		CodeTTL:         time.Minute,
+		AccessTokenTTL:  time.Hour,
+		RefreshTokenTTL: 24 * time.Hour,
+		IDTokenTTL:      time.Hour,
+		SessionTTL:      time.Hour,
+	})
+}
+
+// migrationsDir 仓库根目录下的 migrations 目录
+func migrationsDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "migrations")
+}
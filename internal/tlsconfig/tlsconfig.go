@@ -0,0 +1,173 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// 客户端证书校验方式
+const (
+	ClientAuthNone     = "none"     // 不要求客户端证书
+	ClientAuthOptional = "optional" // 客户端提供证书时校验
+	ClientAuthRequire  = "require"  // 必须提供由 client CA 签发的证书
+)
+
+// Options TLS 选项
+type Options struct {
+	CertFile     string   // 证书文件（PEM，可包含中间证书）
+	KeyFile      string   // 私钥文件（PEM）
+	SelfSigned   bool     // 使用启动时生成的自签名证书（仅用于开发环境）
+	MinVersion   string   // 最低 TLS 版本: 1.2, 1.3
+	CipherSuites []string // TLS 1.2 允许的加密套件名称，为空时使用 Go 默认值
+	ClientCAFile string   // 校验客户端证书的 CA 文件（PEM）
+	ClientAuth   string   // none, optional, require
+}
+
+// Manager 管理服务端证书和客户端 CA，支持运行时重新加载
+type Manager struct {
+	opts         Options
+	minVersion   uint16
+	cipherSuites []uint16
+	clientAuth   tls.ClientAuthType
+
+	cert      atomic.Pointer[tls.Certificate]
+	clientCAs atomic.Pointer[x509.CertPool]
+}
+
+// New 加载证书并创建 Manager
+func New(opts Options) (*Manager, error) {
+	minVersion, err := ParseVersion(opts.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	cipherSuites, err := ParseCipherSuites(opts.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	clientAuth, err := parseClientAuth(opts.ClientAuth)
+	if err != nil {
+		return nil, err
+	}
+	if clientAuth != tls.NoClientCert && opts.ClientCAFile == "" {
+		return nil, errors.New("client CA file is required for client certificate verification")
+	}
+
+	m := &Manager{
+		opts:         opts,
+		minVersion:   minVersion,
+		cipherSuites: cipherSuites,
+		clientAuth:   clientAuth,
+	}
+
+	if opts.SelfSigned {
+		cert, err := generateSelfSigned()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate self-signed certificate: %w", err)
+		}
+		m.cert.Store(cert)
+	}
+	if err := m.Reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Reload 重新读取证书、私钥和客户端 CA 文件，读取失败时保留当前证书
+// 自签名证书不会重新生成
+func (m *Manager) Reload() error {
+	if !m.opts.SelfSigned {
+		cert, err := tls.LoadX509KeyPair(m.opts.CertFile, m.opts.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load certificate: %w", err)
+		}
+		m.cert.Store(&cert)
+	}
+
+	if m.opts.ClientCAFile != "" {
+		pem, err := os.ReadFile(m.opts.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in client CA file %s", m.opts.ClientCAFile)
+		}
+		m.clientCAs.Store(pool)
+	}
+	return nil
+}
+
+// Certificate 当前使用的服务端证书
+func (m *Manager) Certificate() *tls.Certificate {
+	return m.cert.Load()
+}
+
+// TLSConfig 构建服务端 tls.Config，每次握手使用最新加载的证书和客户端 CA
+func (m *Manager) TLSConfig() *tls.Config {
+	base := &tls.Config{
+		MinVersion:   m.minVersion,
+		CipherSuites: m.cipherSuites,
+		ClientAuth:   m.clientAuth,
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+
+	cfg := base.Clone()
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		conn := base.Clone()
+		conn.Certificates = []tls.Certificate{*m.cert.Load()}
+		conn.ClientCAs = m.clientCAs.Load()
+		return conn, nil
+	}
+	return cfg
+}
+
+// ParseVersion 解析 TLS 版本，为空时为 1.2
+func ParseVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q (supported: 1.2, 1.3)", version)
+	}
+}
+
+// ParseCipherSuites 按名称解析加密套件，只允许 Go 认为安全的套件
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	supported := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		supported[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := supported[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported or insecure cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// parseClientAuth 解析客户端证书校验方式
+func parseClientAuth(mode string) (tls.ClientAuthType, error) {
+	switch mode {
+	case "", ClientAuthNone:
+		return tls.NoClientCert, nil
+	case ClientAuthOptional:
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequire:
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return 0, fmt.Errorf("unsupported client auth %q (supported: none, optional, require)", mode)
+	}
+}
@@ -0,0 +1,147 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName tracer 名称
+const instrumentationName = "gosir"
+
+// 导出器类型
+const (
+	ExporterNone   = "none"   // 关闭追踪
+	ExporterOTLP   = "otlp"   // OTLP/HTTP 导出到 collector
+	ExporterStdout = "stdout" // 输出到控制台（开发调试）
+	ExporterFile   = "file"   // 输出到文件（开发调试）
+)
+
+// Config 追踪配置
+type Config struct {
+	Exporter    string  // none, otlp, stdout, file
+	Endpoint    string  // OTLP collector 地址，如 localhost:4318
+	Insecure    bool    // OTLP 是否使用明文 HTTP
+	FilePath    string  // file 导出器的输出文件
+	SampleRatio float64 // 采样率 0-1，仅对没有上游采样决策的根 span 生效
+	ServiceName string  // 服务名
+}
+
+// ShutdownFunc 关闭追踪，导出剩余的 span
+type ShutdownFunc func(ctx context.Context) error
+
+// Init 初始化全局 TracerProvider 和 W3C traceparent 传播器
+// 导出器为 none 时不做任何事，全局使用 noop 实现
+func Init(cfg Config) (ShutdownFunc, error) {
+	noop := func(context.Context) error { return nil }
+
+	if cfg.Exporter == "" || cfg.Exporter == ExporterNone {
+		return noop, nil
+	}
+
+	exporter, closeFn, err := newExporter(cfg)
+	if err != nil {
+		return noop, err
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = instrumentationName
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return noop, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeFn != nil {
+			if closeErr := closeFn(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
+
+// newExporter 根据配置创建导出器，返回的 close 函数用于关闭输出文件
+func newExporter(cfg Config) (sdktrace.SpanExporter, func() error, error) {
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(context.Background(), opts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		return exporter, nil, nil
+
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		return exporter, nil, nil
+
+	case ExporterFile:
+		if cfg.FilePath == "" {
+			return nil, nil, fmt.Errorf("tracing file path is required for file exporter")
+		}
+		if err := os.MkdirAll(filepath.Dir(cfg.FilePath), 0755); err != nil {
+			return nil, nil, fmt.Errorf("failed to create tracing directory: %w", err)
+		}
+		file, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open tracing file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			_ = file.Close()
+			return nil, nil, fmt.Errorf("failed to create file exporter: %w", err)
+		}
+		return exporter, file.Close, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown tracing exporter: %s", cfg.Exporter)
+	}
+}
+
+// Tracer 获取应用 tracer（未初始化时为 noop 实现）
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
@@ -0,0 +1,9 @@
+-- 创建 token 黑名单表（多实例及命令行吊销共享）
+CREATE TABLE IF NOT EXISTS token_blacklist (
+    jti VARCHAR(36) PRIMARY KEY,
+    expired_at DATETIME NOT NULL,
+    blacklisted_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_token_blacklist_expired_at ON token_blacklist(expired_at);
@@ -0,0 +1,2 @@
+-- 用户首次登录后必须修改密码（管理员初始化账号使用）
+ALTER TABLE users ADD COLUMN must_change_password INTEGER DEFAULT 0;
@@ -0,0 +1,11 @@
+-- 创建限流计数表（rateLimit.store 为 database 时多实例共享）
+CREATE TABLE IF NOT EXISTS rate_limit_counters (
+    key VARCHAR(255) PRIMARY KEY,
+    value REAL NOT NULL DEFAULT 0,
+    previous REAL NOT NULL DEFAULT 0,
+    timestamp DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_rate_limit_counters_expires_at ON rate_limit_counters(expires_at);
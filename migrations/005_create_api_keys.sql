@@ -0,0 +1,18 @@
+-- 创建 API Key 表（只保存前缀和 secret 的哈希）
+CREATE TABLE IF NOT EXISTS api_keys (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    secret_hash VARCHAR(64) NOT NULL,
+    scopes TEXT NOT NULL DEFAULT '',
+    expires_at DATETIME,
+    last_used_at DATETIME,
+    last_used_ip VARCHAR(64),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 创建索引
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_prefix ON api_keys(prefix);
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id);
@@ -0,0 +1,15 @@
+-- 创建外部身份表（OIDC 登录的提供方用户与本地用户的关联）
+CREATE TABLE IF NOT EXISTS identities (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    last_login_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 创建索引
+CREATE UNIQUE INDEX IF NOT EXISTS idx_identities_provider_subject ON identities(provider, subject);
+CREATE INDEX IF NOT EXISTS idx_identities_user_id ON identities(user_id);
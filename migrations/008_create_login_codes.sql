@@ -0,0 +1,16 @@
+-- 创建免密登录凭证表（验证码和登录链接，只保存哈希）
+CREATE TABLE IF NOT EXISTS login_codes (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    kind VARCHAR(10) NOT NULL,
+    channel VARCHAR(10) NOT NULL,
+    secret_hash VARCHAR(64) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_login_codes_user_id ON login_codes(user_id);
+CREATE INDEX IF NOT EXISTS idx_login_codes_expires_at ON login_codes(expires_at);
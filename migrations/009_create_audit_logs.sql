@@ -0,0 +1,23 @@
+-- 创建审计日志表
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id VARCHAR(36) PRIMARY KEY,
+    actor_id VARCHAR(36),
+    action VARCHAR(50) NOT NULL,
+    target_type VARCHAR(50),
+    target_id VARCHAR(100),
+    changes TEXT,
+    detail VARCHAR(255),
+    ip VARCHAR(64),
+    user_agent VARCHAR(255),
+    request_id VARCHAR(128),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs(created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs(actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target ON audit_logs(target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs(action);
+
+-- 审计日志写入后不可修改（过期记录由定时任务删除）
+CREATE TRIGGER IF NOT EXISTS audit_logs_immutable BEFORE UPDATE ON audit_logs BEGIN SELECT RAISE(ABORT, 'audit logs are immutable'); END;
@@ -0,0 +1,2 @@
+-- 用户版本号（乐观锁），每次修改加 1
+ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
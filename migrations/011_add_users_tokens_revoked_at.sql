@@ -0,0 +1,2 @@
+-- 用户 token 撤销时间，在此之前签发的 token 失效（禁用用户时设置）
+ALTER TABLE users ADD COLUMN tokens_revoked_at DATETIME;
@@ -0,0 +1,3 @@
+-- 邮箱不区分大小写：已有邮箱转为小写（转换后与其他用户冲突的保持不变，需管理员处理）
+UPDATE users SET email = lower(email) WHERE email <> lower(email) AND NOT EXISTS (SELECT 1 FROM users AS other WHERE other.email = lower(users.email));
+CREATE INDEX IF NOT EXISTS idx_users_email_lower ON users(lower(email));
@@ -0,0 +1,3 @@
+-- 授权码换取 token 后标记为已使用（不再立即删除），重复使用时吊销用它签发的刷新令牌
+ALTER TABLE oauth_authorization_codes ADD COLUMN family_id VARCHAR(36) NOT NULL DEFAULT '';
+ALTER TABLE oauth_authorization_codes ADD COLUMN used_at DATETIME;
@@ -0,0 +1,2 @@
+-- 管理员标记，由初始化管理员账号时设置（不再以 admin.email 判断管理员，修改邮箱不影响管理员身份）
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT 0;